@@ -17,8 +17,11 @@ limitations under the License.
 package app
 
 import (
+	"context"
 	"io/ioutil"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/spf13/pflag"
 
@@ -32,14 +35,31 @@ import (
 // Main is the kind main(), it will invoke Run(), if an error is returned
 // it will then call os.Exit
 func Main() {
-	if err := Run(cmd.NewLogger(), cmd.StandardIOStreams(), os.Args[1:]); err != nil {
+	if err := Run(signalContext(), cmd.NewLogger(), cmd.StandardIOStreams(), os.Args[1:]); err != nil {
 		os.Exit(1)
 	}
 }
 
+// signalContext returns a context cancelled on the first SIGINT or SIGTERM,
+// which kills running commands and cleans up E.G. half created clusters
+// A second signal exits immediately, skipping the cleanup
+func signalContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		cancel()
+		<-signals
+		os.Exit(1)
+	}()
+	return ctx
+}
+
 // Run invokes the kind root command, returning the error.
+// Commands stop once ctx is done
 // See: sigs.k8s.io/kind/pkg/cmd/kind
-func Run(logger log.Logger, streams cmd.IOStreams, args []string) error {
+func Run(ctx context.Context, logger log.Logger, streams cmd.IOStreams, args []string) error {
 	// NOTE: we handle the quiet flag here so we can fully silence cobra
 	if checkQuiet(args) {
 		// if we are in quiet mode, we want to suppress all status output
@@ -48,7 +68,7 @@ func Run(logger log.Logger, streams cmd.IOStreams, args []string) error {
 		streams.ErrOut = ioutil.Discard
 	}
 	// actually run the command
-	c := kind.NewCommand(logger, streams)
+	c := kind.NewCommand(ctx, logger, streams)
 	c.SetArgs(args)
 	if err := c.Execute(); err != nil {
 		logError(logger, err)
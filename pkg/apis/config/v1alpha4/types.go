@@ -31,6 +31,9 @@ type Cluster struct {
 	// Networking contains cluster wide network settings
 	Networking Networking `yaml:"networking,omitempty"`
 
+	// Storage contains cluster wide storage settings
+	Storage Storage `yaml:"storage,omitempty"`
+
 	// KubeadmConfigPatches are applied to the generated kubeadm config as
 	// merge patches. The `kind` field must match the target object, and
 	// if `apiVersion` is specified it will only be applied to matching objects.
@@ -66,6 +69,27 @@ type Cluster struct {
 	// in the order listed.
 	// These should be YAML or JSON formatting RFC 6902 JSON patches
 	ContainerdConfigPatchesJSON6902 []string `yaml:"containerdConfigPatchesJSON6902,omitempty"`
+
+	// ProviderPatches are applied to the objects generated by the node
+	// provider as merge patches, such as the node Pod of the kubernetes
+	// provider. The `kind` field must match the target object, and
+	// if `apiVersion` is specified it will only be applied to matching objects.
+	//
+	// This should be an inline yaml blob-string
+	//
+	// https://tools.ietf.org/html/rfc7386
+	//
+	// The cluster-level patches are appied before the node-level patches.
+	ProviderPatches []string `yaml:"providerPatches,omitempty"`
+
+	// Hooks are commands run on the nodes or on the host at defined points
+	// of cluster creation, in the order listed, see Hook
+	Hooks []Hook `yaml:"hooks,omitempty"`
+
+	// Addons are manifests applied to the cluster once all nodes joined and
+	// the default CNI and StorageClass are installed, in the order listed,
+	// see Addon
+	Addons []Addon `yaml:"addons,omitempty"`
 }
 
 // TypeMeta partially copies apimachinery/pkg/apis/meta/v1.TypeMeta
@@ -100,6 +124,10 @@ type Node struct {
 	// binded to a host Port
 	ExtraPortMappings []PortMapping `yaml:"extraPortMappings,omitempty"`
 
+	// Resources describes the compute resources of the node container
+	// If unset the node is not constrained, see Resources
+	Resources Resources `yaml:"resources,omitempty"`
+
 	// KubeadmConfigPatches are applied to the generated kubeadm config as
 	// merge patches. The `kind` field must match the target object, and
 	// if `apiVersion` is specified it will only be applied to matching objects.
@@ -127,6 +155,38 @@ type Node struct {
 	// The node-level patches will be applied after the cluster-level patches
 	// have been applied. (See Cluster.KubeadmConfigPatchesJSON6902)
 	KubeadmConfigPatchesJSON6902 []PatchJSON6902 `yaml:"kubeadmConfigPatchesJSON6902,omitempty"`
+
+	// ProviderPatches are applied to the objects generated by the node
+	// provider for this node as merge patches. (See Cluster.ProviderPatches)
+	//
+	// The node-level patches will be applied after the cluster-level patches
+	// have been applied.
+	ProviderPatches []string `yaml:"providerPatches,omitempty"`
+}
+
+// Resources describes the compute resources requested by and limiting a node
+// Quantities use the Kubernetes resource quantity format.
+// In yaml this looks like:
+//  requests:
+//    cpu: 500m
+//    memory: 1Gi
+//  limits:
+//    cpu: "2"
+//    memory: 4Gi
+// Requests default to the matching Limits, and must not exceed them.
+type Resources struct {
+	// Requests are the resources reserved for the node
+	Requests ResourceList `yaml:"requests,omitempty"`
+	// Limits are the maximum resources the node may use
+	Limits ResourceList `yaml:"limits,omitempty"`
+}
+
+// ResourceList is a set of compute resource quantities
+type ResourceList struct {
+	// CPU is a quantity of CPU cores, e.g. "1.5" or "1500m"
+	CPU string `yaml:"cpu,omitempty"`
+	// Memory is a quantity of bytes, e.g. "2Gi"
+	Memory string `yaml:"memory,omitempty"`
 }
 
 // NodeRole defines possible role for nodes in a Kubernetes cluster managed by `kind`
@@ -165,6 +225,46 @@ type Networking struct {
 	DisableDefaultCNI bool `yaml:"disableDefaultCNI,omitempty"`
 }
 
+// Storage contains cluster wide storage settings
+type Storage struct {
+	// If DisableDefaultStorageClass is true, kind will not install the
+	// default dynamic provisioner and StorageClass.
+	DisableDefaultStorageClass bool `yaml:"disableDefaultStorageClass,omitempty"`
+	// VolumeBindingMode is the volume binding mode of the default
+	// StorageClass
+	//
+	// Defaults to WaitForFirstConsumer
+	VolumeBindingMode VolumeBindingMode `yaml:"volumeBindingMode,omitempty"`
+	// ReclaimPolicy is the reclaim policy of the volumes provisioned for the
+	// default StorageClass
+	//
+	// Defaults to Delete
+	ReclaimPolicy ReclaimPolicy `yaml:"reclaimPolicy,omitempty"`
+}
+
+// VolumeBindingMode represents an "enum" for the volume binding modes of
+// the default StorageClass, see also Storage.
+type VolumeBindingMode string
+
+const (
+	// VolumeBindingWaitForFirstConsumer provisions volumes on the node the
+	// first pod using them is scheduled to
+	VolumeBindingWaitForFirstConsumer VolumeBindingMode = "WaitForFirstConsumer"
+	// VolumeBindingImmediate provisions volumes as soon as they are claimed
+	VolumeBindingImmediate VolumeBindingMode = "Immediate"
+)
+
+// ReclaimPolicy represents an "enum" for the reclaim policies of the
+// default StorageClass, see also Storage.
+type ReclaimPolicy string
+
+const (
+	// ReclaimDelete deletes the volume directory once it is released
+	ReclaimDelete ReclaimPolicy = "Delete"
+	// ReclaimRetain keeps the volume directory once it is released
+	ReclaimRetain ReclaimPolicy = "Retain"
+)
+
 // ClusterIPFamily defines cluster network IP family
 type ClusterIPFamily string
 
@@ -265,3 +365,79 @@ const (
 	// PortMappingProtocolSCTP specifies SCTP protocol
 	PortMappingProtocolSCTP PortMappingProtocol = "SCTP"
 )
+
+// Addon is a set of manifests applied to the cluster during creation
+// In yaml this looks like:
+//  name: cert-manager
+//  manifests:
+//  - ./addons/cert-manager.yaml
+//  waitFor:
+//  - kind: Deployment
+//    namespace: cert-manager
+//    name: cert-manager-webhook
+type Addon struct {
+	// Name identifies the addon in the status output
+	Name string `yaml:"name,omitempty"`
+	// Manifests are the paths on the host of manifest files, or of
+	// directories whose .yaml, .yml and .json files are applied in
+	// lexical order
+	// Relative paths are relative to the current working directory
+	Manifests []string `yaml:"manifests,omitempty"`
+	// WaitFor lists the workloads that must become available before the
+	// addon is considered installed
+	WaitFor []AddonWorkload `yaml:"waitFor,omitempty"`
+}
+
+// AddonWorkload identifies a workload created by an Addon
+type AddonWorkload struct {
+	// Kind is the kind of the workload, Deployment or DaemonSet
+	Kind string `yaml:"kind,omitempty"`
+	// Namespace is the namespace of the workload
+	// Defaults to "default"
+	Namespace string `yaml:"namespace,omitempty"`
+	// Name is the name of the workload
+	Name string `yaml:"name,omitempty"`
+}
+
+// Hook is a command run during cluster creation
+// In yaml this looks like:
+//  name: load-images
+//  when: AfterReady
+//  roles:
+//  - worker
+//  command: ["ctr", "-n", "k8s.io", "images", "import", "/images/app.tar"]
+type Hook struct {
+	// Name identifies the hook in the logs
+	Name string `yaml:"name,omitempty"`
+	// When is the point of cluster creation at which the hook runs
+	When HookPoint `yaml:"when,omitempty"`
+	// Roles selects the nodes with these roles to run the hook on
+	Roles []NodeRole `yaml:"roles,omitempty"`
+	// Nodes selects the nodes with these names to run the hook on, e.g.
+	// "kind-worker2"
+	// If neither Roles nor Nodes are set, the hook runs on every
+	// control-plane and worker node
+	Nodes []string `yaml:"nodes,omitempty"`
+	// OnHost runs the hook on the host running kind instead of on the nodes
+	// The cluster name is available to it as $KIND_CLUSTER_NAME
+	OnHost bool `yaml:"onHost,omitempty"`
+	// Command is the command to run and its arguments, it is not run in
+	// a shell
+	Command []string `yaml:"command,omitempty"`
+}
+
+// HookPoint represents an "enum" for the points of cluster creation at which
+// hooks run, see also Hook.
+type HookPoint string
+
+const (
+	// AfterProvision runs hooks once the node containers are created
+	AfterProvision HookPoint = "AfterProvision"
+	// AfterKubeadmInit runs hooks once the first control-plane node is
+	// initialized
+	AfterKubeadmInit HookPoint = "AfterKubeadmInit"
+	// AfterJoin runs hooks once every node joined the cluster
+	AfterJoin HookPoint = "AfterJoin"
+	// AfterReady runs hooks once the control-plane is ready
+	AfterReady HookPoint = "AfterReady"
+)
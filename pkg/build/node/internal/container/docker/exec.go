@@ -17,6 +17,7 @@ limitations under the License.
 package docker
 
 import (
+	"context"
 	"io"
 
 	"sigs.k8s.io/kind/pkg/exec"
@@ -35,10 +36,15 @@ func ContainerCmder(containerNameOrID string) exec.Cmder {
 }
 
 func (c *containerCmder) Command(command string, args ...string) exec.Cmd {
+	return c.CommandContext(context.Background(), command, args...)
+}
+
+func (c *containerCmder) CommandContext(ctx context.Context, command string, args ...string) exec.Cmd {
 	return &containerCmd{
 		nameOrID: c.nameOrID,
 		command:  command,
 		args:     args,
+		ctx:      ctx,
 	}
 }
 
@@ -51,6 +57,7 @@ type containerCmd struct {
 	stdin    io.Reader
 	stdout   io.Writer
 	stderr   io.Writer
+	ctx      context.Context
 }
 
 func (c *containerCmd) Run() error {
@@ -82,7 +89,7 @@ func (c *containerCmd) Run() error {
 		// finally, with the caller args
 		c.args...,
 	)
-	cmd := exec.Command("docker", args...)
+	cmd := exec.CommandContext(c.ctx, "docker", args...)
 	if c.stdin != nil {
 		cmd.SetStdin(c.stdin)
 	}
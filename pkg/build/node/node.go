@@ -447,6 +447,8 @@ func (c *BuildContext) prePullImages(dir, containerID string) error {
 
 	// all builds should isntall the default CNI images currently
 	requiredImages = append(requiredImages, defaultCNIImages...)
+	// and the default storage images
+	requiredImages = append(requiredImages, defaultStorageImages...)
 
 	// Create "images" subdir.
 	imagesDir := path.Join(dir, "bits", "images")
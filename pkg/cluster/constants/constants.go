@@ -47,3 +47,8 @@ const (
 	// kubernetes nodes
 	ExternalEtcdNodeRoleValue string = "external-etcd"
 )
+
+// LocalPathProvisionerImage is the image of the dynamic provisioner backing
+// the default StorageClass, it is installed at cluster creation and
+// preloaded in node images
+const LocalPathProvisionerImage = "rancher/local-path-provisioner:v0.0.11"
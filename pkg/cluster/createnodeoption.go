@@ -0,0 +1,62 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	internalcreate "sigs.k8s.io/kind/pkg/cluster/internal/create"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// CreateNodeOption is a Provider.CreateNode option
+type CreateNodeOption interface {
+	apply(*internalcreate.NodeOptions) error
+}
+
+type createNodeOptionAdapter func(*internalcreate.NodeOptions) error
+
+func (c createNodeOptionAdapter) apply(o *internalcreate.NodeOptions) error {
+	return c(o)
+}
+
+// CreateNodeWithRole configures the role of the node, "worker" by default
+// "control-plane" nodes can only be added to clusters created with multiple
+// control plane nodes, which have an external load balancer
+func CreateNodeWithRole(role string) CreateNodeOption {
+	return createNodeOptionAdapter(func(o *internalcreate.NodeOptions) error {
+		o.Role = config.NodeRole(role)
+		return nil
+	})
+}
+
+// CreateNodeWithNodeImage overrides the image of the node, which should
+// match the Kubernetes version of the existing nodes
+func CreateNodeWithNodeImage(nodeImage string) CreateNodeOption {
+	return createNodeOptionAdapter(func(o *internalcreate.NodeOptions) error {
+		o.Image = nodeImage
+		return nil
+	})
+}
+
+// CreateNodeWithRetain disables deletion of the node after a failure to
+// create it
+// This is mainly used for debugging purposes
+func CreateNodeWithRetain(retain bool) CreateNodeOption {
+	return createNodeOptionAdapter(func(o *internalcreate.NodeOptions) error {
+		o.Retain = retain
+		return nil
+	})
+}
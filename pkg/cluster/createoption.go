@@ -17,10 +17,13 @@ limitations under the License.
 package cluster
 
 import (
+	"io"
 	"time"
 
 	"sigs.k8s.io/kind/pkg/apis/config/v1alpha3"
+	"sigs.k8s.io/kind/pkg/cluster/events"
 	internalcreate "sigs.k8s.io/kind/pkg/cluster/internal/create"
+	"sigs.k8s.io/kind/pkg/cluster/internal/readiness"
 	internalencoding "sigs.k8s.io/kind/pkg/internal/apis/config/encoding"
 )
 
@@ -80,6 +83,16 @@ func CreateWithRetain(retain bool) CreateOption {
 	})
 }
 
+// CreateWithResume resumes creating the existing nodes of a cluster whose
+// creation failed and whose nodes were retained, see CreateWithRetain
+// The steps already completed on the nodes are skipped
+func CreateWithResume(resume bool) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.Resume = resume
+		return nil
+	})
+}
+
 // CreateWithWaitForReady configures a maximum wait time for the control plane
 // node(s) to be ready. By default no waiting is performed
 func CreateWithWaitForReady(waitTime time.Duration) CreateOption {
@@ -89,6 +102,20 @@ func CreateWithWaitForReady(waitTime time.Duration) CreateOption {
 	})
 }
 
+// CreateWithReadinessGates configures the readiness gates waited for with
+// CreateWithWaitForReady, see ReadinessGateNames
+// By default all of the gates applying to the cluster config are used
+func CreateWithReadinessGates(gates ...string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		parsed, err := readiness.ParseGates(gates)
+		if err != nil {
+			return err
+		}
+		o.ReadinessGates = parsed
+		return nil
+	})
+}
+
 // CreateWithKubeconfigPath sets the explicit --kubeconfig path
 func CreateWithKubeconfigPath(explicitPath string) CreateOption {
 	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
@@ -124,3 +151,23 @@ func CreateWithDisplaySalutation(displaySalutation bool) CreateOption {
 		return nil
 	})
 }
+
+// CreateWithDryRun makes create write the defaulted config, the rendered
+// node config files and the provider objects to out instead of creating
+// the cluster, if out is not nil
+func CreateWithDryRun(out io.Writer) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.DryRun = out
+		return nil
+	})
+}
+
+// CreateWithEventHandler registers handler to be called with an event for
+// each phase of creating the cluster, such as provisioning the nodes and
+// each setup action, for timing them
+func CreateWithEventHandler(handler events.Handler) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.EventHandlers = append(o.EventHandlers, handler)
+		return nil
+	})
+}
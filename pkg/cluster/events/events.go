@@ -0,0 +1,166 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Result is the outcome of a phase
+type Result string
+
+const (
+	// Success means the phase completed
+	Success Result = "success"
+	// Failure means the phase failed with Event.Error
+	Failure Result = "failure"
+	// Skipped means the phase had already completed, see resuming creation
+	Skipped Result = "skipped"
+)
+
+// Event describes a phase of cluster creation once it ended, such as
+// provisioning the nodes or a setup action like kubeadminit
+type Event struct {
+	Name string `json:"name"`
+	// Parent is the phase this phase is nested in, if any, such as
+	// provision for pulling the node images, nested phases end first
+	Parent string    `json:"parent,omitempty"`
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	// Duration is encoded in nanoseconds
+	Duration time.Duration `json:"duration"`
+	Result   Result        `json:"result"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// Handler is called with each event, handlers must not block
+type Handler func(Event)
+
+// Run runs fn as the phase name, calling handlers with its event
+func Run(handlers []Handler, name string, fn func() error) error {
+	return run(handlers, "", name, fn)
+}
+
+func run(handlers []Handler, parent, name string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	end := time.Now()
+	e := Event{
+		Name:     name,
+		Parent:   parent,
+		Start:    start,
+		End:      end,
+		Duration: end.Sub(start),
+		Result:   Success,
+	}
+	if err != nil {
+		e.Result = Failure
+		e.Error = err.Error()
+	}
+	Emit(handlers, e)
+	return err
+}
+
+// nesting is the phase and handlers carried by a context, see NewContext
+type nesting struct {
+	parent   string
+	handlers []Handler
+}
+
+// nestingKey is the context key of the nesting
+type nestingKey struct{}
+
+// NewContext returns a copy of ctx carrying handlers, for emitting the
+// phases nested in the phase parent from code that only has the context
+func NewContext(ctx context.Context, parent string, handlers []Handler) context.Context {
+	return context.WithValue(ctx, nestingKey{}, nesting{parent: parent, handlers: handlers})
+}
+
+// RunContext is like Run for a phase nested in the phase of ctx, emitting
+// nothing if ctx carries no handlers
+func RunContext(ctx context.Context, name string, fn func() error) error {
+	n, _ := ctx.Value(nestingKey{}).(nesting)
+	return run(n.handlers, n.parent, name, fn)
+}
+
+// Emit calls handlers with e
+func Emit(handlers []Handler, e Event) {
+	for _, h := range handlers {
+		h(e)
+	}
+}
+
+// JSONLines returns a Handler writing each event to w as a line of JSON
+// Errors writing to w are ignored
+func JSONLines(w io.Writer) Handler {
+	var mu sync.Mutex
+	return func(e Event) {
+		encoded, err := json.Marshal(e)
+		if err != nil {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		_, _ = w.Write(append(encoded, '\n'))
+	}
+}
+
+// Summary collects events for a table of the phases and their durations
+type Summary struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+// Handle is a Handler collecting e
+func (s *Summary) Handle(e Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, e)
+}
+
+// Events returns the collected events in order
+func (s *Summary) Events() []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Event{}, s.events...)
+}
+
+// WriteTable writes a table of the collected phases to w, ending with the
+// total duration, nested phases are shown along with their parent and are
+// not counted twice in the total
+func (s *Summary) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "PHASE\tRESULT\tDURATION")
+	var total time.Duration
+	for _, e := range s.Events() {
+		name := e.Name
+		if e.Parent != "" {
+			name = fmt.Sprintf("%s (%s)", e.Name, e.Parent)
+		} else {
+			total += e.Duration
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", name, e.Result, e.Duration.Round(time.Millisecond))
+	}
+	fmt.Fprintf(tw, "total\t\t%s\n", total.Round(time.Millisecond))
+	return tw.Flush()
+}
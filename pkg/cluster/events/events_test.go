@@ -0,0 +1,109 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+	summary := &Summary{}
+	handlers := []Handler{summary.Handle}
+	assert.ExpectError(t, false, Run(handlers, "provision", func() error {
+		return nil
+	}))
+	assert.ExpectError(t, true, Run(handlers, "kubeadminit", func() error {
+		return errors.New("preflight failed")
+	}))
+
+	got := summary.Events()
+	assert.DeepEqual(t, 2, len(got))
+	assert.StringEqual(t, "provision", got[0].Name)
+	assert.DeepEqual(t, Success, got[0].Result)
+	assert.StringEqual(t, "", got[0].Error)
+	assert.DeepEqual(t, got[0].End.Sub(got[0].Start), got[0].Duration)
+	assert.StringEqual(t, "kubeadminit", got[1].Name)
+	assert.DeepEqual(t, Failure, got[1].Result)
+	assert.StringEqual(t, "preflight failed", got[1].Error)
+}
+
+func TestRunContext(t *testing.T) {
+	t.Parallel()
+	summary := &Summary{}
+	ctx := NewContext(context.Background(), "provision", []Handler{summary.Handle})
+	assert.ExpectError(t, false, Run([]Handler{summary.Handle}, "provision", func() error {
+		return RunContext(ctx, "pull-images", func() error {
+			return nil
+		})
+	}))
+	// without handlers nothing is emitted
+	assert.ExpectError(t, false, RunContext(context.Background(), "create-nodes", func() error {
+		return nil
+	}))
+
+	got := summary.Events()
+	assert.DeepEqual(t, 2, len(got))
+	assert.StringEqual(t, "pull-images", got[0].Name)
+	assert.StringEqual(t, "provision", got[0].Parent)
+	assert.StringEqual(t, "provision", got[1].Name)
+	assert.StringEqual(t, "", got[1].Parent)
+}
+
+func TestJSONLines(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	h := JSONLines(&out)
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	h(Event{Name: "provision", Start: start, End: start.Add(time.Second), Duration: time.Second, Result: Success})
+	h(Event{Name: "kubeadminit", Start: start, End: start, Result: Failure, Error: "failed"})
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	assert.DeepEqual(t, []string{
+		`{"name":"provision","start":"2020-01-01T00:00:00Z","end":"2020-01-01T00:00:01Z","duration":1000000000,"result":"success"}`,
+		`{"name":"kubeadminit","start":"2020-01-01T00:00:00Z","end":"2020-01-01T00:00:00Z","duration":0,"result":"failure","error":"failed"}`,
+	}, lines)
+	var decoded Event
+	assert.ExpectError(t, false, json.Unmarshal([]byte(lines[0]), &decoded))
+	assert.DeepEqual(t, time.Second, decoded.Duration)
+}
+
+func TestSummaryWriteTable(t *testing.T) {
+	t.Parallel()
+	summary := &Summary{}
+	summary.Handle(Event{Name: "pull-images", Parent: "provision", Duration: time.Second, Result: Success})
+	summary.Handle(Event{Name: "provision", Duration: 1500 * time.Millisecond, Result: Success})
+	summary.Handle(Event{Name: "kubeadminit", Duration: 30 * time.Second, Result: Success})
+	summary.Handle(Event{Name: "installcni", Result: Skipped})
+	var out bytes.Buffer
+	assert.ExpectError(t, false, summary.WriteTable(&out))
+	assert.StringEqual(t, `PHASE                    RESULT   DURATION
+pull-images (provision)  success  1s
+provision                success  1.5s
+kubeadminit              success  30s
+installcni               skipped  0s
+total                             31.5s
+`, out.String())
+}
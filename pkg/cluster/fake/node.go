@@ -0,0 +1,341 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// Node is a fake nodes.Node and exec.ContextCmder, which records the
+// commands run on it and returns scripted output instead of running them
+//
+// Commands without a scripted response emulate the few commands kind uses
+// to manage files on nodes against an in-memory filesystem:
+// `cat <path>`, `cp /dev/stdin <path>`, `test -e <path>` and `mkdir`, all
+// other commands succeed without output
+type Node struct {
+	name  string
+	role  string
+	ipv4  string
+	ipv6  string
+	image string
+
+	mu        sync.Mutex
+	stopped   bool
+	files     map[string]string
+	responses []response
+	commands  []Command
+}
+
+// Command is a command run on a Node
+type Command struct {
+	Command string
+	Args    []string
+	Env     []string
+	// Stdin is the entire input supplied to the command, if any
+	Stdin string
+}
+
+// String returns the command line of c
+func (c Command) String() string {
+	return strings.Join(append([]string{c.Command}, c.Args...), " ")
+}
+
+// response is a scripted response to commands starting with prefix
+type response struct {
+	prefix string
+	output string
+	err    error
+	// blocked, if set, makes the command run until its context is done
+	// it is closed once the first such command runs
+	blocked chan struct{}
+	once    *sync.Once
+}
+
+// started returns a func to call once a command blocks on r, or nil if
+// commands do not block on r
+func (r *response) started() func() {
+	if r.blocked == nil {
+		return nil
+	}
+	return func() {
+		r.once.Do(func() { close(r.blocked) })
+	}
+}
+
+// NewNode returns a new fake node named name with role and addresses
+func NewNode(name, role, ipv4, ipv6 string) *Node {
+	return &Node{
+		name:  name,
+		role:  role,
+		ipv4:  ipv4,
+		ipv6:  ipv6,
+		files: map[string]string{},
+	}
+}
+
+// String is part of the nodes.Node interface
+func (n *Node) String() string {
+	return n.name
+}
+
+// Role is part of the nodes.Node interface
+func (n *Node) Role() (string, error) {
+	return n.role, nil
+}
+
+// IP is part of the nodes.Node interface
+func (n *Node) IP() (ipv4 string, ipv6 string, err error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.ipv4, n.ipv6, nil
+}
+
+// Image returns the node image the node was provisioned with, if any
+func (n *Node) Image() string {
+	return n.image
+}
+
+// Running returns false if the node was stopped and not started again
+func (n *Node) Running() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return !n.stopped
+}
+
+// Script makes commands whose command line starts with prefix write output
+// and return err instead of being emulated
+// Later scripts take precedence over earlier ones
+func (n *Node) Script(prefix, output string, err error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.responses = append(n.responses, response{
+		prefix: prefix,
+		output: output,
+		err:    err,
+	})
+}
+
+// Block makes commands whose command line starts with prefix hang until the
+// context they were created with is done, like a stuck process
+// The returned channel is closed once the first of them runs
+// Later scripts take precedence over earlier ones
+func (n *Node) Block(prefix string) <-chan struct{} {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	blocked := make(chan struct{})
+	n.responses = append(n.responses, response{
+		prefix:  prefix,
+		blocked: blocked,
+		once:    &sync.Once{},
+	})
+	return blocked
+}
+
+// SetFile sets the contents of the file at path
+func (n *Node) SetFile(path, contents string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.files[path] = contents
+}
+
+// File returns the contents of the file at path, and whether it exists
+func (n *Node) File(path string) (string, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	contents, ok := n.files[path]
+	return contents, ok
+}
+
+// Files returns the sorted paths of all files on the node
+func (n *Node) Files() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	paths := make([]string, 0, len(n.files))
+	for path := range n.files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Commands returns the commands run on the node so far, in order
+func (n *Node) Commands() []Command {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]Command{}, n.commands...)
+}
+
+// Ran returns the first command run on the node starting with prefix, if any
+func (n *Node) Ran(prefix string) (Command, bool) {
+	for _, c := range n.Commands() {
+		if strings.HasPrefix(c.String(), prefix) {
+			return c, true
+		}
+	}
+	return Command{}, false
+}
+
+// Command is part of the exec.Cmder interface
+func (n *Node) Command(command string, args ...string) exec.Cmd {
+	return n.CommandContext(context.Background(), command, args...)
+}
+
+// CommandContext is part of the exec.ContextCmder interface
+// Commands are not run once ctx is done
+func (n *Node) CommandContext(ctx context.Context, command string, args ...string) exec.Cmd {
+	return &nodeCmd{
+		node:    n,
+		command: command,
+		args:    args,
+		ctx:     ctx,
+	}
+}
+
+// run records c and returns its output, and a func to call before blocking
+// if it blocks
+func (n *Node) run(c Command) (string, func(), error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.commands = append(n.commands, c)
+	line := c.String()
+	for i := len(n.responses) - 1; i >= 0; i-- {
+		if strings.HasPrefix(line, n.responses[i].prefix) {
+			return n.responses[i].output, n.responses[i].started(), n.responses[i].err
+		}
+	}
+	switch {
+	case c.Command == "cat" && len(c.Args) == 1:
+		contents, ok := n.files[c.Args[0]]
+		if !ok {
+			return "cat: " + c.Args[0] + ": No such file or directory\n", nil, &ExitError{Code: 1}
+		}
+		return contents, nil, nil
+	case c.Command == "test" && len(c.Args) == 2 && c.Args[0] == "-e":
+		if _, ok := n.files[c.Args[1]]; !ok {
+			return "", nil, &ExitError{Code: 1}
+		}
+	case c.Command == "cp" && len(c.Args) == 2 && c.Args[0] == "/dev/stdin":
+		n.files[c.Args[1]] = c.Stdin
+	}
+	return "", nil, nil
+}
+
+// ExitError is the error of a command exiting non-zero on a fake node,
+// exposing the exit code like os/exec.ExitError
+type ExitError struct {
+	Code int
+}
+
+var _ error = &ExitError{}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// ExitCode returns the exit code of the command
+func (e *ExitError) ExitCode() int {
+	return e.Code
+}
+
+// nodeCmd implements exec.Cmd for fake nodes
+type nodeCmd struct {
+	node    *Node
+	command string
+	args    []string
+	env     []string
+	stdin   io.Reader
+	stdout  io.Writer
+	stderr  io.Writer
+	ctx     context.Context
+}
+
+// Run is part of the exec.Cmd interface
+func (c *nodeCmd) Run() error {
+	command := Command{
+		Command: c.command,
+		Args:    append([]string{}, c.args...),
+		Env:     append([]string{}, c.env...),
+	}
+	if c.stdin != nil {
+		var stdin bytes.Buffer
+		if _, err := io.Copy(&stdin, c.stdin); err != nil {
+			return errors.Wrap(err, "failed to read stdin")
+		}
+		command.Stdin = stdin.String()
+	}
+	var output string
+	err := c.ctx.Err()
+	if err == nil {
+		var started func()
+		output, started, err = c.node.run(command)
+		if started != nil {
+			started()
+			<-c.ctx.Done()
+			err = c.ctx.Err()
+		}
+	}
+	stdout := c.stdout
+	if stdout == nil {
+		stdout = ioutil.Discard
+	}
+	if _, werr := io.WriteString(stdout, output); werr != nil {
+		return errors.Wrap(werr, "failed to write stdout")
+	}
+	if err != nil {
+		return errors.WithStack(&exec.RunError{
+			Command: append([]string{c.command}, c.args...),
+			Output:  []byte(output),
+			Inner:   err,
+		})
+	}
+	return nil
+}
+
+// SetEnv is part of the exec.Cmd interface
+func (c *nodeCmd) SetEnv(env ...string) exec.Cmd {
+	c.env = env
+	return c
+}
+
+// SetStdin is part of the exec.Cmd interface
+func (c *nodeCmd) SetStdin(r io.Reader) exec.Cmd {
+	c.stdin = r
+	return c
+}
+
+// SetStdout is part of the exec.Cmd interface
+func (c *nodeCmd) SetStdout(w io.Writer) exec.Cmd {
+	c.stdout = w
+	return c
+}
+
+// SetStderr is part of the exec.Cmd interface
+func (c *nodeCmd) SetStderr(w io.Writer) exec.Cmd {
+	c.stderr = w
+	return c
+}
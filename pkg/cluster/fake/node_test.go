@@ -0,0 +1,110 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestNodeFiles(t *testing.T) {
+	t.Parallel()
+	n := NewNode("kind-control-plane", "control-plane", "172.17.0.2", "")
+	cmd := n.Command("cp", "/dev/stdin", "/kind/kubeadm.conf").SetStdin(strings.NewReader("config"))
+	assert.ExpectError(t, false, cmd.Run())
+	contents, ok := n.File("/kind/kubeadm.conf")
+	assert.DeepEqual(t, true, ok)
+	assert.StringEqual(t, "config", contents)
+
+	var out bytes.Buffer
+	assert.ExpectError(t, false, n.Command("cat", "/kind/kubeadm.conf").SetStdout(&out).Run())
+	assert.StringEqual(t, "config", out.String())
+
+	// missing files fail like cat would
+	err := n.Command("cat", "/missing").Run()
+	assert.ExpectError(t, true, err)
+	if exec.RunErrorForError(err) == nil {
+		t.Errorf("expected a RunError, got %v", err)
+	}
+
+	assert.ExpectError(t, false, n.Command("test", "-e", "/kind/kubeadm.conf").Run())
+	assert.ExpectError(t, true, n.Command("test", "-e", "/missing").Run())
+
+	assert.DeepEqual(t, []Command{
+		{Command: "cp", Args: []string{"/dev/stdin", "/kind/kubeadm.conf"}, Env: []string{}, Stdin: "config"},
+		{Command: "cat", Args: []string{"/kind/kubeadm.conf"}, Env: []string{}},
+		{Command: "cat", Args: []string{"/missing"}, Env: []string{}},
+		{Command: "test", Args: []string{"-e", "/kind/kubeadm.conf"}, Env: []string{}},
+		{Command: "test", Args: []string{"-e", "/missing"}, Env: []string{}},
+	}, n.Commands())
+}
+
+func TestNodeScript(t *testing.T) {
+	t.Parallel()
+	n := NewNode("kind-worker", "worker", "172.17.0.3", "")
+	n.SetFile("/kind/version", "v1.17.0")
+	n.Script("kubeadm", "", errors.New("exit status 1"))
+	n.Script("kubeadm join", "joined", nil)
+	n.Script("cat /kind/version", "v1.18.0", nil)
+
+	lines, err := exec.CombinedOutputLines(n.Command("kubeadm", "join", "--config", "/kind/kubeadm.conf"))
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, []string{"joined"}, lines)
+	assert.ExpectError(t, true, n.Command("kubeadm", "reset").Run())
+
+	// scripts take precedence over files
+	lines, err = exec.OutputLines(n.Command("cat", "/kind/version"))
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, []string{"v1.18.0"}, lines)
+
+	// everything else succeeds
+	assert.ExpectError(t, false, n.Command("systemctl", "restart", "kubelet").SetEnv("A=B").Run())
+	commands := n.Commands()
+	assert.DeepEqual(t, []string{"A=B"}, commands[len(commands)-1].Env)
+}
+
+func TestNodeCommandContext(t *testing.T) {
+	t.Parallel()
+	n := NewNode("kind-worker", "worker", "172.17.0.3", "")
+	blocked := n.Block("kubeadm join")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- n.CommandContext(ctx, "kubeadm", "join").Run()
+	}()
+	// the command hangs until it is cancelled
+	<-blocked
+	cancel()
+	err := <-errCh
+	assert.ExpectError(t, true, err)
+	assert.StringEqual(t, `command "kubeadm join" failed with error: context canceled`, err.Error())
+
+	// commands are not run once their context is done
+	err = n.CommandContext(ctx, "kubeadm", "reset").Run()
+	assert.ExpectError(t, true, err)
+	assert.StringEqual(t, `command "kubeadm reset" failed with error: context canceled`, err.Error())
+	assert.DeepEqual(t, []Command{
+		{Command: "kubeadm", Args: []string{"join"}, Env: []string{}},
+	}, n.Commands())
+}
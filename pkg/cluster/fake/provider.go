@@ -0,0 +1,311 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/events"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/cli"
+)
+
+// Provider is an in-memory cluster provider, which provisions a fake Node
+// for each node in the cluster config instead of running containers
+// Like the real providers, its methods fail once their context is done
+type Provider struct {
+	// Setup, if set, is called with each node Provision creates before it
+	// is listed, to script responses and seed files
+	Setup func(n *Node)
+	// ProvisionError, if set, is returned by Provision instead of creating
+	// any nodes
+	ProvisionError error
+
+	mu        sync.Mutex
+	clusters  map[string][]*Node
+	endpoints map[string]string
+	addresses int
+}
+
+var _ provider.Provider = &Provider{}
+
+// NewProvider returns a new Provider with no clusters
+func NewProvider() *Provider {
+	return &Provider{
+		clusters:  map[string][]*Node{},
+		endpoints: map[string]string{},
+	}
+}
+
+// Provision is part of the providers.Provider interface
+func (p *Provider) Provision(ctx context.Context, status *cli.Status, cluster string, cfg *config.Cluster) (err error) {
+	status.Start("Preparing nodes 📦")
+	defer func() { status.End(err == nil) }()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if p.ProvisionError != nil {
+		return p.ProvisionError
+	}
+	if existing := p.Nodes(cluster); len(existing) > 0 {
+		return errors.Errorf("cluster %q already exists", cluster)
+	}
+
+	return events.RunContext(ctx, "create-nodes", func() error {
+		return p.provision(cluster, cfg)
+	})
+}
+
+// provision creates the nodes of cluster
+func (p *Provider) provision(cluster string, cfg *config.Cluster) error {
+	names, roles := planNodes(cluster, cfg)
+	created := make([]*Node, 0, len(names))
+	for i, name := range names {
+		created = append(created, p.newNode(name, roles[i]))
+	}
+	// the load balancer, if any, comes first and has no node image
+	offset := len(created) - len(cfg.Nodes)
+	for i, node := range cfg.Nodes {
+		created[offset+i].image = node.Image
+	}
+	if p.Setup != nil {
+		for _, n := range created {
+			p.Setup(n)
+		}
+	}
+
+	port := cfg.Networking.APIServerPort
+	if port == 0 {
+		port = common.APIServerInternalPort
+	}
+	address := cfg.Networking.APIServerAddress
+	if address == "" {
+		address = "127.0.0.1"
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clusters[cluster] = created
+	p.endpoints[cluster] = net.JoinHostPort(address, strconv.Itoa(int(port)))
+	return nil
+}
+
+// ProvisionDryRun is part of the providers.Provider interface
+// The objects of the fake provider are the names and roles of its nodes
+func (p *Provider) ProvisionDryRun(cluster string, cfg *config.Cluster) (string, error) {
+	var b strings.Builder
+	names, roles := planNodes(cluster, cfg)
+	for i, name := range names {
+		fmt.Fprintf(&b, "%s %s\n", name, roles[i])
+	}
+	return b.String(), nil
+}
+
+// planNodes returns the names and roles of the nodes Provision creates for
+// cluster, in creation order
+func planNodes(cluster string, cfg *config.Cluster) (names, roles []string) {
+	nodeNamer := common.MakeNodeNamer(cluster)
+	controlPlanes := 0
+	for _, node := range cfg.Nodes {
+		if node.Role == config.ControlPlaneRole {
+			controlPlanes++
+		}
+	}
+	if controlPlanes > 1 {
+		roles = append(roles, constants.ExternalLoadBalancerNodeRoleValue)
+	}
+	for _, node := range cfg.Nodes {
+		roles = append(roles, string(node.Role))
+	}
+	for _, role := range roles {
+		names = append(names, nodeNamer(role))
+	}
+	return names, roles
+}
+
+// ProvisionNode is part of the providers.Provider interface
+func (p *Provider) ProvisionNode(ctx context.Context, status *cli.Status, cluster, name string, cfg *config.Cluster, node *config.Node) (err error) {
+	status.Start(fmt.Sprintf("Preparing node %s 📦", name))
+	defer func() { status.End(err == nil) }()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if p.ProvisionError != nil {
+		return p.ProvisionError
+	}
+	existing := p.Nodes(cluster)
+	if len(existing) == 0 {
+		return errors.Errorf("unknown cluster %q", cluster)
+	}
+	for _, n := range existing {
+		if n.String() == name {
+			return errors.Errorf("node %q already exists", name)
+		}
+	}
+	created := p.newNode(name, string(node.Role))
+	created.image = node.Image
+	if p.Setup != nil {
+		p.Setup(created)
+	}
+	p.AddNode(cluster, created)
+	return nil
+}
+
+// newNode returns a new node with the next free fake addresses
+func (p *Provider) newNode(name, role string) *Node {
+	ipv4, ipv6 := p.nextAddresses()
+	return NewNode(name, role, ipv4, ipv6)
+}
+
+// nextAddresses returns the next free fake addresses
+func (p *Provider) nextAddresses() (string, string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.addresses++
+	return fmt.Sprintf("172.17.0.%d", p.addresses+1), fmt.Sprintf("fc00::%d", p.addresses+1)
+}
+
+// AddNode adds n to cluster, creating the cluster if necessary, this is
+// useful to test operations on existing clusters
+func (p *Provider) AddNode(cluster string, n *Node) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clusters[cluster] = append(p.clusters[cluster], n)
+	if _, ok := p.endpoints[cluster]; !ok {
+		p.endpoints[cluster] = net.JoinHostPort("127.0.0.1", strconv.Itoa(common.APIServerInternalPort))
+	}
+}
+
+// Nodes returns the fake nodes of cluster sorted by name
+func (p *Provider) Nodes(cluster string) []*Node {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	sorted := append([]*Node{}, p.clusters[cluster]...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].name < sorted[j].name
+	})
+	return sorted
+}
+
+// ListClusters is part of the providers.Provider interface
+func (p *Provider) ListClusters() ([]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	clusters := make([]string, 0, len(p.clusters))
+	for cluster := range p.clusters {
+		clusters = append(clusters, cluster)
+	}
+	sort.Strings(clusters)
+	return clusters, nil
+}
+
+// ListNodes is part of the providers.Provider interface
+func (p *Provider) ListNodes(cluster string) ([]nodes.Node, error) {
+	fakeNodes := p.Nodes(cluster)
+	ret := make([]nodes.Node, 0, len(fakeNodes))
+	for _, n := range fakeNodes {
+		ret = append(ret, n)
+	}
+	return ret, nil
+}
+
+// DeleteNodes is part of the providers.Provider interface
+func (p *Provider) DeleteNodes(ctx context.Context, n []nodes.Node) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	deleted := map[nodes.Node]bool{}
+	for _, node := range n {
+		deleted[node] = true
+	}
+	for cluster, clusterNodes := range p.clusters {
+		remaining := []*Node{}
+		for _, node := range clusterNodes {
+			if !deleted[node] {
+				remaining = append(remaining, node)
+			}
+		}
+		if len(remaining) == 0 {
+			delete(p.clusters, cluster)
+			delete(p.endpoints, cluster)
+			continue
+		}
+		p.clusters[cluster] = remaining
+	}
+	return nil
+}
+
+// StopNodes is part of the providers.Provider interface
+func (p *Provider) StopNodes(ctx context.Context, n []nodes.Node) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	for _, node := range n {
+		fakeNode, ok := node.(*Node)
+		if !ok {
+			return errors.Errorf("unknown node %s", node)
+		}
+		fakeNode.mu.Lock()
+		fakeNode.stopped = true
+		fakeNode.mu.Unlock()
+	}
+	return nil
+}
+
+// StartNodes is part of the providers.Provider interface
+// Like containers, started nodes are assigned new addresses
+func (p *Provider) StartNodes(ctx context.Context, n []nodes.Node) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	for _, node := range n {
+		fakeNode, ok := node.(*Node)
+		if !ok {
+			return errors.Errorf("unknown node %s", node)
+		}
+		ipv4, ipv6 := p.nextAddresses()
+		fakeNode.mu.Lock()
+		fakeNode.stopped = false
+		fakeNode.ipv4, fakeNode.ipv6 = ipv4, ipv6
+		fakeNode.mu.Unlock()
+	}
+	return nil
+}
+
+// GetAPIServerEndpoint is part of the providers.Provider interface
+func (p *Provider) GetAPIServerEndpoint(cluster string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	endpoint, ok := p.endpoints[cluster]
+	if !ok {
+		return "", errors.Errorf("unknown cluster %q", cluster)
+	}
+	return endpoint, nil
+}
@@ -19,11 +19,11 @@ limitations under the License.
 package context
 
 import (
+	stdcontext "context"
+
 	"sigs.k8s.io/kind/pkg/cluster/constants"
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
-	"sigs.k8s.io/kind/pkg/log"
 
-	"sigs.k8s.io/kind/pkg/cluster/internal/providers/docker"
 	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider"
 )
 
@@ -35,20 +35,10 @@ import (
 // consume this
 type Context struct {
 	name string
-	// cluster backend (docker, ...)
+	// cluster backend (docker, kubernetes, ...)
 	provider provider.Provider
-}
-
-// NewContext returns a new internal cluster management context
-// if name is "" the default name will be used
-func NewContext(logger log.Logger, name string) *Context {
-	if name == "" {
-		name = constants.DefaultClusterName
-	}
-	return &Context{
-		name:     name,
-		provider: docker.NewProvider(logger),
-	}
+	// ctx cancels the operations on the cluster, see WithContext
+	ctx stdcontext.Context
 }
 
 // NewProviderContext returns a new context with given provider and name
@@ -59,6 +49,23 @@ func NewProviderContext(p provider.Provider, name string) *Context {
 	}
 }
 
+// WithContext returns a shallow copy of c with its context changed to ctx,
+// which cancels the provider calls and node commands using Context()
+func (c *Context) WithContext(ctx stdcontext.Context) *Context {
+	c2 := *c
+	c2.ctx = ctx
+	return &c2
+}
+
+// Context returns the context of c, which defaults to
+// context.Background()
+func (c *Context) Context() stdcontext.Context {
+	if c.ctx != nil {
+		return c.ctx
+	}
+	return stdcontext.Background()
+}
+
 // Name returns the cluster's name
 func (c *Context) Name() string {
 	return c.name
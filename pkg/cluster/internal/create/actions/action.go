@@ -17,6 +17,7 @@ limitations under the License.
 package actions
 
 import (
+	stdcontext "context"
 	"sync"
 
 	"sigs.k8s.io/kind/pkg/cluster/internal/context"
@@ -74,6 +75,12 @@ func (cd *cachedData) setNodes(n []nodes.Node) {
 	cd.nodes = n
 }
 
+// Context returns the context of the cluster being created, actions should
+// stop once it is done, E.G. by running node commands with CommandContext
+func (ac *ActionContext) Context() stdcontext.Context {
+	return ac.ClusterContext.Context()
+}
+
 // Nodes returns the list of cluster nodes, this is a cached call
 func (ac *ActionContext) Nodes() ([]nodes.Node, error) {
 	cachedNodes := ac.cache.getNodes()
@@ -0,0 +1,44 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package actionstest contains helpers for testing create actions
+package actionstest
+
+import (
+	stdcontext "context"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/cluster/fake"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/context"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+)
+
+// NewContext provisions the nodes of cfg with the fake provider and returns
+// an action context for the cluster "kind"
+func NewContext(t *testing.T, cfg *config.Cluster) (*fake.Provider, *actions.ActionContext) {
+	config.SetDefaultsCluster(cfg)
+	p := fake.NewProvider()
+	status := cli.StatusForLogger(log.NoopLogger{})
+	if err := p.Provision(stdcontext.Background(), status, "kind", cfg); err != nil {
+		t.Fatalf("failed to provision nodes: %v", err)
+	}
+	ctx := context.NewProviderContext(p, "kind")
+	return p, actions.NewActionContext(log.NoopLogger{}, cfg, ctx, status)
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package addons implements the action applying the addon manifests of the
+// cluster config
+package addons
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+)
+
+// waitTimeout is how long to wait for each addon workload to become available
+const waitTimeout = 5 * time.Minute
+
+type action struct{}
+
+// NewAction returns a new action for applying addons
+func NewAction() actions.Action {
+	return &action{}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	if len(ctx.Config.Addons) == 0 {
+		return nil
+	}
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+
+	// get the target node for this task
+	controlPlanes, err := nodeutils.ControlPlaneNodes(allNodes)
+	if err != nil {
+		return err
+	}
+	node := controlPlanes[0] // kind expects at least one always
+
+	for i := range ctx.Config.Addons {
+		if err := installAddon(ctx, node, &ctx.Config.Addons[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// installAddon applies the manifests of addon from node and waits for its
+// workloads
+func installAddon(ctx *actions.ActionContext, node nodes.Node, addon *config.Addon) error {
+	ctx.Status.Start(fmt.Sprintf("Installing addon %s 🧩", addon.Name))
+	defer ctx.Status.End(false)
+
+	manifests, err := readManifests(addon.Manifests)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read manifests of addon %s", addon.Name)
+	}
+	cmd := exec.CommandContextFor(ctx.Context(), node,
+		"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf",
+		"apply", "--server-side", "-f", "-",
+	)
+	cmd.SetStdin(bytes.NewReader(manifests))
+	lines, err := exec.CombinedOutputLines(cmd)
+	ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
+	if err != nil {
+		return errors.Wrapf(err, "failed to apply addon %s", addon.Name)
+	}
+
+	for _, w := range addon.WaitFor {
+		cmd := exec.CommandContextFor(ctx.Context(), node,
+			"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf",
+			"rollout", "status", "--namespace="+w.Namespace,
+			fmt.Sprintf("--timeout=%s", waitTimeout),
+			strings.ToLower(w.Kind)+"/"+w.Name,
+		)
+		lines, err := exec.CombinedOutputLines(cmd)
+		ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
+		if err != nil {
+			return errors.Wrapf(err, "%s %s/%s of addon %s did not become available", w.Kind, w.Namespace, w.Name, addon.Name)
+		}
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// readManifests returns the manifests at paths as a single multi-document
+// yaml stream
+// Directories contribute their .yaml, .yml and .json files in lexical order
+func readManifests(paths []string) ([]byte, error) {
+	files := []string{}
+	for _, path := range paths {
+		dirFiles, err := manifestFiles(path)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, dirFiles...)
+	}
+
+	var b bytes.Buffer
+	for _, file := range files {
+		contents, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString("---\n")
+		b.Write(contents)
+		if len(contents) > 0 && contents[len(contents)-1] != '\n' {
+			b.WriteString("\n")
+		}
+	}
+	return b.Bytes(), nil
+}
+
+// manifestFiles returns path if it is a file, or the manifest files in path
+// if it is a directory
+func manifestFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	infos, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{}
+	for _, info := range infos {
+		if info.IsDir() {
+			continue
+		}
+		switch filepath.Ext(info.Name()) {
+		case ".yaml", ".yml", ".json":
+			files = append(files, filepath.Join(path, info.Name()))
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addons
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/actionstest"
+)
+
+// writeManifests creates a directory of manifests for testing
+func writeManifests(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "kind-addons-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	files := map[string]string{
+		"rbac.yaml":              "kind: ClusterRole\n",
+		"crds/b.yml":             "kind: CustomResourceDefinition\nmetadata:\n  name: b\n",
+		"crds/a.json":            `{"kind": "CustomResourceDefinition", "metadata": {"name": "a"}}`,
+		"crds/README.md":         "not a manifest",
+		"crds/nested/c.yaml":     "kind: Ignored\n",
+		"operator/operator.yaml": "kind: Deployment\n",
+	}
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("failed to write manifest: %v", err)
+		}
+	}
+	return dir
+}
+
+func TestReadManifests(t *testing.T) {
+	t.Parallel()
+	dir := writeManifests(t)
+	defer os.RemoveAll(dir)
+
+	manifests, err := readManifests([]string{
+		filepath.Join(dir, "rbac.yaml"),
+		filepath.Join(dir, "crds"),
+	})
+	assert.ExpectError(t, false, err)
+	assert.StringEqual(t, `---
+kind: ClusterRole
+---
+{"kind": "CustomResourceDefinition", "metadata": {"name": "a"}}
+---
+kind: CustomResourceDefinition
+metadata:
+  name: b
+`, string(manifests))
+
+	_, err = readManifests([]string{filepath.Join(dir, "missing.yaml")})
+	assert.ExpectError(t, true, err)
+}
+
+func TestAction(t *testing.T) {
+	t.Parallel()
+	dir := writeManifests(t)
+	defer os.RemoveAll(dir)
+
+	p, ctx := actionstest.NewContext(t, &config.Cluster{
+		Addons: []config.Addon{
+			{
+				Name:      "rbac",
+				Manifests: []string{filepath.Join(dir, "rbac.yaml")},
+			},
+			{
+				Name:      "operator",
+				Manifests: []string{filepath.Join(dir, "operator")},
+				WaitFor: []config.AddonWorkload{
+					{Kind: "Deployment", Namespace: "operators", Name: "operator"},
+				},
+			},
+		},
+	})
+	assert.ExpectError(t, false, NewAction().Execute(ctx))
+
+	node := p.Nodes("kind")[0]
+	commands := node.Commands()
+	assert.DeepEqual(t, 3, len(commands))
+	assert.StringEqual(t, "kubectl --kubeconfig=/etc/kubernetes/admin.conf apply --server-side -f -", commands[0].String())
+	assert.StringEqual(t, "---\nkind: ClusterRole\n", commands[0].Stdin)
+	assert.StringEqual(t, "---\nkind: Deployment\n", commands[1].Stdin)
+	assert.StringEqual(t, "kubectl --kubeconfig=/etc/kubernetes/admin.conf rollout status --namespace=operators --timeout=5m0s deployment/operator", commands[2].String())
+}
+
+func TestActionWaitFailure(t *testing.T) {
+	t.Parallel()
+	dir := writeManifests(t)
+	defer os.RemoveAll(dir)
+
+	p, ctx := actionstest.NewContext(t, &config.Cluster{
+		Addons: []config.Addon{
+			{
+				Name:      "operator",
+				Manifests: []string{filepath.Join(dir, "operator")},
+				WaitFor: []config.AddonWorkload{
+					{Kind: "DaemonSet", Namespace: "operators", Name: "agent"},
+				},
+			},
+		},
+	})
+	p.Nodes("kind")[0].Script("kubectl --kubeconfig=/etc/kubernetes/admin.conf rollout status", "timed out", errors.New("exit status 1"))
+	err := NewAction().Execute(ctx)
+	assert.ExpectError(t, true, err)
+	if !strings.Contains(err.Error(), "DaemonSet operators/agent of addon operator") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -19,11 +19,16 @@ package config
 
 import (
 	"bytes"
+	"context"
+	"net"
 	"strings"
 
+	"sigs.k8s.io/yaml"
+
 	"sigs.k8s.io/kind/pkg/cluster/constants"
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
 	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
 
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
 	"sigs.k8s.io/kind/pkg/cluster/internal/kubeadm"
@@ -33,14 +38,51 @@ import (
 	"sigs.k8s.io/kind/pkg/internal/apis/config"
 )
 
+// ClusterConfigPath is where the cluster config is written on control plane
+// nodes, so that nodes added to the cluster later are configured alike
+const ClusterConfigPath = "/kind/cluster-config.yaml"
+
 // Action implements action for creating the node config files
-type Action struct{}
+type Action struct {
+	// nodeName limits the action to the config of the named node, if set
+	nodeName string
+	// token overrides the default bootstrap token, if set
+	token string
+	// certificateKey is the key of the control plane certificates uploaded
+	// for control plane nodes joining an existing cluster, if any
+	certificateKey string
+}
 
 // NewAction returns a new action for creating the config files
 func NewAction() actions.Action {
 	return &Action{}
 }
 
+// NewNodeAction returns a new action for creating the config file of the
+// node name, joining an existing cluster with the bootstrap token and, for
+// control plane nodes, the uploaded certificates with certificateKey
+func NewNodeAction(name, token, certificateKey string) actions.Action {
+	return &Action{
+		nodeName:       name,
+		token:          token,
+		certificateKey: certificateKey,
+	}
+}
+
+// selectNodes returns the nodes the action applies to
+func (a *Action) selectNodes(allNodes []nodes.Node) []nodes.Node {
+	if a.nodeName == "" {
+		return allNodes
+	}
+	selected := []nodes.Node{}
+	for _, node := range allNodes {
+		if node.String() == a.nodeName {
+			selected = append(selected, node)
+		}
+	}
+	return selected
+}
+
 // Execute runs the action
 func (a *Action) Execute(ctx *actions.ActionContext) error {
 	ctx.Status.Start("Writing configuration 📜")
@@ -64,6 +106,15 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 		controlPlaneEndpoint = controlPlaneEndpointIPv6
 	}
 
+	// the API server certificate must be valid for the address the provider
+	// exposes the API server on, which may only be known after provisioning
+	apiServerAddress := ctx.Config.Networking.APIServerAddress
+	if endpoint, err := ctx.ClusterContext.GetAPIServerEndpoint(); err == nil {
+		if host, _, err := net.SplitHostPort(endpoint); err == nil {
+			apiServerAddress = host
+		}
+	}
+
 	// create kubeadm init config
 	fns := []func() error{}
 
@@ -71,13 +122,17 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 		ClusterName:          ctx.ClusterContext.Name(),
 		ControlPlaneEndpoint: controlPlaneEndpoint,
 		APIBindPort:          common.APIServerInternalPort,
-		APIServerAddress:     ctx.Config.Networking.APIServerAddress,
+		APIServerAddress:     apiServerAddress,
 		Token:                kubeadm.Token,
 		PodSubnet:            ctx.Config.Networking.PodSubnet,
 		ServiceSubnet:        ctx.Config.Networking.ServiceSubnet,
 		ControlPlane:         true,
 		IPv6:                 ctx.Config.Networking.IPFamily == "ipv6",
 	}
+	if a.token != "" {
+		configData.Token = a.token
+	}
+	configData.CertificateKey = a.certificateKey
 
 	kubeadmConfigPlusPatches := func(node nodes.Node, data kubeadm.ConfigData) func() error {
 		return func() error {
@@ -97,6 +152,7 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 	if err != nil {
 		return err
 	}
+	controlPlanes = a.selectNodes(controlPlanes)
 
 	for _, node := range controlPlanes {
 		node := node             // capture loop variable
@@ -104,11 +160,26 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 		fns = append(fns, kubeadmConfigPlusPatches(node, configData))
 	}
 
+	// keep the cluster config for adding nodes, unless adding one now
+	if a.nodeName == "" {
+		rawConfig, err := yaml.Marshal(ctx.Config)
+		if err != nil {
+			return errors.Wrap(err, "failed to encode cluster config")
+		}
+		for _, node := range controlPlanes {
+			node := node // capture loop variable
+			fns = append(fns, func() error {
+				return nodeutils.WriteFile(node, ClusterConfigPath, string(rawConfig))
+			})
+		}
+	}
+
 	// then create the kubeadm join config for the worker nodes if any
 	workers, err := nodeutils.SelectNodesByRole(allNodes, constants.WorkerNodeRoleValue)
 	if err != nil {
 		return err
 	}
+	workers = a.selectNodes(workers)
 	if len(workers) > 0 {
 		// create the workers concurrently
 		for _, node := range workers {
@@ -131,32 +202,32 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 		// workers + control planes
 		kubeNodes := append([]nodes.Node{}, controlPlanes...)
 		kubeNodes = append(kubeNodes, workers...)
-		fns := make([]func() error, len(kubeNodes))
+		fns := make([]func(context.Context) error, len(kubeNodes))
 		for i, node := range kubeNodes {
 			node := node // capture loop variable
-			fns[i] = func() error {
+			fns[i] = func(cmdCtx context.Context) error {
 				// read and patch the config
 				const containerdConfigPath = "/etc/containerd/config.toml"
 				var buff bytes.Buffer
-				if err := node.Command("cat", containerdConfigPath).SetStdout(&buff).Run(); err != nil {
+				if err := exec.CommandContextFor(cmdCtx, node, "cat", containerdConfigPath).SetStdout(&buff).Run(); err != nil {
 					return errors.Wrap(err, "failed to read containerd config from node")
 				}
 				patched, err := patch.TOML(buff.String(), ctx.Config.ContainerdConfigPatches, ctx.Config.ContainerdConfigPatchesJSON6902)
 				if err != nil {
 					return errors.Wrap(err, "failed to patch contianerd config")
 				}
-				if err := nodeutils.WriteFile(node, containerdConfigPath, patched); err != nil {
+				if err := nodeutils.WriteFileContext(cmdCtx, node, containerdConfigPath, patched); err != nil {
 					return errors.Wrap(err, "failed to write patched containerd config")
 				}
 				// restart containerd now that we've re-configured it
 				// skip if the systemd (also the containerd) is not running
-				if err := node.Command("bash", "-c", `! systemctl is-system-running || systemctl restart containerd`).Run(); err != nil {
+				if err := exec.CommandContextFor(cmdCtx, node, "bash", "-c", `! systemctl is-system-running || systemctl restart containerd`).Run(); err != nil {
 					return errors.Wrap(err, "failed to restart containerd after patching config")
 				}
 				return nil
 			}
 		}
-		if err := errors.UntilErrorConcurrent(fns); err != nil {
+		if err := errors.UntilErrorConcurrentContext(ctx.Context(), fns); err != nil {
 			return err
 		}
 	}
@@ -239,10 +310,24 @@ func allPatchesFromConfig(cfg *config.Cluster) (patches []string, jsonPatches []
 // writeKubeadmConfig writes the kubeadm configuration in the specified node
 func writeKubeadmConfig(kubeadmConfig string, node nodes.Node) error {
 	// copy the config to the node
-	if err := nodeutils.WriteFile(node, "/kind/kubeadm.conf", kubeadmConfig); err != nil {
+	if err := nodeutils.WriteFile(node, kubeadm.ConfigPath, kubeadmConfig); err != nil {
 		// TODO(bentheelder): logging here
 		return errors.Wrap(err, "failed to copy kubeadm config to node")
 	}
 
 	return nil
 }
+
+// ReadClusterConfig returns the cluster config written on the control plane
+// node n when the cluster was created
+func ReadClusterConfig(ctx context.Context, n nodes.Node) (*config.Cluster, error) {
+	var buff bytes.Buffer
+	if err := exec.CommandContextFor(ctx, n, "cat", ClusterConfigPath).SetStdout(&buff).Run(); err != nil {
+		return nil, errors.Wrapf(err, "failed to read cluster config from node %s", n.String())
+	}
+	cfg := &config.Cluster{}
+	if err := yaml.Unmarshal(buff.Bytes(), cfg); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode cluster config from node %s", n.String())
+	}
+	return cfg, nil
+}
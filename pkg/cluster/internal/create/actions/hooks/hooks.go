@@ -0,0 +1,167 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package hooks implements the action running the user-defined hooks of a
+// point of cluster creation
+package hooks
+
+import (
+	"os"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+)
+
+type action struct {
+	point config.HookPoint
+	host  exec.Cmder
+}
+
+// NewAction returns a new action running the hooks for point
+func NewAction(point config.HookPoint) actions.Action {
+	return &action{
+		point: point,
+		host:  exec.DefaultCmder,
+	}
+}
+
+// ForPoint returns the hooks in cfg that run at point, in order
+func ForPoint(cfg *config.Cluster, point config.HookPoint) []config.Hook {
+	hooks := []config.Hook{}
+	for _, hook := range cfg.Hooks {
+		if hook.When == point {
+			hooks = append(hooks, hook)
+		}
+	}
+	return hooks
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	hooks := ForPoint(ctx.Config, a.point)
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	ctx.Status.Start("Running " + string(a.point) + " hooks 🪝")
+	defer ctx.Status.End(false)
+
+	for i := range hooks {
+		hook := &hooks[i]
+		if hook.OnHost {
+			cmd := exec.CommandContextFor(ctx.Context(), a.host, hook.Command[0], hook.Command[1:]...)
+			cmd.SetEnv(hostEnv(ctx.ClusterContext.Name())...)
+			if err := runHook(ctx, hook, "the host", cmd); err != nil {
+				return err
+			}
+			continue
+		}
+		targets, err := selectNodes(ctx, hook)
+		if err != nil {
+			return errors.Wrapf(err, "failed to select nodes for hook %s", hookName(hook))
+		}
+		for _, node := range targets {
+			cmd := exec.CommandContextFor(ctx.Context(), node, hook.Command[0], hook.Command[1:]...)
+			if err := runHook(ctx, hook, node.String(), cmd); err != nil {
+				return err
+			}
+		}
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// runHook runs cmd for hook on target, logging its output, which is also
+// included in the error if it fails
+func runHook(ctx *actions.ActionContext, hook *config.Hook, target string, cmd exec.Cmd) error {
+	lines, err := exec.CombinedOutputLines(cmd)
+	output := strings.Join(lines, "\n")
+	if err != nil {
+		return errors.Wrapf(err, "failed to run hook %s on %s, output:\n%s", hookName(hook), target, output)
+	}
+	ctx.Logger.V(1).Infof("Output of hook %s on %s:\n%s", hookName(hook), target, output)
+	return nil
+}
+
+// selectNodes returns the cluster nodes hook runs on, in the order they are
+// listed
+// Nodes matching either the roles or the names of hook are selected, or all
+// of the control-plane and worker nodes if it selects neither
+// Only control-plane and worker nodes are candidates, as ListInternalNodes
+// filters out the others such as the external load balancer, so hooks
+// naming those fail as naming unknown nodes
+func selectNodes(ctx *actions.ActionContext, hook *config.Hook) ([]nodes.Node, error) {
+	allNodes, err := ctx.ClusterContext.ListInternalNodes()
+	if err != nil {
+		return nil, err
+	}
+	if len(hook.Roles) == 0 && len(hook.Nodes) == 0 {
+		return allNodes, nil
+	}
+
+	roles := map[string]bool{}
+	for _, role := range hook.Roles {
+		roles[string(role)] = true
+	}
+	names := map[string]bool{}
+	for _, name := range hook.Nodes {
+		names[name] = true
+	}
+
+	selected := []nodes.Node{}
+	for _, node := range allNodes {
+		if names[node.String()] {
+			delete(names, node.String())
+			selected = append(selected, node)
+			continue
+		}
+		role, err := node.Role()
+		if err != nil {
+			return nil, err
+		}
+		if roles[role] {
+			selected = append(selected, node)
+		}
+	}
+	// every named node must exist
+	for _, name := range hook.Nodes {
+		if names[name] {
+			return nil, errors.Errorf("unknown node %q", name)
+		}
+	}
+	return selected, nil
+}
+
+// hostEnv returns the environment of host hooks for cluster
+func hostEnv(cluster string) []string {
+	env := append([]string{}, os.Environ()...)
+	return append(env, "KIND_CLUSTER_NAME="+cluster)
+}
+
+// hookName returns the name of hook for logs and errors
+func hookName(hook *config.Hook) string {
+	if hook.Name != "" {
+		return hook.Name
+	}
+	return strings.Join(hook.Command, " ")
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooks
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/cluster/fake"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/apis/config/encoding"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/actionstest"
+)
+
+// parseContext parses rawConfig and returns a test action context for it
+func parseContext(t *testing.T, rawConfig string) (*fake.Provider, *actions.ActionContext) {
+	cfg, err := encoding.Parse([]byte(rawConfig))
+	if err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+	return actionstest.NewContext(t, cfg)
+}
+
+// ranCommands returns the commands run on each node, by node name
+func ranCommands(p *fake.Provider) map[string][]string {
+	ran := map[string][]string{}
+	for _, n := range p.Nodes("kind") {
+		for _, c := range n.Commands() {
+			ran[n.String()] = append(ran[n.String()], c.String())
+		}
+	}
+	return ran
+}
+
+func TestNodeHooks(t *testing.T) {
+	t.Parallel()
+	p, ctx := parseContext(t, `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+nodes:
+- role: control-plane
+- role: control-plane
+- role: worker
+- role: worker
+hooks:
+- when: AfterJoin
+  command: ["all"]
+- when: AfterJoin
+  roles: [worker]
+  nodes: [kind-control-plane2]
+  command: ["some", "args"]
+- when: AfterReady
+  command: ["later"]
+`)
+	assert.ExpectError(t, false, NewAction(config.AfterJoin).Execute(ctx))
+	assert.DeepEqual(t, map[string][]string{
+		"kind-control-plane":  {"all"},
+		"kind-control-plane2": {"all", "some args"},
+		"kind-worker":         {"all", "some args"},
+		"kind-worker2":        {"all", "some args"},
+	}, ranCommands(p))
+}
+
+func TestNodeHookFailure(t *testing.T) {
+	t.Parallel()
+	p, ctx := parseContext(t, `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+nodes:
+- role: control-plane
+- role: worker
+hooks:
+- name: fails
+  when: AfterProvision
+  roles: [worker]
+  command: ["false"]
+- when: AfterProvision
+  command: ["never"]
+`)
+	for _, n := range p.Nodes("kind") {
+		n.Script("false", "oops", errors.New("exit status 1"))
+	}
+	err := NewAction(config.AfterProvision).Execute(ctx)
+	assert.ExpectError(t, true, err)
+	if !strings.Contains(err.Error(), "failed to run hook fails on kind-worker, output:\noops") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	assert.DeepEqual(t, map[string][]string{
+		"kind-worker": {"false"},
+	}, ranCommands(p))
+}
+
+func TestUnknownNode(t *testing.T) {
+	t.Parallel()
+	p, ctx := parseContext(t, `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+hooks:
+- when: AfterKubeadmInit
+  nodes: [kind-worker]
+  command: ["true"]
+`)
+	assert.ExpectError(t, true, NewAction(config.AfterKubeadmInit).Execute(ctx))
+	assert.DeepEqual(t, map[string][]string{}, ranCommands(p))
+}
+
+func TestHostHooks(t *testing.T) {
+	t.Parallel()
+	p, ctx := parseContext(t, `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+hooks:
+- when: AfterReady
+  onHost: true
+  command: ["kubectl", "apply", "-f", "app.yaml"]
+`)
+	host := fake.NewNode("host", "", "", "")
+	a := &action{point: config.AfterReady, host: host}
+	assert.ExpectError(t, false, a.Execute(ctx))
+	assert.DeepEqual(t, map[string][]string{}, ranCommands(p))
+	commands := host.Commands()
+	assert.DeepEqual(t, 1, len(commands))
+	assert.StringEqual(t, "kubectl apply -f app.yaml", commands[0].String())
+	env := commands[0].Env
+	assert.StringEqual(t, "KIND_CLUSTER_NAME=kind", env[len(env)-1])
+}
@@ -23,6 +23,7 @@ import (
 	"strings"
 
 	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
 
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
@@ -54,7 +55,7 @@ func (a *action) Execute(ctx *actions.ActionContext) error {
 
 	// read the manifest from the node
 	var raw bytes.Buffer
-	if err := node.Command("cat", "/kind/manifests/default-cni.yaml").SetStdout(&raw).Run(); err != nil {
+	if err := exec.CommandContextFor(ctx.Context(), node, "cat", "/kind/manifests/default-cni.yaml").SetStdout(&raw).Run(); err != nil {
 		return errors.Wrap(err, "failed to read CNI manifest")
 	}
 	manifest := raw.String()
@@ -84,8 +85,8 @@ func (a *action) Execute(ctx *actions.ActionContext) error {
 	}
 
 	// install the manifest
-	if err := node.Command(
-		"kubectl", "create", "--kubeconfig=/etc/kubernetes/admin.conf",
+	if err := exec.CommandContextFor(ctx.Context(), node,
+		"kubectl", "apply", "--kubeconfig=/etc/kubernetes/admin.conf",
 		"-f", "-",
 	).SetStdin(strings.NewReader(manifest)).Run(); err != nil {
 		return errors.Wrap(err, "failed to apply overlay network")
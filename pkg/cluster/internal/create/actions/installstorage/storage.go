@@ -14,15 +14,21 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// Package installstorage implements the an action to isntall a default
-// storageclass
+// Package installstorage implements the an action to install a default
+// dynamic storage provisioner and storageclass
 package installstorage
 
 import (
+	"bytes"
+	"context"
 	"strings"
+	"text/template"
 
+	"sigs.k8s.io/kind/pkg/cluster/constants"
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
 	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
 
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
@@ -52,8 +58,12 @@ func (a *action) Execute(ctx *actions.ActionContext) error {
 	}
 	node := controlPlanes[0] // kind expects at least one always
 
-	// add the default storage class
-	if err := addDefaultStorageClass(node); err != nil {
+	// add the provisioner and the default storage class
+	manifest, err := storageManifest(&ctx.Config.Storage)
+	if err != nil {
+		return err
+	}
+	if err := addDefaultStorage(ctx.Context(), node, manifest); err != nil {
 		return errors.Wrap(err, "failed to add default storage class")
 	}
 
@@ -62,21 +72,147 @@ func (a *action) Execute(ctx *actions.ActionContext) error {
 	return nil
 }
 
-// a default storage class
+// a dynamic provisioner creating hostPath volumes under /var/local-path on
+// the node the volume is used on, and the default storage class using it
 // we need this for e2es (StatefulSet)
-const defaultStorageClassManifest = `# host-path based default storage class
+const defaultStorageManifest = `# local-path based default storage class
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: local-path-storage
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: local-path-provisioner-service-account
+  namespace: local-path-storage
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: local-path-provisioner-role
+rules:
+- apiGroups: [""]
+  resources: ["nodes", "persistentvolumeclaims"]
+  verbs: ["get", "list", "watch"]
+- apiGroups: [""]
+  resources: ["endpoints", "persistentvolumes", "pods"]
+  verbs: ["*"]
+- apiGroups: [""]
+  resources: ["events"]
+  verbs: ["create", "patch"]
+- apiGroups: ["storage.k8s.io"]
+  resources: ["storageclasses"]
+  verbs: ["get", "list", "watch"]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: local-path-provisioner-bind
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: local-path-provisioner-role
+subjects:
+- kind: ServiceAccount
+  name: local-path-provisioner-service-account
+  namespace: local-path-storage
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: local-path-provisioner
+  namespace: local-path-storage
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app: local-path-provisioner
+  template:
+    metadata:
+      labels:
+        app: local-path-provisioner
+    spec:
+      serviceAccountName: local-path-provisioner-service-account
+      tolerations:
+      - key: node-role.kubernetes.io/master
+        operator: Equal
+        effect: NoSchedule
+      containers:
+      - name: local-path-provisioner
+        image: {{ .Image }}
+        imagePullPolicy: IfNotPresent
+        command:
+        - local-path-provisioner
+        - --debug
+        - start
+        - --config
+        - /etc/config/config.json
+        volumeMounts:
+        - name: config-volume
+          mountPath: /etc/config/
+        env:
+        - name: POD_NAMESPACE
+          valueFrom:
+            fieldRef:
+              fieldPath: metadata.namespace
+      volumes:
+      - name: config-volume
+        configMap:
+          name: local-path-config
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: local-path-config
+  namespace: local-path-storage
+data:
+  config.json: |-
+    {
+      "nodePathMap": [
+        {
+          "node": "DEFAULT_PATH_FOR_NON_LISTED_NODES",
+          "paths": ["/var/local-path"]
+        }
+      ]
+    }
+---
 apiVersion: storage.k8s.io/v1
 kind: StorageClass
 metadata:
-  namespace: kube-system
   name: standard
   annotations:
     storageclass.kubernetes.io/is-default-class: "true"
-provisioner: kubernetes.io/host-path`
+provisioner: rancher.io/local-path
+volumeBindingMode: {{ .VolumeBindingMode }}
+reclaimPolicy: {{ .ReclaimPolicy }}
+`
+
+// storageManifest returns the default storage manifest for storage
+func storageManifest(storage *config.Storage) (string, error) {
+	t, err := template.New("storage-manifest").Parse(defaultStorageManifest)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse storage manifest template")
+	}
+	var out bytes.Buffer
+	err = t.Execute(&out, &struct {
+		Image             string
+		VolumeBindingMode config.VolumeBindingMode
+		ReclaimPolicy     config.ReclaimPolicy
+	}{
+		Image:             constants.LocalPathProvisionerImage,
+		VolumeBindingMode: storage.VolumeBindingMode,
+		ReclaimPolicy:     storage.ReclaimPolicy,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to execute storage manifest template")
+	}
+	return out.String(), nil
+}
 
-func addDefaultStorageClass(controlPlane nodes.Node) error {
-	in := strings.NewReader(defaultStorageClassManifest)
-	cmd := controlPlane.Command(
+func addDefaultStorage(ctx context.Context, controlPlane nodes.Node, manifest string) error {
+	in := strings.NewReader(manifest)
+	cmd := exec.CommandContextFor(ctx, controlPlane,
 		"kubectl",
 		"--kubeconfig=/etc/kubernetes/admin.conf", "apply", "-f", "-",
 	)
@@ -0,0 +1,77 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package installstorage
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestStorageManifest(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name     string
+		Storage  config.Storage
+		Expected []string
+	}{
+		{
+			Name: "defaults",
+			Storage: func() config.Storage {
+				c := config.Cluster{}
+				config.SetDefaultsCluster(&c)
+				return c.Storage
+			}(),
+			Expected: []string{
+				"volumeBindingMode: WaitForFirstConsumer\n",
+				"reclaimPolicy: Delete\n",
+			},
+		},
+		{
+			Name: "retained immediate",
+			Storage: config.Storage{
+				VolumeBindingMode: config.VolumeBindingImmediate,
+				ReclaimPolicy:     config.ReclaimRetain,
+			},
+			Expected: []string{
+				"volumeBindingMode: Immediate\n",
+				"reclaimPolicy: Retain\n",
+			},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			manifest, err := storageManifest(&tc.Storage)
+			assert.ExpectError(t, false, err)
+			expected := append([]string{
+				"image: " + constants.LocalPathProvisionerImage + "\n",
+				"provisioner: rancher.io/local-path\n",
+				`"paths": ["/var/local-path"]`,
+			}, tc.Expected...)
+			for _, e := range expected {
+				if !strings.Contains(manifest, e) {
+					t.Errorf("expected manifest to contain %q", e)
+				}
+			}
+		})
+	}
+}
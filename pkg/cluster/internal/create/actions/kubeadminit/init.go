@@ -26,6 +26,7 @@ import (
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
 
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/kubeadm"
 )
 
 // kubeadmInitAction implements action for executing the kubadm init
@@ -57,14 +58,14 @@ func (a *action) Execute(ctx *actions.ActionContext) error {
 	}
 
 	// run kubeadm
-	cmd := node.Command(
+	cmd := exec.CommandContextFor(ctx.Context(), node,
 		// init because this is the control plane node
 		"kubeadm", "init",
 		// preflight errors are expected, in particular for swap being enabled
 		// TODO(bentheelder): limit the set of acceptable errors
 		"--ignore-preflight-errors=all",
 		// specify our generated config file
-		"--config=/kind/kubeadm.conf",
+		"--config="+kubeadm.ConfigPath,
 		"--skip-token-print",
 		// increase verbosity for debugging
 		"--v=6",
@@ -101,7 +102,7 @@ func (a *action) Execute(ctx *actions.ActionContext) error {
 	// if we are only provisioning one node, remove the master taint
 	// https://kubernetes.io/docs/setup/independent/create-cluster-kubeadm/#master-isolation
 	if len(allNodes) == 1 {
-		if err := node.Command(
+		if err := exec.CommandContextFor(ctx.Context(), node,
 			"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf",
 			"taint", "nodes", "--all", "node-role.kubernetes.io/master-",
 		).Run(); err != nil {
@@ -18,7 +18,9 @@ limitations under the License.
 package kubeadmjoin
 
 import (
+	"context"
 	"strings"
+	"time"
 
 	"sigs.k8s.io/kind/pkg/cluster/constants"
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
@@ -29,17 +31,51 @@ import (
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
 
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/kubeadm"
 )
 
+// actionName is the name cluster creation records the action under, it is
+// also recorded on each node as it joins so that resuming skips them
+const actionName = "kubeadmjoin"
+
+// joinTimeout bounds joining a single node, so that a hung kubeadm join
+// fails cluster creation rather than blocking it forever
+const joinTimeout = 5 * time.Minute
+
 // Action implements action for creating the kubeadm join
 // and deployng it on the bootrap control-plane node.
-type Action struct{}
+type Action struct {
+	// nodeName limits the action to joining the named node, if set
+	nodeName string
+}
 
 // NewAction returns a new action for creating the kubeadm jion
 func NewAction() actions.Action {
 	return &Action{}
 }
 
+// NewNodeAction returns a new action joining the node name to an existing
+// cluster
+func NewNodeAction(name string) actions.Action {
+	return &Action{
+		nodeName: name,
+	}
+}
+
+// selectNodes returns the nodes the action applies to
+func (a *Action) selectNodes(allNodes []nodes.Node) []nodes.Node {
+	if a.nodeName == "" {
+		return allNodes
+	}
+	selected := []nodes.Node{}
+	for _, node := range allNodes {
+		if node.String() == a.nodeName {
+			selected = append(selected, node)
+		}
+	}
+	return selected
+}
+
 // Execute runs the action
 func (a *Action) Execute(ctx *actions.ActionContext) error {
 	allNodes, err := ctx.Nodes()
@@ -52,6 +88,10 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 	if err != nil {
 		return err
 	}
+	secondaryControlPlanes, err = notJoined(ctx.Context(), a.selectNodes(secondaryControlPlanes))
+	if err != nil {
+		return err
+	}
 	if len(secondaryControlPlanes) > 0 {
 		if err := joinSecondaryControlPlanes(ctx, secondaryControlPlanes); err != nil {
 			return err
@@ -63,6 +103,10 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 	if err != nil {
 		return err
 	}
+	workers, err = notJoined(ctx.Context(), a.selectNodes(workers))
+	if err != nil {
+		return err
+	}
 	if len(workers) > 0 {
 		if err := joinWorkers(ctx, workers); err != nil {
 			return err
@@ -72,6 +116,21 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 	return nil
 }
 
+// notJoined returns the nodes that have not already joined the cluster
+func notJoined(ctx context.Context, allNodes []nodes.Node) ([]nodes.Node, error) {
+	selected := []nodes.Node{}
+	for _, node := range allNodes {
+		joined, err := actions.HasCompletedAction(ctx, node, actionName)
+		if err != nil {
+			return nil, err
+		}
+		if !joined {
+			selected = append(selected, node)
+		}
+	}
+	return selected, nil
+}
+
 func joinSecondaryControlPlanes(
 	ctx *actions.ActionContext,
 	secondaryControlPlanes []nodes.Node,
@@ -83,7 +142,7 @@ func joinSecondaryControlPlanes(
 	// (this is not safe currently)
 	for _, node := range secondaryControlPlanes {
 		node := node // capture loop variable
-		if err := runKubeadmJoin(ctx.Logger, node); err != nil {
+		if err := runKubeadmJoin(ctx.Context(), ctx.Logger, node); err != nil {
 			return err
 		}
 	}
@@ -100,14 +159,14 @@ func joinWorkers(
 	defer ctx.Status.End(false)
 
 	// create the workers concurrently
-	fns := []func() error{}
+	fns := []func(context.Context) error{}
 	for _, node := range workers {
 		node := node // capture loop variable
-		fns = append(fns, func() error {
-			return runKubeadmJoin(ctx.Logger, node)
+		fns = append(fns, func(joinCtx context.Context) error {
+			return runKubeadmJoin(joinCtx, ctx.Logger, node)
 		})
 	}
-	if err := errors.UntilErrorConcurrent(fns); err != nil {
+	if err := errors.UntilErrorConcurrentContext(ctx.Context(), fns); err != nil {
 		return err
 	}
 
@@ -115,14 +174,18 @@ func joinWorkers(
 	return nil
 }
 
-// runKubeadmJoin executes kubadm join command
-func runKubeadmJoin(logger log.Logger, node nodes.Node) error {
+// runKubeadmJoin executes kubadm join command, killing it once ctx is done
+// or it took longer than joinTimeout
+func runKubeadmJoin(ctx context.Context, logger log.Logger, node nodes.Node) error {
+	ctx, cancel := context.WithTimeout(ctx, joinTimeout)
+	defer cancel()
+
 	// run kubeadm join
 	// TODO(bentheelder): this should be using the config file
-	cmd := node.Command(
+	cmd := exec.CommandContextFor(ctx, node,
 		"kubeadm", "join",
 		// the join command uses the config file generated in a well known location
-		"--config", "/kind/kubeadm.conf",
+		"--config", kubeadm.ConfigPath,
 		// preflight errors are expected, in particular for swap being enabled
 		// TODO(bentheelder): limit the set of acceptable errors
 		"--ignore-preflight-errors=all",
@@ -131,9 +194,12 @@ func runKubeadmJoin(logger log.Logger, node nodes.Node) error {
 	)
 	lines, err := exec.CombinedOutputLines(cmd)
 	logger.V(3).Info(strings.Join(lines, "\n"))
+	if ctx.Err() == context.DeadlineExceeded {
+		return errors.Errorf("timed out after %s joining node %s with kubeadm", joinTimeout, node)
+	}
 	if err != nil {
 		return errors.Wrap(err, "failed to join node with kubeadm")
 	}
 
-	return nil
+	return actions.RecordCompletedAction(ctx, node, actionName)
 }
@@ -0,0 +1,99 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package actions
+
+import (
+	"context"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// StatePath is the file on nodes recording the actions completed on them,
+// one name per line, so that creating a cluster can be resumed
+const StatePath = "/kind/state"
+
+// CompletedActions returns the names of the actions recorded as completed
+// on node, nodes without a state file have not completed any
+func CompletedActions(ctx context.Context, node nodes.Node) ([]string, error) {
+	lines, err := exec.OutputLines(exec.CommandContextFor(ctx, node, "cat", StatePath))
+	if err != nil {
+		// only a missing state file means no action completed
+		exists := exec.CommandContextFor(ctx, node, "test", "-e", StatePath).Run()
+		if exitedNonZero(exists) {
+			return nil, nil
+		}
+		if exists != nil {
+			return nil, errors.Wrapf(exists, "failed to check the state of node %s", node)
+		}
+		return nil, errors.Wrapf(err, "failed to read the state of node %s", node)
+	}
+	completed := []string{}
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			completed = append(completed, line)
+		}
+	}
+	return completed, nil
+}
+
+// exitedNonZero returns true if err is a command exiting with a non-zero
+// code, rather than failing to run at all
+func exitedNonZero(err error) bool {
+	runErr := exec.RunErrorForError(err)
+	if runErr == nil {
+		return false
+	}
+	exitErr, ok := runErr.Inner.(interface{ ExitCode() int })
+	return ok && exitErr.ExitCode() > 0
+}
+
+// RecordCompletedAction records the action name as completed on node
+func RecordCompletedAction(ctx context.Context, node nodes.Node, name string) error {
+	completed, err := CompletedActions(ctx, node)
+	if err != nil {
+		return err
+	}
+	for _, c := range completed {
+		if c == name {
+			return nil
+		}
+	}
+	completed = append(completed, name)
+	if err := nodeutils.WriteFileContext(ctx, node, StatePath, strings.Join(completed, "\n")+"\n"); err != nil {
+		return errors.Wrapf(err, "failed to record the state of node %s", node)
+	}
+	return nil
+}
+
+// HasCompletedAction returns true if the action name is recorded as
+// completed on node
+func HasCompletedAction(ctx context.Context, node nodes.Node, name string) (bool, error) {
+	completed, err := CompletedActions(ctx, node)
+	if err != nil {
+		return false, err
+	}
+	for _, c := range completed {
+		if c == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package actions
+
+import (
+	"context"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/cluster/fake"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestCompletedActions(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name        string
+		Setup       func(n *fake.Node)
+		Expected    []string
+		ExpectError bool
+	}{
+		{
+			Name:  "no state file",
+			Setup: func(n *fake.Node) {},
+		},
+		{
+			Name: "state file",
+			Setup: func(n *fake.Node) {
+				n.SetFile(StatePath, "config\nkubeadminit\n")
+			},
+			Expected: []string{"config", "kubeadminit"},
+		},
+		{
+			Name: "unreadable state file",
+			Setup: func(n *fake.Node) {
+				n.SetFile(StatePath, "config\n")
+				n.Script("cat "+StatePath, "", &fake.ExitError{Code: 1})
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "unreachable node",
+			Setup: func(n *fake.Node) {
+				n.Script("", "", errors.New("connection refused"))
+			},
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			n := fake.NewNode("kind-control-plane", "control-plane", "172.17.0.2", "fc00::2")
+			tc.Setup(n)
+			completed, err := CompletedActions(context.Background(), n)
+			assert.ExpectError(t, tc.ExpectError, err)
+			assert.DeepEqual(t, tc.Expected, completed)
+		})
+	}
+}
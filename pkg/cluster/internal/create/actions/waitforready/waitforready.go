@@ -19,24 +19,23 @@ package waitforready
 
 import (
 	"fmt"
-	"strings"
 	"time"
 
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
-	"sigs.k8s.io/kind/pkg/cluster/nodes"
-	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
-	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/cluster/internal/readiness"
 )
 
 // Action implements an action for waiting for the cluster to be ready
 type Action struct {
 	waitTime time.Duration
+	gates    []readiness.Gate
 }
 
-// NewAction returns a new action for waiting for the cluster to be ready
-func NewAction(waitTime time.Duration) actions.Action {
+// NewAction returns a new action for waiting for the cluster to pass gates
+func NewAction(waitTime time.Duration, gates []readiness.Gate) actions.Action {
 	return &Action{
 		waitTime: waitTime,
+		gates:    gates,
 	}
 }
 
@@ -48,28 +47,26 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 	}
 	ctx.Status.Start(
 		fmt.Sprintf(
-			"Waiting ≤ %s for control-plane = Ready ⏳",
+			"Waiting ≤ %s for cluster = Ready ⏳",
 			formatDuration(a.waitTime),
 		),
 	)
 
-	allNodes, err := ctx.Nodes()
+	allNodes, err := ctx.ClusterContext.ListInternalNodes()
 	if err != nil {
 		return err
 	}
-	// get a control plane node to use to check cluster status
-	controlPlanes, err := nodeutils.ControlPlaneNodes(allNodes)
-	if err != nil {
-		return err
-	}
-	node := controlPlanes[0] // kind expects at least one always
 
-	// Wait for the nodes to reach Ready status.
+	// Wait for the cluster to pass the readiness gates.
 	startTime := time.Now()
-	isReady := waitForReady(node, startTime.Add(a.waitTime))
-	if !isReady {
+	if err := readiness.Wait(ctx.Context(), allNodes, a.gates, a.waitTime); err != nil {
 		ctx.Status.End(false)
+		// only timing out is not fatal, cancelling creation is
+		if ctx.Context().Err() != nil {
+			return err
+		}
 		fmt.Println(" • WARNING: Timed out waiting for Ready ⚠️")
+		ctx.Logger.Warnf("%v", err)
 		return nil
 	}
 
@@ -79,51 +76,6 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 	return nil
 }
 
-// WaitForReady uses kubectl inside the "node" container to check if the
-// control plane nodes are "Ready".
-func waitForReady(node nodes.Node, until time.Time) bool {
-	return tryUntil(until, func() bool {
-		cmd := node.Command(
-			"kubectl",
-			"--kubeconfig=/etc/kubernetes/admin.conf",
-			"get",
-			"nodes",
-			"--selector=node-role.kubernetes.io/master",
-			// When the node reaches status ready, the status field will be set
-			// to true.
-			"-o=jsonpath='{.items..status.conditions[-1:].status}'",
-		)
-		lines, err := exec.CombinedOutputLines(cmd)
-		if err != nil {
-			return false
-		}
-
-		// 'lines' will return the status of all nodes labeled as master. For
-		// example, if we have three control plane nodes, and all are ready,
-		// then the status will have the following format: `True True True'.
-		status := strings.Fields(lines[0])
-		for _, s := range status {
-			// Check node status. If node is ready then this will be 'True',
-			// 'False' or 'Unkown' otherwise.
-			if !strings.Contains(s, "True") {
-				return false
-			}
-		}
-		return true
-	})
-}
-
-// helper that calls `try()`` in a loop until the deadline `until`
-// has passed or `try()`returns true, returns whether try ever returned true
-func tryUntil(until time.Time, try func() bool) bool {
-	for until.After(time.Now()) {
-		if try() {
-			return true
-		}
-	}
-	return false
-}
-
 func formatDuration(duration time.Duration) string {
 	return duration.Round(time.Second).String()
 }
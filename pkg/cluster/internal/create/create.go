@@ -17,15 +17,19 @@ limitations under the License.
 package create
 
 import (
+	stdcontext "context"
 	"fmt"
+	"io"
 	"math/rand"
 	"regexp"
 	"time"
 
 	"github.com/alessio/shellescape"
 
+	"sigs.k8s.io/kind/pkg/cluster/events"
 	"sigs.k8s.io/kind/pkg/cluster/internal/context"
 	"sigs.k8s.io/kind/pkg/cluster/internal/delete"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/internal/apis/config"
 	"sigs.k8s.io/kind/pkg/internal/apis/config/encoding"
@@ -33,7 +37,9 @@ import (
 	"sigs.k8s.io/kind/pkg/log"
 
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/addons"
 	configaction "sigs.k8s.io/kind/pkg/cluster/internal/create/actions/config"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/hooks"
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/installcni"
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/installstorage"
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/kubeadminit"
@@ -41,6 +47,7 @@ import (
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/loadbalancer"
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/waitforready"
 	"sigs.k8s.io/kind/pkg/cluster/internal/kubeconfig"
+	"sigs.k8s.io/kind/pkg/cluster/internal/readiness"
 )
 
 const (
@@ -51,7 +58,7 @@ const (
 
 // similar to valid docker container names, but since we will prefix
 // and suffix this name, we can relax it a little
-// see NewContext() for usage
+// see Cluster() for usage
 // https://godoc.org/github.com/docker/docker/daemon/names#pkg-constants
 var validNameRE = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
 
@@ -63,11 +70,22 @@ type ClusterOptions struct {
 	Retain         bool
 	WaitForReady   time.Duration
 	KubeconfigPath string
+	// ReadinessGates are the gates waited for with WaitForReady, if nil
+	// all of the gates applying to Config are used
+	ReadinessGates []readiness.Gate
+	// Resume continues creating the existing nodes of a cluster whose
+	// creation failed, skipping the steps already completed on them
+	Resume bool
 	// see https://github.com/kubernetes-sigs/kind/issues/324
 	StopBeforeSettingUpKubernetes bool // if false kind should setup kubernetes after creating nodes
 	// Options to control output
 	DisplayUsage      bool
 	DisplaySalutation bool
+	// EventHandlers are called with an event for each phase of creation
+	EventHandlers []events.Handler
+	// DryRun, if set, receives the defaulted config, the rendered node
+	// config files and the provider objects instead of creating the cluster
+	DryRun io.Writer
 }
 
 // Cluster creates a cluster
@@ -94,49 +112,104 @@ func Cluster(logger log.Logger, ctx *context.Context, opts *ClusterOptions) erro
 		return err
 	}
 
+	// only render what would be created
+	if opts.DryRun != nil {
+		return dryRun(logger, ctx, opts)
+	}
+
 	// setup a status object to show progress to the user
 	status := cli.StatusForLogger(logger)
 
-	// Create node containers implementing defined config Nodes
-	if err := ctx.Provider().Provision(status, ctx.Name(), opts.Config); err != nil {
+	// the nodes of a resumed cluster are kept on failure, so that creating
+	// it can be resumed again
+	retain := opts.Retain || opts.Resume
+
+	// Create node containers implementing defined config Nodes, unless
+	// resuming the creation of existing nodes
+	completed := map[string]bool{}
+	if opts.Resume {
+		var err error
+		if completed, err = completedSteps(ctx); err != nil {
+			return err
+		}
+	} else if err := events.Run(opts.EventHandlers, "provision", func() error {
+		// providers emit the phases of provisioning, such as pulling images
+		provisionCtx := events.NewContext(ctx.Context(), "provision", opts.EventHandlers)
+		return ctx.Provider().Provision(provisionCtx, status, ctx.Name(), opts.Config)
+	}); err != nil {
 		// In case of errors nodes are deleted (except if retain is explicitly set)
 		logger.Errorf("%v", err)
-		if !opts.Retain {
-			_ = delete.Cluster(logger, ctx, opts.KubeconfigPath)
-		}
+		cleanup(logger, ctx, opts.KubeconfigPath, retain)
 		return err
 	}
 
 	// TODO(bentheelder): make this controllable from the command line?
-	actionsToRun := []actions.Action{
-		loadbalancer.NewAction(), // setup external loadbalancer
-		configaction.NewAction(), // setup kubeadm config
-	}
+	stepsToRun := hookSteps(opts.Config, config.AfterProvision)
+	stepsToRun = append(stepsToRun,
+		step{"loadbalancer", loadbalancer.NewAction()}, // setup external loadbalancer
+		step{"config", configaction.NewAction()},       // setup kubeadm config
+	)
 	if !opts.StopBeforeSettingUpKubernetes {
-		actionsToRun = append(actionsToRun,
-			kubeadminit.NewAction(), // run kubeadm init
+		stepsToRun = append(stepsToRun,
+			step{"kubeadminit", kubeadminit.NewAction()}, // run kubeadm init
 		)
+		stepsToRun = append(stepsToRun, hookSteps(opts.Config, config.AfterKubeadmInit)...)
 		// this step might be skipped, but is next after init
 		if !opts.Config.Networking.DisableDefaultCNI {
-			actionsToRun = append(actionsToRun,
-				installcni.NewAction(), // install CNI
+			stepsToRun = append(stepsToRun,
+				step{"installcni", installcni.NewAction()}, // install CNI
+			)
+		}
+		// this step might be skipped too
+		if !opts.Config.Storage.DisableDefaultStorageClass {
+			stepsToRun = append(stepsToRun,
+				step{"installstorage", installstorage.NewAction()}, // install StorageClass
 			)
 		}
 		// add remaining steps
-		actionsToRun = append(actionsToRun,
-			installstorage.NewAction(),                // install StorageClass
-			kubeadmjoin.NewAction(),                   // run kubeadm join
-			waitforready.NewAction(opts.WaitForReady), // wait for cluster readiness
+		stepsToRun = append(stepsToRun,
+			step{"kubeadmjoin", kubeadmjoin.NewAction()}, // run kubeadm join
 		)
+		stepsToRun = append(stepsToRun, hookSteps(opts.Config, config.AfterJoin)...)
+		// addons may need every node and the default StorageClass
+		if len(opts.Config.Addons) > 0 {
+			stepsToRun = append(stepsToRun,
+				step{"addons", addons.NewAction()}, // apply addon manifests
+			)
+		}
+		stepsToRun = append(stepsToRun,
+			step{"waitforready", waitforready.NewAction(opts.WaitForReady, opts.ReadinessGates)}, // wait for cluster readiness
+		)
+		stepsToRun = append(stepsToRun, hookSteps(opts.Config, config.AfterReady)...)
 	}
 
-	// run all actions
+	// run all actions, recording each on the nodes once it completed
+	internalNodes, err := ctx.ListInternalNodes()
+	if err != nil {
+		cleanup(logger, ctx, opts.KubeconfigPath, retain)
+		return err
+	}
 	actionsContext := actions.NewActionContext(logger, opts.Config, ctx, status)
-	for _, action := range actionsToRun {
-		if err := action.Execute(actionsContext); err != nil {
-			if !opts.Retain {
-				_ = delete.Cluster(logger, ctx, opts.KubeconfigPath)
+	for _, s := range stepsToRun {
+		// stop between steps once creation is cancelled
+		if err := ctx.Context().Err(); err != nil {
+			cleanup(logger, ctx, opts.KubeconfigPath, retain)
+			return err
+		}
+		if completed[s.name] {
+			logger.V(1).Infof("Skipping completed step %s", s.name)
+			now := time.Now()
+			events.Emit(opts.EventHandlers, events.Event{Name: s.name, Start: now, End: now, Result: events.Skipped})
+			continue
+		}
+		err := events.Run(opts.EventHandlers, s.name, func() error {
+			if err := s.action.Execute(actionsContext); err != nil {
+				return err
 			}
+			return recordStep(ctx.Context(), internalNodes, s.name)
+		})
+		if err != nil {
+			cleanup(logger, ctx, opts.KubeconfigPath, retain)
 			return err
 		}
 	}
@@ -146,7 +219,9 @@ func Cluster(logger log.Logger, ctx *context.Context, opts *ClusterOptions) erro
 		return nil
 	}
 
-	if err := kubeconfig.Export(ctx, opts.KubeconfigPath); err != nil {
+	if err := events.Run(opts.EventHandlers, "kubeconfig", func() error {
+		return kubeconfig.Export(ctx, opts.KubeconfigPath)
+	}); err != nil {
 		return err
 	}
 
@@ -162,6 +237,75 @@ func Cluster(logger log.Logger, ctx *context.Context, opts *ClusterOptions) erro
 	return nil
 }
 
+// cleanup deletes the cluster after creating it failed, unless retain is set
+// Creation may have failed because ctx was cancelled, E.G. on Ctrl-C, so the
+// cluster is deleted with a context that is not
+func cleanup(logger log.Logger, ctx *context.Context, explicitKubeconfigPath string, retain bool) {
+	if err := ctx.Context().Err(); err != nil {
+		logger.Warnf("Creating cluster %q was interrupted: %v", ctx.Name(), err)
+	}
+	if retain {
+		return
+	}
+	_ = delete.Cluster(logger, ctx.WithContext(stdcontext.Background()), explicitKubeconfigPath)
+}
+
+// hookSteps returns the step running the hooks in cfg for point, if any
+func hookSteps(cfg *config.Cluster, point config.HookPoint) []step {
+	if len(hooks.ForPoint(cfg, point)) == 0 {
+		return []step{}
+	}
+	return []step{{"hooks/" + string(point), hooks.NewAction(point)}}
+}
+
+// step is an action of cluster creation, which is recorded on the nodes by
+// name once it completed so that creation can be resumed
+type step struct {
+	name   string
+	action actions.Action
+}
+
+// completedSteps returns the names of the steps recorded as completed on
+// every node of the existing cluster
+func completedSteps(ctx *context.Context) (map[string]bool, error) {
+	internalNodes, err := ctx.ListInternalNodes()
+	if err != nil {
+		return nil, err
+	}
+	if len(internalNodes) == 0 {
+		return nil, errors.Errorf("no nodes found for cluster %q, it cannot be resumed", ctx.Name())
+	}
+	counts := map[string]int{}
+	for _, node := range internalNodes {
+		names, err := actions.CompletedActions(ctx.Context(), node)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			counts[name]++
+		}
+	}
+	completed := map[string]bool{}
+	for name, count := range counts {
+		if count == len(internalNodes) {
+			completed[name] = true
+		}
+	}
+	return completed, nil
+}
+
+// recordStep records the step name as completed on all of the nodes
+func recordStep(ctx stdcontext.Context, allNodes []nodes.Node, name string) error {
+	fns := []func(stdcontext.Context) error{}
+	for _, node := range allNodes {
+		node := node // capture loop variable
+		fns = append(fns, func(ctx stdcontext.Context) error {
+			return actions.RecordCompletedAction(ctx, node, name)
+		})
+	}
+	return errors.UntilErrorConcurrentContext(ctx, fns)
+}
+
 func logUsage(logger log.Logger, ctx *context.Context, explicitKubeconfigPath string) {
 	// construct a sample command for interacting with the cluster
 	kctx := kubeconfig.ContextForCluster(ctx.Name())
@@ -211,5 +355,10 @@ func fixupOptions(opts *ClusterOptions) error {
 	// may be constructed in memory rather than from disk)
 	config.SetDefaultsCluster(opts.Config)
 
+	// default to all of the readiness gates that can pass
+	if opts.ReadinessGates == nil {
+		opts.ReadinessGates = readiness.DefaultGates(opts.Config)
+	}
+
 	return nil
 }
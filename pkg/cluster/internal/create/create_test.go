@@ -0,0 +1,482 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	stdcontext "context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/cluster/events"
+	"sigs.k8s.io/kind/pkg/cluster/fake"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/apis/config/encoding"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/context"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/loadbalancer"
+	"sigs.k8s.io/kind/pkg/cluster/internal/readiness"
+)
+
+const adminKubeconfig = `apiVersion: v1
+clusters:
+- cluster:
+    certificate-authority-data: definitelyacert
+    server: https://172.17.0.2:6443
+  name: kind
+contexts:
+- context:
+    cluster: kind
+    user: kubernetes-admin
+  name: kubernetes-admin@kind
+current-context: kubernetes-admin@kind
+kind: Config
+preferences: {}
+users:
+- name: kubernetes-admin
+  user:
+    client-certificate-data: seemslegit
+    client-key-data: yep
+`
+
+const cniManifest = "kind: DaemonSet\n"
+
+// setupNode seeds n with the files the create actions read from node images
+func setupNode(n *fake.Node) {
+	n.SetFile("/kind/version", "v1.17.0\n")
+	n.SetFile("/kind/manifests/default-cni.yaml", cniManifest)
+	n.SetFile("/etc/kubernetes/admin.conf", adminKubeconfig)
+	n.Script("cat /etc/kubernetes/pki/", "definitelyacert", nil)
+}
+
+// commandLines returns the command lines run on n
+func commandLines(n *fake.Node) []string {
+	lines := []string{}
+	for _, c := range n.Commands() {
+		lines = append(lines, c.String())
+	}
+	return lines
+}
+
+func newTestCluster(t *testing.T, rawConfig string) (*fake.Provider, *context.Context, *ClusterOptions, func()) {
+	dir, err := ioutil.TempDir("", "kind-create-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	// an empty config selects the defaults
+	cfg, err := encoding.Load("")
+	if rawConfig != "" {
+		cfg, err = encoding.Parse([]byte(rawConfig))
+	}
+	if err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+	p := fake.NewProvider()
+	p.Setup = setupNode
+	opts := &ClusterOptions{
+		Config:         cfg,
+		KubeconfigPath: filepath.Join(dir, "kubeconfig"),
+	}
+	return p, context.NewProviderContext(p, "kind"), opts, func() { os.RemoveAll(dir) }
+}
+
+func TestClusterSingleNode(t *testing.T) {
+	t.Parallel()
+	p, ctx, opts, cleanup := newTestCluster(t, "")
+	defer cleanup()
+	assert.ExpectError(t, false, Cluster(log.NoopLogger{}, ctx, opts))
+
+	nodes := p.Nodes("kind")
+	assert.DeepEqual(t, 1, len(nodes))
+	controlPlane := nodes[0]
+	assert.StringEqual(t, "kind-control-plane", controlPlane.String())
+
+	kubeadmConfig, ok := controlPlane.File("/kind/kubeadm.conf")
+	assert.DeepEqual(t, true, ok)
+	assert.DeepEqual(t, true, strings.Contains(kubeadmConfig, "kubernetesVersion: v1.17.0"))
+
+	_, ok = controlPlane.Ran("kubeadm init")
+	assert.DeepEqual(t, true, ok)
+	_, ok = controlPlane.Ran("kubeadm join")
+	assert.DeepEqual(t, false, ok)
+	// a single node cluster schedules workloads on the control plane
+	_, ok = controlPlane.Ran("kubectl --kubeconfig=/etc/kubernetes/admin.conf taint nodes --all")
+	assert.DeepEqual(t, true, ok)
+
+	cni, ok := controlPlane.Ran("kubectl apply --kubeconfig=/etc/kubernetes/admin.conf -f -")
+	assert.DeepEqual(t, true, ok)
+	assert.StringEqual(t, cniManifest, cni.Stdin)
+	storage, ok := controlPlane.Ran("kubectl --kubeconfig=/etc/kubernetes/admin.conf apply -f -")
+	assert.DeepEqual(t, true, ok)
+	assert.DeepEqual(t, true, strings.Contains(storage.Stdin, "kind: StorageClass"))
+
+	kubeconfig, err := ioutil.ReadFile(opts.KubeconfigPath)
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, true, strings.Contains(string(kubeconfig), "server: https://127.0.0.1:"))
+	assert.DeepEqual(t, true, strings.Contains(string(kubeconfig), "current-context: kind-kind"))
+}
+
+func TestClusterHA(t *testing.T) {
+	t.Parallel()
+	p, ctx, opts, cleanup := newTestCluster(t, `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+networking:
+  disableDefaultCNI: true
+nodes:
+- role: control-plane
+- role: control-plane
+- role: control-plane
+- role: worker
+`)
+	defer cleanup()
+	assert.ExpectError(t, false, Cluster(log.NoopLogger{}, ctx, opts))
+
+	byName := map[string]*fake.Node{}
+	for _, n := range p.Nodes("kind") {
+		byName[n.String()] = n
+	}
+	assert.DeepEqual(t, 5, len(byName))
+
+	// the load balancer balances across all control planes
+	lb := byName["kind-external-load-balancer"]
+	haproxy, ok := lb.File(loadbalancer.ConfigPath)
+	assert.DeepEqual(t, true, ok)
+	for _, name := range []string{"kind-control-plane", "kind-control-plane2", "kind-control-plane3"} {
+		assert.DeepEqual(t, true, strings.Contains(haproxy, name))
+	}
+	assert.DeepEqual(t, []string{
+		"mkdir -p " + filepath.Dir(loadbalancer.ConfigPath),
+		"cp /dev/stdin " + loadbalancer.ConfigPath,
+		"kill -s HUP 1",
+	}, commandLines(lb))
+
+	// only the bootstrap control plane runs init, the others join
+	_, ok = byName["kind-control-plane"].Ran("kubeadm init")
+	assert.DeepEqual(t, true, ok)
+	for _, name := range []string{"kind-control-plane2", "kind-control-plane3", "kind-worker"} {
+		_, ok = byName[name].Ran("kubeadm join")
+		assert.DeepEqual(t, true, ok)
+		_, ok = byName[name].Ran("kubeadm init")
+		assert.DeepEqual(t, false, ok)
+	}
+	// the shared certificates are copied to the other control planes
+	cert, _ := byName["kind-control-plane2"].File("/etc/kubernetes/pki/ca.key")
+	assert.StringEqual(t, "definitelyacert", cert)
+	_, ok = byName["kind-worker"].File("/etc/kubernetes/pki/ca.key")
+	assert.DeepEqual(t, false, ok)
+
+	// multi node clusters keep the taint, and the default CNI was disabled
+	_, ok = byName["kind-control-plane"].Ran("kubectl --kubeconfig=/etc/kubernetes/admin.conf taint")
+	assert.DeepEqual(t, false, ok)
+	_, ok = byName["kind-control-plane"].Ran("kubectl apply")
+	assert.DeepEqual(t, false, ok)
+}
+
+func TestClusterEvents(t *testing.T) {
+	t.Parallel()
+	p, ctx, opts, cleanup := newTestCluster(t, "")
+	defer cleanup()
+	p.Setup = func(n *fake.Node) {
+		setupNode(n)
+		n.Script("kubeadm init", "preflight failed", errors.New("exit status 1"))
+	}
+	summary := &events.Summary{}
+	opts.EventHandlers = []events.Handler{summary.Handle}
+	assert.ExpectError(t, true, Cluster(log.NoopLogger{}, ctx, opts))
+
+	results := []string{}
+	for _, e := range summary.Events() {
+		results = append(results, e.Name+" "+string(e.Result))
+		if e.End.Before(e.Start) {
+			t.Errorf("event %s ended before it started", e.Name)
+		}
+	}
+	assert.DeepEqual(t, []string{
+		"create-nodes success",
+		"provision success",
+		"loadbalancer success",
+		"config success",
+		"kubeadminit failure",
+	}, results)
+	if failed := summary.Events()[4]; !strings.Contains(failed.Error, "kubeadm") {
+		t.Errorf("expected the kubeadm init error, got %q", failed.Error)
+	}
+}
+
+func TestClusterHooks(t *testing.T) {
+	t.Parallel()
+	p, ctx, opts, cleanup := newTestCluster(t, `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+nodes:
+- role: control-plane
+- role: worker
+hooks:
+- when: AfterProvision
+  command: ["echo", "provisioned"]
+- when: AfterJoin
+  roles: [worker]
+  command: ["echo", "joined"]
+`)
+	defer cleanup()
+	summary := &events.Summary{}
+	opts.EventHandlers = []events.Handler{summary.Handle}
+	assert.ExpectError(t, false, Cluster(log.NoopLogger{}, ctx, opts))
+
+	names := []string{}
+	for _, e := range summary.Events() {
+		names = append(names, e.Name)
+	}
+	assert.DeepEqual(t, []string{
+		"create-nodes",
+		"provision",
+		"hooks/AfterProvision",
+		"loadbalancer",
+		"config",
+		"kubeadminit",
+		"installcni",
+		"installstorage",
+		"kubeadmjoin",
+		"hooks/AfterJoin",
+		"waitforready",
+		"kubeconfig",
+	}, names)
+	for _, n := range p.Nodes("kind") {
+		_, joined := n.Ran("echo joined")
+		assert.DeepEqual(t, n.String() == "kind-worker", joined)
+		_, provisioned := n.Ran("echo provisioned")
+		assert.DeepEqual(t, true, provisioned)
+	}
+}
+
+func TestClusterAddons(t *testing.T) {
+	t.Parallel()
+	p, ctx, opts, cleanup := newTestCluster(t, `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+nodes:
+- role: control-plane
+- role: worker
+`)
+	defer cleanup()
+	manifest := filepath.Join(filepath.Dir(opts.KubeconfigPath), "addon.yaml")
+	assert.ExpectError(t, false, ioutil.WriteFile(manifest, []byte("kind: Deployment\n"), 0644))
+	opts.Config.Addons = []config.Addon{{
+		Name:      "operator",
+		Manifests: []string{manifest},
+		WaitFor:   []config.AddonWorkload{{Kind: "Deployment", Namespace: "default", Name: "operator"}},
+	}}
+	assert.ExpectError(t, false, Cluster(log.NoopLogger{}, ctx, opts))
+
+	// addons are waited for once the workers can run them and claims bind
+	state, _ := p.Nodes("kind")[0].File(actions.StatePath)
+	assert.StringEqual(t, "loadbalancer\nconfig\nkubeadminit\ninstallcni\ninstallstorage\nkubeadmjoin\naddons\nwaitforready\n", state)
+}
+
+func TestClusterWithoutDefaultStorage(t *testing.T) {
+	t.Parallel()
+	p, ctx, opts, cleanup := newTestCluster(t, `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+storage:
+  disableDefaultStorageClass: true
+`)
+	defer cleanup()
+	assert.ExpectError(t, false, Cluster(log.NoopLogger{}, ctx, opts))
+	assert.DeepEqual(t, []readiness.Gate{
+		readiness.NodesReady,
+		readiness.SystemWorkloads,
+		readiness.DefaultServiceAccount,
+	}, opts.ReadinessGates)
+	node := p.Nodes("kind")[0]
+	state, _ := node.File(actions.StatePath)
+	assert.StringEqual(t, "loadbalancer\nconfig\nkubeadminit\ninstallcni\nkubeadmjoin\nwaitforready\n", state)
+	for _, c := range node.Commands() {
+		if strings.Contains(c.Stdin, "kind: StorageClass") {
+			t.Errorf("unexpected StorageClass applied by %q", c.String())
+		}
+	}
+}
+
+func TestClusterFailure(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name           string
+		Retain         bool
+		ExpectClusters []string
+	}{
+		{
+			Name:           "nodes are deleted",
+			ExpectClusters: []string{},
+		},
+		{
+			Name:           "nodes are retained",
+			Retain:         true,
+			ExpectClusters: []string{"kind"},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			p, ctx, opts, cleanup := newTestCluster(t, "")
+			defer cleanup()
+			p.Setup = func(n *fake.Node) {
+				setupNode(n)
+				n.Script("kubeadm init", "preflight failed", errors.New("exit status 1"))
+			}
+			opts.Retain = tc.Retain
+			assert.ExpectError(t, true, Cluster(log.NoopLogger{}, ctx, opts))
+			clusters, err := p.ListClusters()
+			assert.ExpectError(t, false, err)
+			assert.DeepEqual(t, tc.ExpectClusters, clusters)
+		})
+	}
+}
+
+func TestClusterInterrupted(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name           string
+		Retain         bool
+		ExpectClusters []string
+	}{
+		{
+			Name:           "nodes are deleted",
+			ExpectClusters: []string{},
+		},
+		{
+			Name:           "nodes are retained",
+			Retain:         true,
+			ExpectClusters: []string{"kind"},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			p, ctx, opts, cleanup := newTestCluster(t, `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+nodes:
+- role: control-plane
+- role: worker
+`)
+			defer cleanup()
+			// interrupt creation while the worker hangs joining
+			interrupt, cancel := stdcontext.WithCancel(stdcontext.Background())
+			defer cancel()
+			p.Setup = func(n *fake.Node) {
+				setupNode(n)
+				if n.String() == "kind-worker" {
+					blocked := n.Block("kubeadm join")
+					go func() {
+						<-blocked
+						cancel()
+					}()
+				}
+			}
+			opts.Retain = tc.Retain
+			err := Cluster(log.NoopLogger{}, ctx.WithContext(interrupt), opts)
+			assert.ExpectError(t, true, err)
+			clusters, err := p.ListClusters()
+			assert.ExpectError(t, false, err)
+			assert.DeepEqual(t, tc.ExpectClusters, clusters)
+		})
+	}
+}
+
+func TestClusterResume(t *testing.T) {
+	t.Parallel()
+	p, ctx, opts, cleanup := newTestCluster(t, `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+nodes:
+- role: control-plane
+- role: control-plane
+- role: worker
+`)
+	defer cleanup()
+	p.Setup = func(n *fake.Node) {
+		setupNode(n)
+		if n.String() == "kind-worker" {
+			n.Script("kubeadm join", "preflight failed", errors.New("exit status 1"))
+		}
+	}
+	opts.Retain = true
+	assert.ExpectError(t, true, Cluster(log.NoopLogger{}, ctx, opts))
+
+	byName := map[string]*fake.Node{}
+	for _, n := range p.Nodes("kind") {
+		byName[n.String()] = n
+	}
+	state, _ := byName["kind-control-plane2"].File(actions.StatePath)
+	assert.StringEqual(t, "loadbalancer\nconfig\nkubeadminit\ninstallcni\ninstallstorage\nkubeadmjoin\n", state)
+	state, _ = byName["kind-worker"].File(actions.StatePath)
+	assert.StringEqual(t, "loadbalancer\nconfig\nkubeadminit\ninstallcni\ninstallstorage\n", state)
+
+	// resuming only joins the worker that failed to join
+	byName["kind-worker"].Script("kubeadm join", "", nil)
+	opts.Resume = true
+	summary := &events.Summary{}
+	opts.EventHandlers = []events.Handler{summary.Handle}
+	assert.ExpectError(t, false, Cluster(log.NoopLogger{}, ctx, opts))
+	results := []string{}
+	for _, e := range summary.Events() {
+		results = append(results, e.Name+" "+string(e.Result))
+	}
+	assert.DeepEqual(t, []string{
+		"loadbalancer skipped",
+		"config skipped",
+		"kubeadminit skipped",
+		"installcni skipped",
+		"installstorage skipped",
+		"kubeadmjoin success",
+		"waitforready success",
+		"kubeconfig success",
+	}, results)
+	assert.DeepEqual(t, 4, len(p.Nodes("kind")))
+	count := func(n *fake.Node, prefix string) int {
+		count := 0
+		for _, line := range commandLines(n) {
+			if strings.HasPrefix(line, prefix) {
+				count++
+			}
+		}
+		return count
+	}
+	assert.DeepEqual(t, 1, count(byName["kind-control-plane"], "kubeadm init"))
+	assert.DeepEqual(t, 1, count(byName["kind-control-plane2"], "kubeadm join"))
+	assert.DeepEqual(t, 2, count(byName["kind-worker"], "kubeadm join"))
+	state, _ = byName["kind-worker"].File(actions.StatePath)
+	assert.StringEqual(t, "loadbalancer\nconfig\nkubeadminit\ninstallcni\ninstallstorage\nkubeadmjoin\nwaitforready\n", state)
+
+	// clusters without nodes cannot be resumed
+	other := context.NewProviderContext(p, "other")
+	assert.ExpectError(t, true, Cluster(log.NoopLogger{}, other, opts))
+}
+
+func TestClusterInvalidName(t *testing.T) {
+	t.Parallel()
+	p := fake.NewProvider()
+	err := Cluster(log.NoopLogger{}, context.NewProviderContext(p, "not/valid"), &ClusterOptions{})
+	assert.ExpectError(t, true, err)
+	clusters, err := p.ListClusters()
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, []string{}, clusters)
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/kind/pkg/apis/config/defaults"
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/internal/context"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	configaction "sigs.k8s.io/kind/pkg/cluster/internal/create/actions/config"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/loadbalancer"
+	"sigs.k8s.io/kind/pkg/cluster/internal/kubeadm"
+	loadbalancerconfig "sigs.k8s.io/kind/pkg/cluster/internal/loadbalancer"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
+)
+
+// containerdConfigPath is the path of the containerd config on nodes
+const containerdConfigPath = "/etc/containerd/config.toml"
+
+// defaultKubeVersion is the Kubernetes version of the default node image,
+// which dry runs render for images not tagged with a version
+var defaultKubeVersion, _ = kubeVersionFromImage(defaults.Image)
+
+// dryRun writes the defaulted cluster config, the config files kind writes
+// to the nodes and the objects of the provider to opts.DryRun, without
+// creating anything
+func dryRun(logger log.Logger, ctx *context.Context, opts *ClusterOptions) error {
+	rawConfig, err := yaml.Marshal(opts.Config)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode cluster config")
+	}
+	writeDryRunSection(opts.DryRun, "cluster config", string(rawConfig))
+
+	files, err := renderNodeFiles(logger, ctx.Name(), opts.Config)
+	if err != nil {
+		return err
+	}
+	if len(files) > 0 {
+		fmt.Fprintln(opts.DryRun, "# node addresses are placeholders until the nodes are created")
+	}
+	for _, f := range files {
+		title := fmt.Sprintf("%s on node %s", f.path, f.node)
+		if f.path == containerdConfigPath {
+			// the base config comes with the node image
+			title = fmt.Sprintf("%s patches on node %s, merged into the config of the node image", f.path, f.node)
+		}
+		writeDryRunSection(opts.DryRun, title, f.contents)
+	}
+
+	objects, err := ctx.Provider().ProvisionDryRun(ctx.Name(), opts.Config)
+	if err != nil {
+		return err
+	}
+	writeDryRunSection(opts.DryRun, "provider objects", objects)
+	return nil
+}
+
+// writeDryRunSection writes contents to out under a comment with title
+func writeDryRunSection(out io.Writer, title, contents string) {
+	fmt.Fprintf(out, "# %s\n%s", title, contents)
+	if !strings.HasSuffix(contents, "\n") {
+		fmt.Fprintln(out)
+	}
+	fmt.Fprintln(out)
+}
+
+// nodeFile is a config file written to a node while creating a cluster
+type nodeFile struct {
+	node     string
+	path     string
+	contents string
+}
+
+// renderNodeFiles returns the config files the create actions write to the
+// nodes of cluster, by running them against in-memory nodes
+// The node addresses are placeholders, as the real ones are only known once
+// the nodes are running, and the containerd config only holds the patches, as
+// the base config is only known once the node image is pulled
+func renderNodeFiles(logger log.Logger, cluster string, cfg *config.Cluster) ([]nodeFile, error) {
+	// the actions read the Kubernetes version from the nodes
+	versions := map[string]string{}
+	nodeNamer := common.MakeNodeNamer(cluster)
+	for _, node := range cfg.Nodes {
+		v, err := kubeVersionFromImage(node.Image)
+		if err != nil {
+			logger.Warnf("Rendering the node configs of image %q for the default Kubernetes version %s: %v", node.Image, defaultKubeVersion, err)
+			v = defaultKubeVersion
+		}
+		versions[nodeNamer(string(node.Role))] = v
+	}
+
+	dryRunNodes := dryRunNodes(cluster, cfg)
+	provider := &dryRunProvider{}
+	for _, n := range dryRunNodes {
+		if v, ok := versions[n.String()]; ok {
+			n.setFile("/kind/version", v)
+		}
+		provider.nodes = append(provider.nodes, n)
+	}
+	status := cli.StatusForLogger(log.NoopLogger{})
+	actionsContext := actions.NewActionContext(log.NoopLogger{}, cfg, context.NewProviderContext(provider, cluster), status)
+	for _, action := range []actions.Action{loadbalancer.NewAction(), configaction.NewAction()} {
+		if err := action.Execute(actionsContext); err != nil {
+			return nil, err
+		}
+	}
+
+	files := []nodeFile{}
+	for _, n := range dryRunNodes {
+		paths := []string{kubeadm.ConfigPath, containerdConfigPath}
+		if n.role == constants.ExternalLoadBalancerNodeRoleValue {
+			paths = []string{loadbalancerconfig.ConfigPath}
+		}
+		for _, path := range paths {
+			if contents, ok := n.file(path); ok && contents != "" {
+				files = append(files, nodeFile{node: n.String(), path: path, contents: contents})
+			}
+		}
+	}
+	return files, nil
+}
+
+// kubeVersionFromImage returns the Kubernetes version of a node image from
+// its tag, node images are tagged with the version they contain
+func kubeVersionFromImage(image string) (string, error) {
+	// drop the digest, if any
+	ref := image
+	if i := strings.Index(ref, "@"); i >= 0 {
+		ref = ref[:i]
+	}
+	// the tag follows the last colon, unless that is a registry port
+	i := strings.LastIndex(ref, ":")
+	if i < 0 || strings.Contains(ref[i:], "/") {
+		return "", errors.Errorf("failed to get the Kubernetes version of image %q without a tag", image)
+	}
+	tag := ref[i+1:]
+	if _, err := version.ParseSemantic(tag); err != nil {
+		return "", errors.Wrapf(err, "failed to get the Kubernetes version of image %q from its tag", image)
+	}
+	return tag, nil
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+func TestClusterDryRun(t *testing.T) {
+	t.Parallel()
+	p, ctx, opts, cleanup := newTestCluster(t, `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+containerdConfigPatches:
+- |-
+  [plugins."io.containerd.grpc.v1.cri".registry.mirrors."localhost:5000"]
+    endpoint = ["http://kind-registry:5000"]
+nodes:
+- role: control-plane
+  image: kindest/node:v1.17.0
+- role: control-plane
+  image: kindest/node:v1.17.0
+- role: worker
+  image: kindest/node:v1.17.0
+`)
+	defer cleanup()
+	var out bytes.Buffer
+	opts.DryRun = &out
+	assert.ExpectError(t, false, Cluster(log.NoopLogger{}, ctx, opts))
+
+	// nothing is created
+	clusters, err := p.ListClusters()
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, []string{}, clusters)
+
+	for _, expected := range []string{
+		"# cluster config\n",
+		"# /kind/kubeadm.conf on node kind-control-plane\n",
+		"# /kind/kubeadm.conf on node kind-control-plane2\n",
+		"# /kind/kubeadm.conf on node kind-worker\n",
+		"# /etc/containerd/config.toml patches on node kind-worker, merged into the config of the node image\n",
+		"# /usr/local/etc/haproxy/haproxy.cfg on node kind-external-load-balancer\n",
+		"kubernetesVersion: v1.17.0\n",
+		`endpoint = ["http://kind-registry:5000"]`,
+		"# provider objects\nkind-external-load-balancer external-load-balancer\n",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected dry run output to contain %q, got:\n%s", expected, out.String())
+		}
+	}
+}
+
+func TestClusterDryRunUntaggedImage(t *testing.T) {
+	t.Parallel()
+	_, ctx, opts, cleanup := newTestCluster(t, `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+nodes:
+- role: control-plane
+  image: kindest/node:latest
+`)
+	defer cleanup()
+	var out bytes.Buffer
+	opts.DryRun = &out
+	assert.ExpectError(t, false, Cluster(log.NoopLogger{}, ctx, opts))
+
+	expected := "kubernetesVersion: " + defaultKubeVersion + "\n"
+	if !strings.Contains(out.String(), expected) {
+		t.Errorf("expected dry run output to contain %q, got:\n%s", expected, out.String())
+	}
+}
+
+func TestKubeVersionFromImage(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name        string
+		Image       string
+		Expected    string
+		ExpectError bool
+	}{
+		{
+			Name:     "tag",
+			Image:    "kindest/node:v1.17.0",
+			Expected: "v1.17.0",
+		},
+		{
+			Name:     "tag and digest",
+			Image:    "kindest/node:v1.16.3@sha256:70ce6ce09bee5c34ab14aec2b84d6edb260473a60638b1b095470a3a0f95ebec",
+			Expected: "v1.16.3",
+		},
+		{
+			Name:     "registry with port",
+			Image:    "localhost:5000/node:v1.17.0",
+			Expected: "v1.17.0",
+		},
+		{
+			Name:        "no tag",
+			Image:       "localhost:5000/node",
+			ExpectError: true,
+		},
+		{
+			Name:        "tag that is not a version",
+			Image:       "kindest/node:latest",
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			version, err := kubeVersionFromImage(tc.Image)
+			assert.ExpectError(t, tc.ExpectError, err)
+			assert.StringEqual(t, tc.Expected, version)
+		})
+	}
+}
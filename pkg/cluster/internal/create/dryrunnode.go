@@ -0,0 +1,241 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"bytes"
+	stdcontext "context"
+	"fmt"
+	"io"
+	"sync"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/cli"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
+)
+
+// errDryRun is returned by the dryRunProvider methods that would change or
+// inspect real nodes
+var errDryRun = errors.New("not available in a dry run")
+
+// dryRunProvider implements provider.Provider for the in-memory nodes of a
+// dry run, it only lists them
+type dryRunProvider struct {
+	nodes []nodes.Node
+}
+
+var _ provider.Provider = &dryRunProvider{}
+
+// Provision is part of the provider.Provider interface
+func (p *dryRunProvider) Provision(ctx stdcontext.Context, status *cli.Status, cluster string, cfg *config.Cluster) error {
+	return errDryRun
+}
+
+// ProvisionDryRun is part of the provider.Provider interface
+func (p *dryRunProvider) ProvisionDryRun(cluster string, cfg *config.Cluster) (string, error) {
+	return "", errDryRun
+}
+
+// ProvisionNode is part of the provider.Provider interface
+func (p *dryRunProvider) ProvisionNode(ctx stdcontext.Context, status *cli.Status, cluster, name string, cfg *config.Cluster, node *config.Node) error {
+	return errDryRun
+}
+
+// ListClusters is part of the provider.Provider interface
+func (p *dryRunProvider) ListClusters() ([]string, error) {
+	return nil, errDryRun
+}
+
+// ListNodes is part of the provider.Provider interface
+func (p *dryRunProvider) ListNodes(cluster string) ([]nodes.Node, error) {
+	return p.nodes, nil
+}
+
+// DeleteNodes is part of the provider.Provider interface
+func (p *dryRunProvider) DeleteNodes(ctx stdcontext.Context, n []nodes.Node) error {
+	return errDryRun
+}
+
+// StopNodes is part of the provider.Provider interface
+func (p *dryRunProvider) StopNodes(ctx stdcontext.Context, n []nodes.Node) error {
+	return errDryRun
+}
+
+// StartNodes is part of the provider.Provider interface
+func (p *dryRunProvider) StartNodes(ctx stdcontext.Context, n []nodes.Node) error {
+	return errDryRun
+}
+
+// GetAPIServerEndpoint is part of the provider.Provider interface
+// The endpoint is only known once the nodes are created
+func (p *dryRunProvider) GetAPIServerEndpoint(cluster string) (string, error) {
+	return "", errDryRun
+}
+
+// dryRunNodes returns in-memory nodes for the nodes of cluster, with
+// placeholder addresses
+func dryRunNodes(cluster string, cfg *config.Cluster) []*dryRunNode {
+	roles := []string{}
+	if countControlPlanes(cfg) > 1 {
+		roles = append(roles, constants.ExternalLoadBalancerNodeRoleValue)
+	}
+	for _, node := range cfg.Nodes {
+		roles = append(roles, string(node.Role))
+	}
+	nodeNamer := common.MakeNodeNamer(cluster)
+	dryRunNodes := make([]*dryRunNode, len(roles))
+	for i, role := range roles {
+		dryRunNodes[i] = &dryRunNode{
+			name:  nodeNamer(role),
+			role:  role,
+			ipv4:  fmt.Sprintf("172.17.0.%d", i+2),
+			ipv6:  fmt.Sprintf("fc00::%d", i+2),
+			files: map[string]string{},
+		}
+	}
+	return dryRunNodes
+}
+
+// countControlPlanes returns the number of control plane nodes in cfg
+func countControlPlanes(cfg *config.Cluster) int {
+	count := 0
+	for _, node := range cfg.Nodes {
+		if node.Role == config.ControlPlaneRole {
+			count++
+		}
+	}
+	return count
+}
+
+// dryRunNode is an in-memory node that records the files written to it
+// Reading a file that was not written returns nothing, other commands are
+// not run at all
+type dryRunNode struct {
+	name  string
+	role  string
+	ipv4  string
+	ipv6  string
+	mu    sync.Mutex
+	files map[string]string
+}
+
+var _ nodes.Node = &dryRunNode{}
+var _ exec.ContextCmder = &dryRunNode{}
+
+// String is part of the nodes.Node interface
+func (n *dryRunNode) String() string {
+	return n.name
+}
+
+// Role is part of the nodes.Node interface
+func (n *dryRunNode) Role() (string, error) {
+	return n.role, nil
+}
+
+// IP is part of the nodes.Node interface
+func (n *dryRunNode) IP() (ipv4 string, ipv6 string, err error) {
+	return n.ipv4, n.ipv6, nil
+}
+
+// setFile sets the contents of path on the node
+func (n *dryRunNode) setFile(path, contents string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.files[path] = contents
+}
+
+// file returns the contents of path on the node, if it was written
+func (n *dryRunNode) file(path string) (string, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	contents, ok := n.files[path]
+	return contents, ok
+}
+
+// Command is part of the exec.Cmder interface
+func (n *dryRunNode) Command(command string, args ...string) exec.Cmd {
+	return n.CommandContext(stdcontext.Background(), command, args...)
+}
+
+// CommandContext is part of the exec.ContextCmder interface
+func (n *dryRunNode) CommandContext(ctx stdcontext.Context, command string, args ...string) exec.Cmd {
+	return &dryRunCmd{
+		node:    n,
+		command: command,
+		args:    args,
+	}
+}
+
+// dryRunCmd implements exec.Cmd for dryRunNode
+type dryRunCmd struct {
+	node    *dryRunNode
+	command string
+	args    []string
+	stdin   io.Reader
+	stdout  io.Writer
+}
+
+// Run is part of the exec.Cmd interface
+// Only reading and copying files is simulated
+func (c *dryRunCmd) Run() error {
+	switch {
+	case c.command == "cat" && len(c.args) == 1:
+		if c.stdout == nil {
+			return nil
+		}
+		contents, _ := c.node.file(c.args[0])
+		_, err := io.WriteString(c.stdout, contents)
+		return errors.Wrap(err, "failed to write stdout")
+	case c.command == "cp" && len(c.args) == 2 && c.args[0] == "/dev/stdin":
+		var buff bytes.Buffer
+		if c.stdin != nil {
+			if _, err := io.Copy(&buff, c.stdin); err != nil {
+				return errors.Wrap(err, "failed to read stdin")
+			}
+		}
+		c.node.setFile(c.args[1], buff.String())
+	}
+	return nil
+}
+
+// SetEnv is part of the exec.Cmd interface
+func (c *dryRunCmd) SetEnv(env ...string) exec.Cmd {
+	return c
+}
+
+// SetStdin is part of the exec.Cmd interface
+func (c *dryRunCmd) SetStdin(r io.Reader) exec.Cmd {
+	c.stdin = r
+	return c
+}
+
+// SetStdout is part of the exec.Cmd interface
+func (c *dryRunCmd) SetStdout(w io.Writer) exec.Cmd {
+	c.stdout = w
+	return c
+}
+
+// SetStderr is part of the exec.Cmd interface
+func (c *dryRunCmd) SetStderr(w io.Writer) exec.Cmd {
+	return c
+}
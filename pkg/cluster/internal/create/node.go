@@ -0,0 +1,247 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	stdcontext "context"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/apis/config/encoding"
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/context"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	configaction "sigs.k8s.io/kind/pkg/cluster/internal/create/actions/config"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/kubeadmjoin"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/loadbalancer"
+	"sigs.k8s.io/kind/pkg/cluster/internal/kubeadm"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
+)
+
+// NodeOptions holds node creation options
+type NodeOptions struct {
+	Role config.NodeRole
+	// Image is the node image, defaulting to the image of the existing nodes
+	// This should match the Kubernetes version of the existing nodes
+	Image  string
+	Retain bool
+}
+
+// Node adds a node to the existing cluster identified by ctx, returning
+// the name of the new node
+func Node(logger log.Logger, ctx *context.Context, opts *NodeOptions) (string, error) {
+	if opts.Role != config.WorkerRole && opts.Role != config.ControlPlaneRole {
+		return "", errors.Errorf("unsupported node role %q", opts.Role)
+	}
+
+	allNodes, err := ctx.ListNodes()
+	if err != nil {
+		return "", errors.Wrap(err, "error listing nodes")
+	}
+	if len(allNodes) == 0 {
+		return "", errors.Errorf("no nodes found for cluster %q", ctx.Name())
+	}
+	// the control plane endpoint of the cluster must not change, so control
+	// plane nodes can only be added behind an external load balancer
+	if opts.Role == config.ControlPlaneRole {
+		loadBalancer, err := nodeutils.ExternalLoadBalancerNode(allNodes)
+		if err != nil {
+			return "", err
+		}
+		if loadBalancer == nil {
+			return "", errors.Errorf(
+				"cluster %q has no external load balancer, control plane nodes can only be added to clusters created with multiple control plane nodes",
+				ctx.Name(),
+			)
+		}
+	}
+	bootstrap, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return "", err
+	}
+
+	// the cluster wide settings the node needs are those of the existing nodes
+	cfg, err := clusterConfig(logger, ctx, bootstrap, opts.Image != "")
+	if err != nil {
+		return "", err
+	}
+	node := nodeConfig(cfg, opts.Role)
+	if opts.Image != "" {
+		node.Image = opts.Image
+	}
+	cfg.Nodes = []config.Node{node}
+	name := nextNodeName(ctx.Name(), string(opts.Role), allNodes)
+
+	status := cli.StatusForLogger(logger)
+	if err := ctx.Provider().ProvisionNode(ctx.Context(), status, ctx.Name(), name, cfg, &cfg.Nodes[0]); err != nil {
+		logger.Errorf("%v", err)
+		if !opts.Retain {
+			deleteNode(logger, ctx, name)
+		}
+		return "", err
+	}
+
+	if err := joinNode(logger, ctx, cfg, status, bootstrap, name, opts.Role); err != nil {
+		if !opts.Retain {
+			deleteNode(logger, ctx, name)
+		}
+		return "", err
+	}
+	return name, nil
+}
+
+// clusterConfig returns the config the cluster of bootstrap was created with
+// Clusters created before the config was kept on the nodes get the default
+// config, as long as the node image is explicit or the default image matches
+// their Kubernetes version
+func clusterConfig(logger log.Logger, ctx *context.Context, bootstrap nodes.Node, explicitImage bool) (*config.Cluster, error) {
+	cfg, err := configaction.ReadClusterConfig(ctx.Context(), bootstrap)
+	if err == nil {
+		return cfg, nil
+	}
+	logger.V(1).Infof("Using the default cluster config: %v", err)
+	cfg, err = encoding.Load("")
+	if err != nil {
+		return nil, err
+	}
+	cfg.Networking.IPFamily, err = kubeadm.IPFamily(ctx.Context(), bootstrap)
+	if err != nil {
+		return nil, err
+	}
+	if explicitImage {
+		return cfg, nil
+	}
+	kubeVersion, err := nodeutils.KubeVersion(bootstrap)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get kubernetes version from node")
+	}
+	imageVersion, err := kubeVersionFromImage(cfg.Nodes[0].Image)
+	if err != nil || imageVersion != kubeVersion {
+		return nil, errors.Errorf(
+			"failed to determine the node image of cluster %q running Kubernetes %s, please specify it with --image",
+			ctx.Name(), kubeVersion,
+		)
+	}
+	return cfg, nil
+}
+
+// nodeConfig returns the config for a new node with role, copied from the
+// first node with role in cfg, or else with the image of the first node
+// Port mappings are dropped as the host ports are taken by that node
+func nodeConfig(cfg *config.Cluster, role config.NodeRole) config.Node {
+	node := config.Node{Role: role}
+	if len(cfg.Nodes) > 0 {
+		node.Image = cfg.Nodes[0].Image
+	}
+	for _, existing := range cfg.Nodes {
+		if existing.Role == role {
+			node = *existing.DeepCopy()
+			node.ExtraPortMappings = nil
+			break
+		}
+	}
+	return node
+}
+
+// joinNode joins the provisioned node name with role to the cluster of
+// bootstrap
+func joinNode(logger log.Logger, ctx *context.Context, cfg *config.Cluster, status *cli.Status, bootstrap nodes.Node, name string, role config.NodeRole) error {
+	// the bootstrap token of the cluster may have expired
+	token, err := lastLine(ctx.Context(), bootstrap, "bootstrap token", "kubeadm", "token", "create")
+	if err != nil {
+		return err
+	}
+	// control plane nodes download the cluster certificates, which kubeadm
+	// deletes after a while
+	certificateKey := ""
+	if role == config.ControlPlaneRole {
+		certificateKey, err = lastLine(ctx.Context(), bootstrap, "certificate key", "kubeadm", "init", "phase", "upload-certs", "--upload-certs")
+		if err != nil {
+			return err
+		}
+	}
+
+	actionsToRun := []actions.Action{
+		configaction.NewNodeAction(name, token, certificateKey), // setup kubeadm config
+		kubeadmjoin.NewNodeAction(name),                         // run kubeadm join
+	}
+	if role == config.ControlPlaneRole {
+		actionsToRun = append(actionsToRun,
+			loadbalancer.NewAction(), // add the node to the load balancer
+		)
+	}
+	actionsContext := actions.NewActionContext(logger, cfg, ctx, status)
+	for _, action := range actionsToRun {
+		if err := action.Execute(actionsContext); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nextNodeName returns the first name for a node with role that is not
+// taken by any of allNodes
+func nextNodeName(cluster, role string, allNodes []nodes.Node) string {
+	taken := map[string]bool{}
+	for _, n := range allNodes {
+		taken[n.String()] = true
+	}
+	nodeNamer := common.MakeNodeNamer(cluster)
+	for {
+		if name := nodeNamer(role); !taken[name] {
+			return name
+		}
+	}
+}
+
+// lastLine runs command on n and returns the last line of its output, where
+// kubeadm prints the created value described by what
+func lastLine(ctx stdcontext.Context, n nodes.Node, what, command string, args ...string) (string, error) {
+	lines, err := exec.OutputLines(exec.CommandContextFor(ctx, n, command, args...))
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create %s", what)
+	}
+	if len(lines) == 0 || strings.TrimSpace(lines[len(lines)-1]) == "" {
+		return "", errors.Errorf("failed to create %s: no output", what)
+	}
+	return strings.TrimSpace(lines[len(lines)-1]), nil
+}
+
+// deleteNode deletes the node name of the cluster identified by ctx, if it
+// was created, after a failure to add it
+// Adding the node may have been cancelled, so it is deleted regardless
+func deleteNode(logger log.Logger, ctx *context.Context, name string) {
+	allNodes, err := ctx.ListNodes()
+	if err != nil {
+		logger.Errorf("failed to list nodes: %v", err)
+		return
+	}
+	for _, n := range allNodes {
+		if n.String() == name {
+			if err := ctx.Provider().DeleteNodes(stdcontext.Background(), []nodes.Node{n}); err != nil {
+				logger.Errorf("failed to delete node %s: %v", name, err)
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+	"sigs.k8s.io/kind/pkg/log"
+
+	configaction "sigs.k8s.io/kind/pkg/cluster/internal/create/actions/config"
+	"sigs.k8s.io/kind/pkg/cluster/internal/loadbalancer"
+)
+
+const testToken = "abcdef.fedcba9876543210"
+
+func TestNode(t *testing.T) {
+	t.Parallel()
+	p, ctx, opts, cleanup := newTestCluster(t, "")
+	defer cleanup()
+	assert.ExpectError(t, false, Cluster(log.NoopLogger{}, ctx, opts))
+	controlPlane := p.Nodes("kind")[0]
+	controlPlane.Script("kubeadm token create", testToken+"\n", nil)
+
+	name, err := Node(log.NoopLogger{}, ctx, &NodeOptions{Role: config.WorkerRole})
+	assert.ExpectError(t, false, err)
+	assert.StringEqual(t, "kind-worker", name)
+	name, err = Node(log.NoopLogger{}, ctx, &NodeOptions{Role: config.WorkerRole})
+	assert.ExpectError(t, false, err)
+	assert.StringEqual(t, "kind-worker2", name)
+
+	nodes := p.Nodes("kind")
+	assert.DeepEqual(t, 3, len(nodes))
+	worker := nodes[1]
+	assert.StringEqual(t, "kind-worker", worker.String())
+	kubeadmConfig, ok := worker.File("/kind/kubeadm.conf")
+	assert.DeepEqual(t, true, ok)
+	assert.DeepEqual(t, true, strings.Contains(kubeadmConfig, testToken))
+	_, ok = worker.Ran("kubeadm join --config /kind/kubeadm.conf")
+	assert.DeepEqual(t, true, ok)
+
+	// the existing nodes are left alone
+	_, ok = controlPlane.Ran("kubeadm join")
+	assert.DeepEqual(t, false, ok)
+	kubeadmConfig, _ = controlPlane.File("/kind/kubeadm.conf")
+	assert.DeepEqual(t, false, strings.Contains(kubeadmConfig, testToken))
+
+	// without the cluster config the default image must match the cluster
+	controlPlane.Script("cat "+configaction.ClusterConfigPath, "", errors.New("no such file"))
+	_, err = Node(log.NoopLogger{}, ctx, &NodeOptions{Role: config.WorkerRole})
+	assert.ExpectError(t, true, err)
+	_, err = Node(log.NoopLogger{}, ctx, &NodeOptions{Role: config.WorkerRole, Image: "kindest/node:v1.17.0"})
+	assert.ExpectError(t, false, err)
+}
+
+func TestNodeControlPlane(t *testing.T) {
+	t.Parallel()
+	p, ctx, opts, cleanup := newTestCluster(t, `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+nodes:
+- role: control-plane
+- role: control-plane
+`)
+	defer cleanup()
+	assert.ExpectError(t, false, Cluster(log.NoopLogger{}, ctx, opts))
+	controlPlane := p.Nodes("kind")[0]
+	assert.StringEqual(t, "kind-control-plane", controlPlane.String())
+	controlPlane.Script("kubeadm token create", testToken+"\n", nil)
+	controlPlane.Script("kubeadm init phase upload-certs --upload-certs", "[upload-certs] Using certificate key:\ncafebabe\n", nil)
+
+	name, err := Node(log.NoopLogger{}, ctx, &NodeOptions{Role: config.ControlPlaneRole})
+	assert.ExpectError(t, false, err)
+	assert.StringEqual(t, "kind-control-plane3", name)
+	nodes := p.Nodes("kind")
+	added := nodes[2]
+	assert.StringEqual(t, name, added.String())
+
+	// the node downloads the uploaded certificates when joining
+	kubeadmConfig, ok := added.File("/kind/kubeadm.conf")
+	assert.DeepEqual(t, true, ok)
+	assert.DeepEqual(t, true, strings.Contains(kubeadmConfig, "certificateKey: cafebabe"))
+	_, ok = added.Ran("kubeadm join --config /kind/kubeadm.conf")
+	assert.DeepEqual(t, true, ok)
+
+	// the load balancer has the new backend
+	loadBalancer := nodes[3]
+	assert.StringEqual(t, "kind-external-load-balancer", loadBalancer.String())
+	ipv4, _, err := added.IP()
+	assert.ExpectError(t, false, err)
+	haproxyConfig, ok := loadBalancer.File(loadbalancer.ConfigPath)
+	assert.DeepEqual(t, true, ok)
+	assert.DeepEqual(t, true, strings.Contains(haproxyConfig, ipv4+":6443"))
+}
+
+func TestNodeClusterConfig(t *testing.T) {
+	t.Parallel()
+	p, ctx, opts, cleanup := newTestCluster(t, `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+networking:
+  podSubnet: 10.123.0.0/16
+nodes:
+- role: control-plane
+  image: kindest/node:v1.16.4
+- role: worker
+  image: kindest/node:v1.16.4
+  extraPortMappings:
+  - containerPort: 80
+    hostPort: 8080
+`)
+	defer cleanup()
+	assert.ExpectError(t, false, Cluster(log.NoopLogger{}, ctx, opts))
+	controlPlane := p.Nodes("kind")[0]
+	controlPlane.Script("kubeadm token create", testToken+"\n", nil)
+
+	// the node matches the existing nodes rather than the defaults
+	name, err := Node(log.NoopLogger{}, ctx, &NodeOptions{Role: config.WorkerRole})
+	assert.ExpectError(t, false, err)
+	added := p.Nodes("kind")[2]
+	assert.StringEqual(t, name, added.String())
+	assert.StringEqual(t, "kindest/node:v1.16.4", added.Image())
+	kubeadmConfig, ok := added.File("/kind/kubeadm.conf")
+	assert.DeepEqual(t, true, ok)
+	if !strings.Contains(kubeadmConfig, "10.123.0.0/16") {
+		t.Errorf("expected the kubeadm config to use the cluster pod subnet:\n%s", kubeadmConfig)
+	}
+
+	// an explicit image takes precedence
+	name, err = Node(log.NoopLogger{}, ctx, &NodeOptions{Role: config.WorkerRole, Image: "kindest/node:v1.16.5"})
+	assert.ExpectError(t, false, err)
+	added = p.Nodes("kind")[3]
+	assert.StringEqual(t, name, added.String())
+	assert.StringEqual(t, "kindest/node:v1.16.5", added.Image())
+}
+
+func TestNodeFailure(t *testing.T) {
+	t.Parallel()
+	p, ctx, opts, cleanup := newTestCluster(t, "")
+	defer cleanup()
+	assert.ExpectError(t, false, Cluster(log.NoopLogger{}, ctx, opts))
+	controlPlane := p.Nodes("kind")[0]
+	controlPlane.Script("kubeadm token create", "", errors.New("etcd is down"))
+
+	// the node is deleted unless retained
+	_, err := Node(log.NoopLogger{}, ctx, &NodeOptions{Role: config.WorkerRole})
+	assert.ExpectError(t, true, err)
+	assert.DeepEqual(t, 1, len(p.Nodes("kind")))
+	_, err = Node(log.NoopLogger{}, ctx, &NodeOptions{Role: config.WorkerRole, Retain: true})
+	assert.ExpectError(t, true, err)
+	assert.DeepEqual(t, 2, len(p.Nodes("kind")))
+
+	// control plane nodes cannot be added without a load balancer
+	_, err = Node(log.NoopLogger{}, ctx, &NodeOptions{Role: config.ControlPlaneRole})
+	assert.ExpectError(t, true, err)
+}
@@ -39,7 +39,7 @@ func Cluster(logger log.Logger, c *context.Context, explicitKubeconfigPath strin
 		logger.Errorf("failed to update kubeconfig: %v", kerr)
 	}
 
-	err = c.Provider().DeleteNodes(n)
+	err = c.Provider().DeleteNodes(c.Context(), n)
 	if err != nil {
 		return err
 	}
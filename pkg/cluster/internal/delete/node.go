@@ -0,0 +1,229 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package delete
+
+import (
+	stdcontext "context"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/context"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/loadbalancer"
+	"sigs.k8s.io/kind/pkg/cluster/internal/kubeadm"
+)
+
+// Node removes the node with nodeName from the cluster identified by ctx,
+// draining and deleting it from Kubernetes before deleting it
+// Control plane nodes are also removed from etcd and the external load
+// balancer, the last control plane node cannot be removed
+func Node(logger log.Logger, ctx *context.Context, nodeName string) (err error) {
+	allNodes, err := ctx.ListNodes()
+	if err != nil {
+		return errors.Wrap(err, "error listing nodes")
+	}
+	var node nodes.Node
+	for _, n := range allNodes {
+		if n.String() == nodeName {
+			node = n
+			break
+		}
+	}
+	if node == nil {
+		return errors.Errorf("unknown node %q in cluster %q", nodeName, ctx.Name())
+	}
+	role, err := node.Role()
+	if err != nil {
+		return err
+	}
+	if role != constants.WorkerNodeRoleValue && role != constants.ControlPlaneNodeRoleValue {
+		return errors.Errorf("unsupported node role %q", role)
+	}
+
+	// the cluster is operated on from another control plane node
+	controlPlanes, err := nodeutils.ControlPlaneNodes(allNodes)
+	if err != nil {
+		return err
+	}
+	var controlPlane nodes.Node
+	for _, n := range controlPlanes {
+		if n.String() != nodeName {
+			controlPlane = n
+			break
+		}
+	}
+	if controlPlane == nil {
+		return errors.Errorf("cannot delete %s, the last %s node", nodeName, constants.ControlPlaneNodeRoleValue)
+	}
+
+	status := cli.StatusForLogger(logger)
+	status.Start(fmt.Sprintf("Deleting node %s 🗑", nodeName))
+	defer func() { status.End(err == nil) }()
+
+	// the Kubernetes node and etcd member are named after the node hostname
+	// which may differ from the kind node name
+	kubeNodeName, err := hostname(ctx.Context(), node)
+	if err != nil {
+		return err
+	}
+	// move workloads off the node before it goes away
+	if _, err := kubectl(ctx.Context(), logger, controlPlane,
+		"drain", kubeNodeName,
+		"--ignore-daemonsets", "--delete-local-data", "--force",
+	); err != nil {
+		return errors.Wrapf(err, "failed to drain node %s", nodeName)
+	}
+	if role == constants.ControlPlaneNodeRoleValue {
+		if err := removeEtcdMember(ctx.Context(), logger, controlPlane, kubeNodeName); err != nil {
+			return err
+		}
+		if err := removeAPIEndpoint(ctx.Context(), logger, controlPlane, kubeNodeName); err != nil {
+			return err
+		}
+	}
+	if _, err := kubectl(ctx.Context(), logger, controlPlane, "delete", "node", kubeNodeName); err != nil {
+		return errors.Wrapf(err, "failed to delete node %s from kubernetes", nodeName)
+	}
+	if err := ctx.Provider().DeleteNodes(ctx.Context(), []nodes.Node{node}); err != nil {
+		return err
+	}
+	if role != constants.ControlPlaneNodeRoleValue {
+		return nil
+	}
+
+	// remove the node from the load balancer backends
+	ipFamily, err := kubeadm.IPFamily(ctx.Context(), controlPlane)
+	if err != nil {
+		return err
+	}
+	cfg := &config.Cluster{Networking: config.Networking{IPFamily: ipFamily}}
+	return loadbalancer.NewAction().Execute(actions.NewActionContext(logger, cfg, ctx, status))
+}
+
+// removeEtcdMember removes the etcd member of the Kubernetes node nodeName
+// using the etcd pod of controlPlane
+// This relies on etcdctl defaulting to the v3 API, as of etcd 3.4
+func removeEtcdMember(ctx stdcontext.Context, logger log.Logger, controlPlane nodes.Node, nodeName string) error {
+	// the etcd static pods are named after their node
+	controlPlaneName, err := hostname(ctx, controlPlane)
+	if err != nil {
+		return err
+	}
+	etcdctl := []string{
+		"--namespace", "kube-system", "exec", "etcd-" + controlPlaneName, "--",
+		"etcdctl",
+		"--endpoints=https://127.0.0.1:2379",
+		"--cacert=/etc/kubernetes/pki/etcd/ca.crt",
+		"--cert=/etc/kubernetes/pki/etcd/peer.crt",
+		"--key=/etc/kubernetes/pki/etcd/peer.key",
+	}
+	lines, err := kubectl(ctx, logger, controlPlane, append(etcdctl, "member", "list")...)
+	if err != nil {
+		return errors.Wrap(err, "failed to list etcd members")
+	}
+	// members are listed as ID, status, name, peer URLs, client URLs, ...
+	for _, line := range lines {
+		fields := strings.Split(line, ", ")
+		if len(fields) < 3 || fields[2] != nodeName {
+			continue
+		}
+		if _, err := kubectl(ctx, logger, controlPlane, append(etcdctl, "member", "remove", fields[0])...); err != nil {
+			return errors.Wrapf(err, "failed to remove etcd member of node %s", nodeName)
+		}
+		return nil
+	}
+	logger.Warnf("No etcd member found for node %s", nodeName)
+	return nil
+}
+
+// removeAPIEndpoint removes the API endpoint of the Kubernetes node nodeName
+// from the kubeadm ClusterStatus, which kubeadm uses to find the control
+// plane nodes when joining more of them
+func removeAPIEndpoint(ctx stdcontext.Context, logger log.Logger, controlPlane nodes.Node, nodeName string) error {
+	lines, err := kubectl(ctx, logger, controlPlane,
+		"get", "configmap", "kubeadm-config", "--namespace", "kube-system", "-o", "yaml",
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to get kubeadm config")
+	}
+	configMap := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(strings.Join(lines, "\n")), &configMap); err != nil {
+		return errors.Wrap(err, "failed to decode kubeadm config")
+	}
+	data, _ := configMap["data"].(map[string]interface{})
+	rawStatus, _ := data["ClusterStatus"].(string)
+	clusterStatus := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(rawStatus), &clusterStatus); err != nil {
+		return errors.Wrap(err, "failed to decode kubeadm cluster status")
+	}
+	endpoints, _ := clusterStatus["apiEndpoints"].(map[string]interface{})
+	if _, ok := endpoints[nodeName]; !ok {
+		return nil
+	}
+	delete(endpoints, nodeName)
+	updatedStatus, err := yaml.Marshal(clusterStatus)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode kubeadm cluster status")
+	}
+	data["ClusterStatus"] = string(updatedStatus)
+	updated, err := yaml.Marshal(configMap)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode kubeadm config")
+	}
+	cmd := exec.CommandContextFor(ctx, controlPlane,
+		"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf", "replace", "-f", "-",
+	).SetStdin(strings.NewReader(string(updated)))
+	if err := cmd.Run(); err != nil {
+		return errors.Wrapf(err, "failed to remove the API endpoint of node %s from the kubeadm config", nodeName)
+	}
+	return nil
+}
+
+// hostname returns the hostname of n, which names its Kubernetes node, or
+// the name of n if it has none
+// Nodes of the kubernetes provider with persistent storage are named after
+// their pod instead of the kind node, for example kind-worker-0
+func hostname(ctx stdcontext.Context, n nodes.Node) (string, error) {
+	lines, err := exec.OutputLines(exec.CommandContextFor(ctx, n, "hostname"))
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get hostname of node %s", n.String())
+	}
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) == "" {
+		return n.String(), nil
+	}
+	return strings.TrimSpace(lines[0]), nil
+}
+
+// kubectl runs kubectl with args on n as the cluster admin, returning the
+// output lines
+func kubectl(ctx stdcontext.Context, logger log.Logger, n nodes.Node, args ...string) ([]string, error) {
+	cmd := exec.CommandContextFor(ctx, n, "kubectl", append([]string{"--kubeconfig=/etc/kubernetes/admin.conf"}, args...)...)
+	lines, err := exec.CombinedOutputLines(cmd)
+	logger.V(3).Info(strings.Join(lines, "\n"))
+	return lines, err
+}
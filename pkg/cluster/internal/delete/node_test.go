@@ -0,0 +1,121 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package delete
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/cluster/fake"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/context"
+	"sigs.k8s.io/kind/pkg/cluster/internal/loadbalancer"
+)
+
+func TestNode(t *testing.T) {
+	t.Parallel()
+	p := fake.NewProvider()
+	controlPlane := fake.NewNode("kind-control-plane", "control-plane", "172.17.0.2", "")
+	worker := fake.NewNode("kind-worker", "worker", "172.17.0.3", "")
+	p.AddNode("kind", controlPlane)
+	p.AddNode("kind", worker)
+	ctx := context.NewProviderContext(p, "kind")
+
+	assert.ExpectError(t, false, Node(log.NoopLogger{}, ctx, "kind-worker"))
+	assert.DeepEqual(t, []*fake.Node{controlPlane}, p.Nodes("kind"))
+	commands := []string{}
+	for _, c := range controlPlane.Commands() {
+		commands = append(commands, c.String())
+	}
+	assert.DeepEqual(t, []string{
+		"kubectl --kubeconfig=/etc/kubernetes/admin.conf drain kind-worker --ignore-daemonsets --delete-local-data --force",
+		"kubectl --kubeconfig=/etc/kubernetes/admin.conf delete node kind-worker",
+	}, commands)
+
+	// unknown nodes and the last control plane node cannot be deleted
+	assert.ExpectError(t, true, Node(log.NoopLogger{}, ctx, "kind-worker"))
+	assert.ExpectError(t, true, Node(log.NoopLogger{}, ctx, "kind-control-plane"))
+	assert.DeepEqual(t, 2, len(controlPlane.Commands()))
+}
+
+func TestNodeControlPlane(t *testing.T) {
+	t.Parallel()
+	p := fake.NewProvider()
+	loadBalancer := fake.NewNode("kind-external-load-balancer", "external-load-balancer", "172.17.0.2", "")
+	controlPlane := fake.NewNode("kind-control-plane", "control-plane", "172.17.0.3", "")
+	controlPlane2 := fake.NewNode("kind-control-plane2", "control-plane", "172.17.0.4", "")
+	for _, n := range []*fake.Node{loadBalancer, controlPlane, controlPlane2} {
+		p.AddNode("kind", n)
+	}
+	// the Kubernetes nodes are named after the hostnames, like the pods of
+	// persistent kubernetes provider nodes
+	controlPlane.Script("hostname", "kind-control-plane-0\n", nil)
+	controlPlane2.Script("hostname", "kind-control-plane2-0\n", nil)
+	controlPlane2.SetFile("/kind/kubeadm.conf", "node-ip: 172.17.0.4\n")
+	etcdctl := "kubectl --kubeconfig=/etc/kubernetes/admin.conf --namespace kube-system exec etcd-kind-control-plane2-0 -- etcdctl" +
+		" --endpoints=https://127.0.0.1:2379 --cacert=/etc/kubernetes/pki/etcd/ca.crt" +
+		" --cert=/etc/kubernetes/pki/etcd/peer.crt --key=/etc/kubernetes/pki/etcd/peer.key"
+	controlPlane2.Script(etcdctl+" member list", strings.Join([]string{
+		"8e9e05c52164694d, started, kind-control-plane2-0, https://172.17.0.4:2380, https://172.17.0.4:2379, false",
+		"91bc3c398fb3c146, started, kind-control-plane-0, https://172.17.0.3:2380, https://172.17.0.3:2379, false",
+	}, "\n"), nil)
+	controlPlane2.Script("kubectl --kubeconfig=/etc/kubernetes/admin.conf get configmap kubeadm-config", `apiVersion: v1
+data:
+  ClusterStatus: |
+    apiEndpoints:
+      kind-control-plane-0:
+        advertiseAddress: 172.17.0.3
+        bindPort: 6443
+      kind-control-plane2-0:
+        advertiseAddress: 172.17.0.4
+        bindPort: 6443
+    apiVersion: kubeadm.k8s.io/v1beta2
+    kind: ClusterStatus
+kind: ConfigMap
+metadata:
+  name: kubeadm-config
+  namespace: kube-system
+`, nil)
+	ctx := context.NewProviderContext(p, "kind")
+
+	// the bootstrap control plane is operated on from the other one
+	assert.ExpectError(t, false, Node(log.NoopLogger{}, ctx, "kind-control-plane"))
+	assert.DeepEqual(t, []*fake.Node{controlPlane2, loadBalancer}, p.Nodes("kind"))
+	ran := map[string]bool{}
+	var replaced string
+	for _, c := range controlPlane2.Commands() {
+		ran[c.String()] = true
+		if c.String() == "kubectl --kubeconfig=/etc/kubernetes/admin.conf replace -f -" {
+			replaced = c.Stdin
+		}
+	}
+	assert.DeepEqual(t, true, ran["kubectl --kubeconfig=/etc/kubernetes/admin.conf drain kind-control-plane-0 --ignore-daemonsets --delete-local-data --force"])
+	assert.DeepEqual(t, true, ran[etcdctl+" member remove 91bc3c398fb3c146"])
+	assert.DeepEqual(t, true, ran["kubectl --kubeconfig=/etc/kubernetes/admin.conf delete node kind-control-plane-0"])
+
+	// the node is no longer a kubeadm API endpoint
+	assert.DeepEqual(t, false, strings.Contains(replaced, "kind-control-plane-0"))
+	assert.DeepEqual(t, true, strings.Contains(replaced, "kind-control-plane2-0"))
+
+	// the load balancer only has the remaining backend
+	haproxyConfig, ok := loadBalancer.File(loadbalancer.ConfigPath)
+	assert.DeepEqual(t, true, ok)
+	assert.DeepEqual(t, false, strings.Contains(haproxyConfig, "172.17.0.3"))
+	assert.DeepEqual(t, true, strings.Contains(haproxyConfig, "172.17.0.4:6443"))
+}
@@ -43,6 +43,9 @@ type ConfigData struct {
 	NodeAddress string
 	// The Token for TLS bootstrap
 	Token string
+	// CertificateKey decrypts the control plane certificates uploaded by
+	// kubeadm for control plane nodes joining an existing cluster
+	CertificateKey string
 	// The subnet used for pods
 	PodSubnet string
 	// The subnet used for services
@@ -303,6 +306,9 @@ controlPlane:
   localAPIEndpoint:
     advertiseAddress: "{{ .NodeAddress }}"
     bindPort: {{.APIBindPort}}
+  {{- if .CertificateKey }}
+  certificateKey: "{{ .CertificateKey }}"
+  {{- end }}
 {{- end }}
 nodeRegistration:
   criSocket: "/run/containerd/containerd.sock"
@@ -400,6 +406,9 @@ controlPlane:
   localAPIEndpoint:
     advertiseAddress: "{{ .NodeAddress }}"
     bindPort: {{.APIBindPort}}
+  {{- if .CertificateKey }}
+  certificateKey: "{{ .CertificateKey }}"
+  {{- end }}
 {{- end }}
 nodeRegistration:
   criSocket: "/run/containerd/containerd.sock"
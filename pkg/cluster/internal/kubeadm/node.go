@@ -0,0 +1,62 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeadm
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"regexp"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// ConfigPath is where the kubeadm config is written on nodes
+const ConfigPath = "/kind/kubeadm.conf"
+
+// nodeIPRE matches the node address in generated configs
+var nodeIPRE = regexp.MustCompile(`node-ip: "?([^"\s]+)`)
+
+// IPFamily returns the IP family of the cluster the kubeadm config on n
+// was generated for
+func IPFamily(ctx context.Context, n nodes.Node) (config.ClusterIPFamily, error) {
+	var buff bytes.Buffer
+	if err := exec.CommandContextFor(ctx, n, "cat", ConfigPath).SetStdout(&buff).Run(); err != nil {
+		return "", errors.Wrapf(err, "failed to read kubeadm config from node %s", n.String())
+	}
+	address := NodeAddress(buff.String())
+	if address == "" {
+		return "", errors.Errorf("failed to find the node address in the kubeadm config of node %s", n.String())
+	}
+	if ip := net.ParseIP(address); ip != nil && ip.To4() == nil {
+		return config.IPv6Family, nil
+	}
+	return config.IPv4Family, nil
+}
+
+// NodeAddress returns the node address kubeadmConfig was generated for, or
+// "" if it has none
+func NodeAddress(kubeadmConfig string) string {
+	match := nodeIPRE.FindStringSubmatch(kubeadmConfig)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package docker
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"strings"
@@ -69,11 +70,18 @@ func (n *node) IP() (ipv4 string, ipv6 string, err error) {
 	return ips[0], ips[1], nil
 }
 
+var _ exec.ContextCmder = &node{}
+
 func (n *node) Command(command string, args ...string) exec.Cmd {
+	return n.CommandContext(context.Background(), command, args...)
+}
+
+func (n *node) CommandContext(ctx context.Context, command string, args ...string) exec.Cmd {
 	return &nodeCmd{
 		nameOrID: n.name,
 		command:  command,
 		args:     args,
+		ctx:      ctx,
 	}
 }
 
@@ -86,6 +94,7 @@ type nodeCmd struct {
 	stdin    io.Reader
 	stdout   io.Writer
 	stderr   io.Writer
+	ctx      context.Context
 }
 
 func (c *nodeCmd) Run() error {
@@ -117,7 +126,7 @@ func (c *nodeCmd) Run() error {
 		// finally, with the caller args
 		c.args...,
 	)
-	cmd := exec.Command("docker", args...)
+	cmd := exec.CommandContext(c.ctx, "docker", args...)
 	if c.stdin != nil {
 		cmd.SetStdin(c.stdin)
 	}
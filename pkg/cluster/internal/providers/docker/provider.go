@@ -17,12 +17,14 @@ limitations under the License.
 package docker
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"strings"
 
 	"k8s.io/apimachinery/pkg/util/sets"
 
+	"sigs.k8s.io/kind/pkg/cluster/events"
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/exec"
@@ -49,10 +51,13 @@ type Provider struct {
 }
 
 // Provision is part of the providers.Provider interface
-func (p *Provider) Provision(status *cli.Status, cluster string, cfg *config.Cluster) (err error) {
+func (p *Provider) Provision(ctx context.Context, status *cli.Status, cluster string, cfg *config.Cluster) (err error) {
 	// TODO: validate cfg
 	// ensure node images are pulled before actually provisioning
-	ensureNodeImages(p.logger, status, cfg)
+	_ = events.RunContext(ctx, "pull-images", func() error {
+		ensureNodeImages(p.logger, status, cfg)
+		return nil
+	})
 
 	// actually provision the cluster
 	// TODO: strings.Repeat("📦", len(desiredNodes))
@@ -60,13 +65,48 @@ func (p *Provider) Provision(status *cli.Status, cluster string, cfg *config.Clu
 	defer func() { status.End(err == nil) }()
 
 	// plan creating the containers
-	createContainerFuncs, err := planCreation(cluster, cfg)
+	createContainerFuncs, err := planCreation(cluster, cfg, createContainer)
 	if err != nil {
 		return err
 	}
 
-	// actually create nodes
-	return errors.UntilErrorConcurrent(createContainerFuncs)
+	// actually create nodes, the first failure cancels creating the others
+	return events.RunContext(ctx, "create-nodes", func() error {
+		return errors.UntilErrorConcurrentContext(ctx, createContainerFuncs)
+	})
+}
+
+// ProvisionDryRun is part of the providers.Provider interface
+func (p *Provider) ProvisionDryRun(cluster string, cfg *config.Cluster) (string, error) {
+	recorder := &common.CommandRecorder{Command: "docker"}
+	createContainerFuncs, err := planCreation(cluster, cfg, func(_ context.Context, args []string) error {
+		return recorder.Record(args)
+	})
+	if err != nil {
+		return "", err
+	}
+	// record the commands in order rather than concurrently
+	for _, createContainer := range createContainerFuncs {
+		if err := createContainer(context.Background()); err != nil {
+			return "", err
+		}
+	}
+	return recorder.String(), nil
+}
+
+// ProvisionNode is part of the providers.Provider interface
+func (p *Provider) ProvisionNode(ctx context.Context, status *cli.Status, cluster, name string, cfg *config.Cluster, node *config.Node) (err error) {
+	// ensure the node image is pulled before actually provisioning
+	ensureNodeImages(p.logger, status, cfg)
+
+	status.Start(fmt.Sprintf("Preparing node %s 📦", name))
+	defer func() { status.End(err == nil) }()
+
+	createContainer, err := planNodeAddition(cluster, name, cfg, node)
+	if err != nil {
+		return err
+	}
+	return createContainer(ctx)
 }
 
 // ListClusters is part of the providers.Provider interface
@@ -113,7 +153,7 @@ func (p *Provider) ListNodes(cluster string) ([]nodes.Node, error) {
 }
 
 // DeleteNodes is part of the providers.Provider interface
-func (p *Provider) DeleteNodes(n []nodes.Node) error {
+func (p *Provider) DeleteNodes(ctx context.Context, n []nodes.Node) error {
 	if len(n) == 0 {
 		return nil
 	}
@@ -127,12 +167,39 @@ func (p *Provider) DeleteNodes(n []nodes.Node) error {
 	for _, node := range n {
 		args = append(args, node.String())
 	}
-	if err := exec.Command(command, args...).Run(); err != nil {
+	if err := exec.CommandContext(ctx, command, args...).Run(); err != nil {
 		return errors.Wrap(err, "failed to delete nodes")
 	}
 	return nil
 }
 
+// StopNodes is part of the providers.Provider interface
+func (p *Provider) StopNodes(ctx context.Context, n []nodes.Node) error {
+	if len(n) == 0 {
+		return nil
+	}
+	args := []string{"stop"}
+	for _, node := range n {
+		args = append(args, node.String())
+	}
+	if err := exec.CommandContext(ctx, "docker", args...).Run(); err != nil {
+		return errors.Wrap(err, "failed to stop nodes")
+	}
+	return nil
+}
+
+// StartNodes is part of the providers.Provider interface
+func (p *Provider) StartNodes(ctx context.Context, n []nodes.Node) error {
+	// start the nodes one at a time, so they are likely to be assigned the
+	// same addresses as when they were created
+	for _, node := range n {
+		if err := exec.CommandContext(ctx, "docker", "start", node.String()).Run(); err != nil {
+			return errors.Wrapf(err, "failed to start node %s", node.String())
+		}
+	}
+	return nil
+}
+
 // GetAPIServerEndpoint is part of the providers.Provider interface
 func (p *Provider) GetAPIServerEndpoint(cluster string) (string, error) {
 	// locate the node that hosts this
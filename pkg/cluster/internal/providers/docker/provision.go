@@ -17,11 +17,15 @@ limitations under the License.
 package docker
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"path/filepath"
+	"strconv"
 	"strings"
 
+	"k8s.io/apimachinery/pkg/api/resource"
+
 	"sigs.k8s.io/kind/pkg/cluster/constants"
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/exec"
@@ -32,7 +36,8 @@ import (
 )
 
 // planCreation creates a slice of funcs that will create the containers
-func planCreation(cluster string, cfg *config.Cluster) (createContainerFuncs []func() error, err error) {
+// by calling create with their run args
+func planCreation(cluster string, cfg *config.Cluster, create func(ctx context.Context, args []string) error) (createContainerFuncs []func(context.Context) error, err error) {
 	// these apply to all container creation
 	nodeNamer := common.MakeNodeNamer(cluster)
 	genericArgs, err := commonArgs(cluster, cfg)
@@ -51,60 +56,94 @@ func planCreation(cluster string, cfg *config.Cluster) (createContainerFuncs []f
 		}
 		// plan loadbalancer node
 		name := nodeNamer(constants.ExternalLoadBalancerNodeRoleValue)
-		createContainerFuncs = append(createContainerFuncs, func() error {
+		createContainerFuncs = append(createContainerFuncs, func(ctx context.Context) error {
 			args, err := runArgsForLoadBalancer(cfg, name, genericArgs)
 			if err != nil {
 				return err
 			}
-			return createContainer(args)
+			return create(ctx, args)
 		})
 	}
 
 	// plan normal nodes
-	for _, node := range cfg.Nodes {
-		node := node.DeepCopy()              // copy so we can modify
-		name := nodeNamer(string(node.Role)) // name the node
-
-		// fixup relative paths, docker can only handle absolute paths
-		for i := range node.ExtraMounts {
-			hostPath := node.ExtraMounts[i].HostPath
-			absHostPath, err := filepath.Abs(hostPath)
-			if err != nil {
-				return nil, errors.Wrapf(err, "unable to resolve absolute path for hostPath: %q", hostPath)
-			}
-			node.ExtraMounts[i].HostPath = absHostPath
+	for i := range cfg.Nodes {
+		node := &cfg.Nodes[i]
+		createContainerFunc, err := planNodeCreation(node, nodeNamer(string(node.Role)), genericArgs, apiServerAddress, apiServerPort, create)
+		if err != nil {
+			return nil, err
 		}
+		createContainerFuncs = append(createContainerFuncs, createContainerFunc)
+	}
+	return createContainerFuncs, nil
+}
+
+// planNodeCreation returns a func creating the container for node with name,
+// publishing the API server of control plane nodes on apiServerAddress and
+// apiServerPort, or a random port if apiServerPort is zero
+func planNodeCreation(node *config.Node, name string, genericArgs []string, apiServerAddress string, apiServerPort int32, create func(ctx context.Context, args []string) error) (func(context.Context) error, error) {
+	node = node.DeepCopy() // copy so we can modify
 
-		// plan actual creation based on role
-		switch node.Role {
-		case config.ControlPlaneRole:
-			createContainerFuncs = append(createContainerFuncs, func() error {
-				port, err := common.PortOrGetFreePort(apiServerPort, apiServerAddress)
-				if err != nil {
-					return errors.Wrap(err, "failed to get port for API server")
-				}
-				node.ExtraPortMappings = append(node.ExtraPortMappings,
-					config.PortMapping{
-						ListenAddress: apiServerAddress,
-						HostPort:      port,
-						ContainerPort: common.APIServerInternalPort,
-					},
-				)
-				return createContainer(runArgsForNode(node, name, genericArgs))
-			})
-		case config.WorkerRole:
-			createContainerFuncs = append(createContainerFuncs, func() error {
-				return createContainer(runArgsForNode(node, name, genericArgs))
-			})
-		default:
-			return nil, errors.Errorf("unknown node role: %q", node.Role)
+	// fixup relative paths, docker can only handle absolute paths
+	for i := range node.ExtraMounts {
+		hostPath := node.ExtraMounts[i].HostPath
+		absHostPath, err := filepath.Abs(hostPath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to resolve absolute path for hostPath: %q", hostPath)
 		}
+		node.ExtraMounts[i].HostPath = absHostPath
+	}
+
+	// plan actual creation based on role
+	switch node.Role {
+	case config.ControlPlaneRole:
+		return func(ctx context.Context) error {
+			port, err := common.PortOrGetFreePort(apiServerPort, apiServerAddress)
+			if err != nil {
+				return errors.Wrap(err, "failed to get port for API server")
+			}
+			node.ExtraPortMappings = append(node.ExtraPortMappings,
+				config.PortMapping{
+					ListenAddress: apiServerAddress,
+					HostPort:      port,
+					ContainerPort: common.APIServerInternalPort,
+				},
+			)
+			args, err := runArgsForNode(node, name, genericArgs)
+			if err != nil {
+				return err
+			}
+			return create(ctx, args)
+		}, nil
+	case config.WorkerRole:
+		return func(ctx context.Context) error {
+			args, err := runArgsForNode(node, name, genericArgs)
+			if err != nil {
+				return err
+			}
+			return create(ctx, args)
+		}, nil
+	default:
+		return nil, errors.Errorf("unknown node role: %q", node.Role)
 	}
-	return createContainerFuncs, nil
 }
 
-func createContainer(args []string) error {
-	if err := exec.Command("docker", args...).Run(); err != nil {
+// planNodeAddition returns a func creating the container for node with
+// name in an existing cluster
+func planNodeAddition(cluster, name string, cfg *config.Cluster, node *config.Node) (func(context.Context) error, error) {
+	genericArgs, err := commonArgs(cluster, cfg)
+	if err != nil {
+		return nil, err
+	}
+	// added control plane nodes are behind the external load balancer
+	apiServerAddress := "127.0.0.1"
+	if clusterIsIPv6(cfg) {
+		apiServerAddress = "::1"
+	}
+	return planNodeCreation(node, name, genericArgs, apiServerAddress, 0, createContainer)
+}
+
+func createContainer(ctx context.Context, args []string) error {
+	if err := exec.CommandContext(ctx, "docker", args...).Run(); err != nil {
 		return errors.Wrap(err, "docker run error")
 	}
 	return nil
@@ -157,7 +196,7 @@ func commonArgs(cluster string, cfg *config.Cluster) ([]string, error) {
 	return args, nil
 }
 
-func runArgsForNode(node *config.Node, name string, args []string) []string {
+func runArgsForNode(node *config.Node, name string, args []string) ([]string, error) {
 	args = append([]string{
 		"run",
 		"--hostname", name, // make hostname match container name
@@ -191,8 +230,15 @@ func runArgsForNode(node *config.Node, name string, args []string) []string {
 	args = append(args, generateMountBindings(node.ExtraMounts...)...)
 	args = append(args, generatePortMappings(node.ExtraPortMappings...)...)
 
+	// constrain the container to the node resources
+	resourceArgs, err := generateResourceArgs(node.Resources)
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, resourceArgs...)
+
 	// finally, specify the image to run
-	return append(args, node.Image)
+	return append(args, node.Image), nil
 }
 
 func runArgsForLoadBalancer(cfg *config.Cluster, name string, args []string) ([]string, error) {
@@ -308,3 +354,43 @@ func generatePortMappings(portMappings ...config.PortMapping) []string {
 	}
 	return args
 }
+
+// generateResourceArgs converts the node resources to a list of args for docker
+// docker has no notion of a cpu request, so it is mapped to relative cpu shares
+func generateResourceArgs(resources config.Resources) ([]string, error) {
+	args := []string{}
+	if resources.Limits.CPU != "" {
+		cpus, err := resource.ParseQuantity(resources.Limits.CPU)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid cpu limit %q", resources.Limits.CPU)
+		}
+		args = append(args, fmt.Sprintf("--cpus=%s", strconv.FormatFloat(float64(cpus.MilliValue())/1000, 'f', -1, 64)))
+	}
+	if resources.Limits.Memory != "" {
+		memory, err := resource.ParseQuantity(resources.Limits.Memory)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid memory limit %q", resources.Limits.Memory)
+		}
+		args = append(args, fmt.Sprintf("--memory=%d", memory.Value()))
+	}
+	if resources.Requests.CPU != "" {
+		cpus, err := resource.ParseQuantity(resources.Requests.CPU)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid cpu request %q", resources.Requests.CPU)
+		}
+		// 1024 shares is one cpu, docker requires at least 2
+		shares := cpus.MilliValue() * 1024 / 1000
+		if shares < 2 {
+			shares = 2
+		}
+		args = append(args, fmt.Sprintf("--cpu-shares=%d", shares))
+	}
+	if resources.Requests.Memory != "" {
+		memory, err := resource.ParseQuantity(resources.Requests.Memory)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid memory request %q", resources.Requests.Memory)
+		}
+		args = append(args, fmt.Sprintf("--memory-reservation=%d", memory.Value()))
+	}
+	return args, nil
+}
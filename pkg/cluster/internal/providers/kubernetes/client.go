@@ -0,0 +1,79 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// host holds the clients for the cluster hosting the node pods
+type host struct {
+	client    clientset.Interface
+	config    *rest.Config
+	namespace string
+	// kubeconfig and context are the explicit host kubeconfig path and
+	// context, if any
+	kubeconfig string
+	context    string
+}
+
+// newHost builds clients for the host cluster selected by opts,
+// if opts.Kubeconfig is empty the default kubectl loading rules are used
+// ($KUBECONFIG, then $HOME/.kube/config, then in-cluster config)
+func newHost(opts Options) (*host, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = opts.Kubeconfig
+	overrides := &clientcmd.ConfigOverrides{
+		CurrentContext: opts.Context,
+	}
+	overrides.Context.Namespace = opts.Namespace
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		loadingRules,
+		overrides,
+	)
+	config, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load host cluster kubeconfig")
+	}
+	// use the namespace of the selected context unless overridden, like kubectl
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get host cluster namespace")
+	}
+	client, err := clientset.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create host cluster client")
+	}
+	return &host{
+		client:     client,
+		config:     config,
+		namespace:  namespace,
+		kubeconfig: opts.Kubeconfig,
+		context:    opts.Context,
+	}, nil
+}
+
+// withNamespace returns a copy of h targeting namespace
+func (h *host) withNamespace(namespace string) *host {
+	scoped := *h
+	scoped.namespace = namespace
+	return &scoped
+}
@@ -16,15 +16,12 @@ limitations under the License.
 
 package kubernetes
 
-import "strings"
-
-// clusterLabelKey is applied to each "node" docker container for identification
+// clusterLabelKey is applied to each "node" pod for identification
 const clusterLabelKey = "io.x-k8s.kind.cluster"
 
-// nodeRoleLabelKey is applied to each "node" docker container for categorization
+// nodeRoleLabelKey is applied to each "node" pod for categorization
 // of nodes by role
 const nodeRoleLabelKey = "io.x-k8s.kind.role"
 
-func labelForJSONPath(key string) string {
-	return strings.ReplaceAll(key, ".", "\\.")
-}
+// nodeNameLabelKey is applied to each "node" pod so that Services can select it
+const nodeNameLabelKey = "io.x-k8s.kind.node"
@@ -0,0 +1,125 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// ProvisionDryRun returns the manifests of the objects Provision would
+// create on the host cluster, without connecting to it
+func (p *Provider) ProvisionDryRun(cluster string, cfg *config.Cluster) (string, error) {
+	if err := p.options.validate(); err != nil {
+		return "", err
+	}
+	objects, err := planObjects(p.options, cluster, cfg)
+	if err != nil {
+		return "", err
+	}
+	manifests := make([]string, 0, len(objects))
+	for _, object := range objects {
+		manifest, err := yaml.Marshal(object)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to encode %s", object.GetName())
+		}
+		manifests = append(manifests, string(manifest))
+	}
+	return strings.Join(manifests, "---\n"), nil
+}
+
+// planObjects returns the objects Provision creates for cluster, in order
+// The UID of the cluster owner is only known once it has been created, so
+// the owner references of the objects lack it
+func planObjects(opts Options, cluster string, cfg *config.Cluster) ([]metav1.Object, error) {
+	objects := []metav1.Object{}
+	namespace := ""
+	if opts.NamespacePerCluster {
+		namespace = clusterNamespace(cluster)
+		objects = append(objects, namespaceForCluster(namespace, cluster))
+	}
+	configMap, err := clusterOwner(cluster, cfg, opts)
+	if err != nil {
+		return nil, err
+	}
+	configMap.Namespace = namespace
+	objects = append(objects, configMap)
+	owner := ownerReference(configMap)
+
+	// namespaced objects are created in the namespace of the cluster
+	add := func(object metav1.Object) {
+		object.SetNamespace(namespace)
+		objects = append(objects, object)
+	}
+	addService := func(svc *corev1.Service) {
+		if svc != nil {
+			add(svc)
+		}
+	}
+
+	if opts.PersistentStorage {
+		add(ownedNodesService(cluster, owner))
+	}
+
+	nodeNamer := common.MakeNodeNamer(cluster)
+	loadBalancer := clusterHasImplicitLoadBalancer(cfg)
+	if loadBalancer {
+		name := nodeNamer(constants.ExternalLoadBalancerNodeRoleValue)
+		pod, err := ownedLoadBalancerPod(name, cluster, cfg.ProviderPatches, owner)
+		if err != nil {
+			return nil, err
+		}
+		add(pod)
+		ports := []corev1.ServicePort{apiServerServicePort()}
+		addService(ownedServiceForNode(name, cluster, opts.serviceType(), ports, owner))
+	}
+
+	for i := range cfg.Nodes {
+		node, err := prepareNode(cfg, &cfg.Nodes[i])
+		if err != nil {
+			return nil, err
+		}
+		name := nodeNamer(string(node.Role))
+		if opts.PersistentStorage {
+			statefulSet, err := ownedStatefulSetForNode(node, name, cluster, opts, owner)
+			if err != nil {
+				return nil, err
+			}
+			add(statefulSet)
+		} else {
+			pod, err := ownedPodForNode(node, name, cluster, owner)
+			if err != nil {
+				return nil, err
+			}
+			add(pod)
+		}
+		// only control planes expose the API server, unless the cluster has
+		// a load balancer in front of them
+		exposeAPIServer := node.Role == config.ControlPlaneRole && !loadBalancer
+		ports := servicePortsForNode(node, opts.serviceType(), exposeAPIServer)
+		addService(ownedServiceForNode(name, cluster, opts.serviceType(), ports, owner))
+	}
+	return objects, nil
+}
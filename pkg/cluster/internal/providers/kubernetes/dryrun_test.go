@@ -0,0 +1,98 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"fmt"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestPlanObjects(t *testing.T) {
+	t.Parallel()
+	ha := &config.Cluster{Nodes: []config.Node{
+		{Role: config.ControlPlaneRole, Image: "kindest/node:v1.17.0"},
+		{Role: config.ControlPlaneRole, Image: "kindest/node:v1.17.0"},
+		{Role: config.WorkerRole, Image: "kindest/node:v1.17.0"},
+	}}
+	cases := []struct {
+		Name     string
+		Options  Options
+		Expected []string
+	}{
+		{
+			Name: "pods",
+			Expected: []string{
+				"ConfigMap kind-kind",
+				"Pod kind-external-load-balancer",
+				"Service kind-external-load-balancer",
+				"Pod kind-control-plane",
+				"Pod kind-control-plane2",
+				"Pod kind-worker",
+			},
+		},
+		{
+			Name:    "persistent storage in a namespace per cluster",
+			Options: Options{PersistentStorage: true, NamespacePerCluster: true},
+			Expected: []string{
+				"Namespace kind-kind",
+				"ConfigMap kind-kind/kind-kind",
+				"Service kind-kind/kind-nodes",
+				"Pod kind-kind/kind-external-load-balancer",
+				"Service kind-kind/kind-external-load-balancer",
+				"StatefulSet kind-kind/kind-control-plane",
+				"StatefulSet kind-kind/kind-control-plane2",
+				"StatefulSet kind-kind/kind-worker",
+			},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			objects, err := planObjects(tc.Options, "kind", ha)
+			assert.ExpectError(t, false, err)
+			names := []string{}
+			for _, object := range objects {
+				kind := object.(runtime.Object).GetObjectKind().GroupVersionKind().Kind
+				name := object.GetName()
+				if object.GetNamespace() != "" {
+					name = fmt.Sprintf("%s/%s", object.GetNamespace(), name)
+				}
+				names = append(names, kind+" "+name)
+				// the owner is only referenced by name until it exists
+				if kind != "Namespace" && kind != "ConfigMap" {
+					assert.DeepEqual(t, []metav1.OwnerReference{{APIVersion: "v1", Kind: "ConfigMap", Name: "kind-kind"}}, object.GetOwnerReferences())
+				}
+			}
+			assert.DeepEqual(t, tc.Expected, names)
+		})
+	}
+
+	// nothing is created without a host cluster
+	p := &Provider{}
+	manifests, err := p.ProvisionDryRun("kind", ha)
+	assert.ExpectError(t, false, err)
+	if manifests == "" {
+		t.Errorf("expected manifests")
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/internal/loadbalancer"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+// clusterHasImplicitLoadBalancer returns true if cfg needs an external load
+// balancer in front of multiple control plane nodes
+func clusterHasImplicitLoadBalancer(cfg *config.Cluster) bool {
+	controlPlanes := 0
+	for _, configNode := range cfg.Nodes {
+		if configNode.Role == config.ControlPlaneRole {
+			controlPlanes++
+		}
+	}
+	return controlPlanes > 1
+}
+
+// loadBalancerPod returns the pod running the external load balancer, which
+// is configured by the loadbalancer action like the docker provider's
+func loadBalancerPod(name, cluster string) *corev1.Pod {
+	automountServiceAccountToken := false
+	return &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Pod",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				clusterLabelKey:  cluster,
+				nodeRoleLabelKey: constants.ExternalLoadBalancerNodeRoleValue,
+				nodeNameLabelKey: name,
+			},
+		},
+		Spec: corev1.PodSpec{
+			Hostname:                     name,
+			AutomountServiceAccountToken: &automountServiceAccountToken,
+			Containers: []corev1.Container{
+				{
+					Name:  name,
+					Image: loadbalancer.Image,
+					Ports: []corev1.ContainerPort{
+						{
+							Name:          apiServerPortName,
+							ContainerPort: common.APIServerInternalPort,
+							Protocol:      corev1.ProtocolTCP,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// createLoadBalancerPod creates the external load balancer pod, the cluster
+// level provider patches apply to it as well
+func createLoadBalancerPod(logger log.Logger, h *host, name, cluster string, patches []string, owner metav1.OwnerReference) error {
+	pod, err := ownedLoadBalancerPod(name, cluster, patches, owner)
+	if err != nil {
+		return err
+	}
+	if manifest, err := yaml.Marshal(pod); err == nil {
+		logger.V(2).Infof("pod manifest for %s\n%s", name, manifest)
+	}
+	if _, err := h.client.CoreV1().Pods(h.namespace).Create(pod); err != nil {
+		return errors.Wrapf(err, "failed to create pod %s", name)
+	}
+	return nil
+}
+
+// ownedLoadBalancerPod returns the external load balancer pod with patches
+// applied, owned by owner
+func ownedLoadBalancerPod(name, cluster string, patches []string, owner metav1.OwnerReference) (*corev1.Pod, error) {
+	pod, err := patchPod(loadBalancerPod(name, cluster), patches)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to apply provider patches to pod %s", name)
+	}
+	pod.OwnerReferences = append(pod.OwnerReferences, owner)
+	return pod, nil
+}
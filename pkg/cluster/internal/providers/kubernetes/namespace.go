@@ -0,0 +1,106 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// clusterNamespace returns the host namespace for cluster when using
+// Options.NamespacePerCluster
+func clusterNamespace(cluster string) string {
+	return "kind-" + cluster
+}
+
+// ensureNamespace creates the namespace h is scoped to for cluster, labeled
+// with the cluster name so that it can be listed
+func ensureNamespace(h *host, cluster string) error {
+	_, err := h.client.CoreV1().Namespaces().Create(namespaceForCluster(h.namespace, cluster))
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "failed to create namespace %s", h.namespace)
+	}
+	return nil
+}
+
+// namespaceForCluster returns the namespace name for cluster
+func namespaceForCluster(name, cluster string) *corev1.Namespace {
+	return &corev1.Namespace{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Namespace",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				clusterLabelKey: cluster,
+			},
+		},
+	}
+}
+
+// listNamespacedClusters returns the clusters with a per-cluster namespace
+// The error is returned as is, so that callers can check its reason
+func listNamespacedClusters(h *host) ([]string, error) {
+	namespaces, err := h.client.CoreV1().Namespaces().List(metav1.ListOptions{
+		LabelSelector: clusterLabelKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	clusters := sets.NewString()
+	for _, namespace := range namespaces.Items {
+		// skip namespaces that are already going away
+		if namespace.DeletionTimestamp != nil {
+			continue
+		}
+		clusters.Insert(namespace.Labels[clusterLabelKey])
+	}
+	return clusters.List(), nil
+}
+
+// deleteNamespaceIfEmpty deletes the per-cluster namespace of cluster once
+// no nodes remain in it, so that deleting some nodes keeps the rest of the
+// cluster, namespaces not created for cluster are kept
+func deleteNamespaceIfEmpty(h *host, namespace, cluster string) error {
+	ns, err := h.client.CoreV1().Namespaces().Get(namespace, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to get namespace %s", namespace)
+	}
+	if ns.Labels[clusterLabelKey] != cluster {
+		return nil
+	}
+	remaining, err := hasNodes(h, namespace, clusterLabelKey)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list nodes in namespace %s", namespace)
+	}
+	if remaining {
+		return nil
+	}
+	err = h.client.CoreV1().Namespaces().Delete(namespace, &metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to delete namespace %s", namespace)
+	}
+	return nil
+}
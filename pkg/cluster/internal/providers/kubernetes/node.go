@@ -17,121 +17,236 @@ limitations under the License.
 package kubernetes
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"io"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
 
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/exec"
 )
 
-// nodes.Node implementation for the docker provider
+// nodes.Node implementation for the kubernetes provider
 type node struct {
-	name string
+	// cluster is the name of the cluster the node belongs to, if known
+	cluster string
+	name    string
+	// pod is the name of the pod implementing the node, which differs from
+	// name when the pod is managed by a StatefulSet
+	pod  string
+	host *host
 }
 
 func (n *node) String() string {
 	return n.name
 }
 
-func (n *node) getSelf() []string {
-	return []string{
-		"get", "pod",
-		n.name,
-	}
+func (n *node) getSelf() (*corev1.Pod, error) {
+	return n.host.client.CoreV1().Pods(n.host.namespace).Get(n.pod, metav1.GetOptions{})
 }
 
 func (n *node) Role() (string, error) {
-	args := append([]string{
-		"-o", fmt.Sprintf("jsonpath={.metadata.labels.%s}", labelForJSONPath(nodeRoleLabelKey)),
-	},
-		n.getSelf()...,
-	)
-	cmd := exec.Command("kubectl", args...)
-	lines, err := exec.OutputLines(cmd)
+	pod, err := n.getSelf()
+	if apierrors.IsNotFound(err) {
+		// stopped nodes only have a StatefulSet, with the pod template
+		statefulSet, serr := n.host.client.AppsV1().StatefulSets(n.host.namespace).Get(n.name, metav1.GetOptions{})
+		if serr == nil {
+			pod, err = &corev1.Pod{ObjectMeta: statefulSet.Spec.Template.ObjectMeta}, nil
+		}
+	}
 	if err != nil {
 		return "", errors.Wrap(err, "failed to get role for node")
 	}
-	if len(lines) != 1 {
-		return "", errors.Errorf("failed to get role for node: output lines %d != 1", len(lines))
+	role, ok := pod.Labels[nodeRoleLabelKey]
+	if !ok {
+		return "", errors.Errorf("failed to get role for node: missing label %s", nodeRoleLabelKey)
 	}
-	return lines[0], nil
+	return role, nil
 }
 
 func (n *node) IP() (ipv4 string, ipv6 string, err error) {
-	// retrieve the IP address of the node using docker inspect
-	args := append([]string{
-		"-o", "jsonpath={.status.podIP}",
-	},
-		n.getSelf()...,
-	)
-	cmd := exec.Command("kubectl", args...)
-	lines, err := exec.CombinedOutputLines(cmd)
+	// retrieve the IP address of the node from the pod status
+	pod, err := n.getSelf()
 	if err != nil {
-		return "", "", errors.Wrap(err, "failed to get container details")
+		return "", "", errors.Wrap(err, "failed to get pod details")
 	}
-	if len(lines) != 1 {
-		return "", "", errors.Errorf("file should only be one line, got %d lines", len(lines))
+	if pod.Status.PodIP == "" {
+		return "", "", errors.Errorf("pod %s has no IP assigned", n.pod)
 	}
-	return lines[0], "", nil
+	return pod.Status.PodIP, "", nil
+}
+
+// Ports implements nodes.PortLister
+func (n *node) Ports() ([]string, error) {
+	return servicePorts(n.host, n.name)
 }
 
+var _ exec.ContextCmder = &node{}
+
 func (n *node) Command(command string, args ...string) exec.Cmd {
+	return n.CommandContext(context.Background(), command, args...)
+}
+
+func (n *node) CommandContext(ctx context.Context, command string, args ...string) exec.Cmd {
 	return &nodeCmd{
-		nameOrID: n.name,
-		command:  command,
-		args:     args,
+		host:      n.host,
+		nameOrID:  n.pod,
+		container: n.name,
+		command:   command,
+		args:      args,
+		ctx:       ctx,
 	}
 }
 
-// nodeCmd implements exec.Cmd for docker nodes
+// nodeCmd implements exec.Cmd for kubernetes nodes
 type nodeCmd struct {
-	nameOrID string // the container name or ID
-	command  string
-	args     []string
-	env      []string
-	stdin    io.Reader
-	stdout   io.Writer
-	stderr   io.Writer
+	host      *host
+	nameOrID  string // the pod name
+	container string // the node container in the pod
+	command   string
+	args      []string
+	env       []string
+	stdin     io.Reader
+	stdout    io.Writer
+	stderr    io.Writer
+	ctx       context.Context
 }
 
 func (c *nodeCmd) Run() error {
-	args := []string{
-		"exec",
+	command := append([]string{c.command}, c.args...)
+	// exec has no notion of environment, so wrap the command with env
+	execCommand := command
+	if len(c.env) > 0 {
+		execCommand = append(append([]string{"env"}, c.env...), command...)
 	}
-	if c.stdin != nil {
-		args = append(args,
-			"-i", // interactive so we can supply input
-		)
+	req := c.host.client.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(c.host.namespace).
+		Name(c.nameOrID).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: c.container,
+			Command:   execCommand,
+			Stdin:     c.stdin != nil,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+	executor, err := remotecommand.NewSPDYExecutor(c.host.config, "POST", req.URL())
+	if err != nil {
+		return errors.WithStack(&exec.RunError{
+			Command: command,
+			Inner:   err,
+		})
 	}
-	// set env
-	// for _, env := range c.env {
-	// 	args = append(args, "-e", env)
-	// }
-	// specify the container and command, after this everything will be
-	// args the command in the container rather than to docker
-	args = append(
-		args,
-		c.nameOrID, // ... against the container
-		"--",       // separator, all the other commands and arguments will be given to pod
-		c.command,
-	)
-
-	args = append(
-		args,
-		// finally, with the caller args
-		c.args...,
-	)
-	cmd := exec.Command("kubectl", args...)
-	if c.stdin != nil {
-		cmd.SetStdin(c.stdin)
+
+	// capture the combined output for errors, like exec.LocalCmd
+	// the remote streams are copied concurrently
+	combinedOutput := &syncBuffer{}
+	// the caller's writers are detached before returning
+	callerStdout := &detachableWriter{writer: c.stdout}
+	callerStderr := &detachableWriter{writer: c.stderr}
+	stdout := io.MultiWriter(callerStdout, combinedOutput)
+	stderr := io.MultiWriter(callerStderr, combinedOutput)
+	// the stream cannot be cancelled, so it is abandoned once ctx is done
+	// like the process killed by exec.CommandContext
+	streamErr := make(chan error, 1)
+	go func() {
+		streamErr <- executor.Stream(remotecommand.StreamOptions{
+			Stdin:  c.stdin,
+			Stdout: stdout,
+			Stderr: stderr,
+		})
+	}()
+	select {
+	case err = <-streamErr:
+	case <-c.ctx.Done():
+		err = c.ctx.Err()
+		// the abandoned stream must not write to the caller after returning
+		callerStdout.Detach()
+		callerStderr.Detach()
 	}
-	if c.stderr != nil {
-		cmd.SetStderr(c.stderr)
+	if err != nil {
+		return errors.WithStack(&exec.RunError{
+			Command: command,
+			Output:  combinedOutput.Bytes(),
+			Inner:   exitError(err),
+		})
 	}
-	if c.stdout != nil {
-		cmd.SetStdout(c.stdout)
+	return nil
+}
+
+// ExitError is the error of a command exiting non-zero on a node, exposing
+// the exit code like os/exec.ExitError
+type ExitError struct {
+	Code int
+}
+
+var _ error = &ExitError{}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// ExitCode returns the exit code of the command
+func (e *ExitError) ExitCode() int {
+	return e.Code
+}
+
+// exitError converts remote exit codes into an *ExitError
+func exitError(err error) error {
+	if exitErr, ok := err.(utilexec.ExitError); ok && exitErr.Exited() {
+		return &ExitError{Code: exitErr.ExitStatus()}
+	}
+	return err
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent writes
+type syncBuffer struct {
+	mu     sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buffer.Write(p)
+}
+
+func (b *syncBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buffer.Bytes()
+}
+
+// detachableWriter writes to writer until it is detached, then discards
+// writes, writer may be nil
+type detachableWriter struct {
+	mu     sync.Mutex
+	writer io.Writer
+}
+
+func (w *detachableWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.writer == nil {
+		return len(p), nil
 	}
-	return cmd.Run()
+	return w.writer.Write(p)
+}
+
+// Detach stops writing to writer, waiting for any write in progress
+func (w *detachableWriter) Detach() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writer = nil
 }
 
 func (c *nodeCmd) SetEnv(env ...string) exec.Cmd {
@@ -0,0 +1,76 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	utilexec "k8s.io/client-go/util/exec"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestExitError(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name     string
+		Err      error
+		Expected string
+		Code     int
+	}{
+		{
+			Name:     "exit code",
+			Err:      utilexec.CodeExitError{Err: fmt.Errorf("command terminated with exit code 2"), Code: 2},
+			Expected: "exit status 2",
+			Code:     2,
+		},
+		{
+			Name:     "stream error",
+			Err:      fmt.Errorf("error dialing backend"),
+			Expected: "error dialing backend",
+			Code:     -1,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			err := exitError(tc.Err)
+			assert.StringEqual(t, tc.Expected, err.Error())
+			code := -1
+			if exitErr, ok := err.(*ExitError); ok {
+				code = exitErr.ExitCode()
+			}
+			assert.DeepEqual(t, tc.Code, code)
+		})
+	}
+}
+
+func TestDetachableWriter(t *testing.T) {
+	t.Parallel()
+	var buff bytes.Buffer
+	w := &detachableWriter{writer: &buff}
+	_, err := w.Write([]byte("before\n"))
+	assert.ExpectError(t, false, err)
+	w.Detach()
+	n, err := w.Write([]byte("after\n"))
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, len("after\n"), n)
+	assert.StringEqual(t, "before\n", buff.String())
+}
@@ -0,0 +1,239 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// clusterOwnerName returns the name of the ConfigMap owning all of the
+// objects of cluster
+func clusterOwnerName(cluster string) string {
+	return "kind-" + cluster
+}
+
+// ownerHostKey is the key of the cluster owner data keeping the Options the
+// cluster was created with, see savedOptions
+const ownerHostKey = "host"
+
+// savedOptions are the Options kept with a cluster, so that later commands
+// find and extend it without being given the same options again
+// The host kubeconfig and context select the host cluster the options are
+// kept in, so they cannot be kept with it, and the namespace is the one the
+// cluster owner is found in
+type savedOptions struct {
+	NamespacePerCluster bool   `json:"namespacePerCluster,omitempty"`
+	ServiceType         string `json:"serviceType,omitempty"`
+	PersistentStorage   bool   `json:"persistentStorage,omitempty"`
+	StorageClass        string `json:"storageClass,omitempty"`
+	StorageSize         string `json:"storageSize,omitempty"`
+}
+
+// ensureClusterOwner creates the ConfigMap representing cluster, if it does
+// not already exist, and returns a reference to it for the cluster objects
+// Deleting the ConfigMap garbage collects everything that references it
+func ensureClusterOwner(h *host, cluster string, cfg *config.Cluster, opts Options) (metav1.OwnerReference, error) {
+	owner, err := clusterOwner(cluster, cfg, opts)
+	if err != nil {
+		return metav1.OwnerReference{}, err
+	}
+	configMaps := h.client.CoreV1().ConfigMaps(h.namespace)
+	created, err := configMaps.Create(owner)
+	if apierrors.IsAlreadyExists(err) {
+		created, err = configMaps.Get(owner.Name, metav1.GetOptions{})
+	}
+	if err != nil {
+		return metav1.OwnerReference{}, errors.Wrapf(err, "failed to create cluster owner %s", owner.Name)
+	}
+	return ownerReference(created), nil
+}
+
+// clusterOwner returns the ConfigMap representing cluster created with opts
+func clusterOwner(cluster string, cfg *config.Cluster, opts Options) (*corev1.ConfigMap, error) {
+	rawConfig, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode cluster config")
+	}
+	rawOptions, err := yaml.Marshal(savedOptions{
+		NamespacePerCluster: opts.NamespacePerCluster,
+		ServiceType:         opts.ServiceType,
+		PersistentStorage:   opts.PersistentStorage,
+		StorageClass:        opts.StorageClass,
+		StorageSize:         opts.StorageSize,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode host options")
+	}
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: clusterOwnerName(cluster),
+			Labels: map[string]string{
+				clusterLabelKey: cluster,
+			},
+		},
+		Data: map[string]string{
+			"cluster":    cluster,
+			"config":     string(rawConfig),
+			ownerHostKey: string(rawOptions),
+		},
+	}, nil
+}
+
+// ownerOptions returns opts with the options kept in the cluster owner
+// found in namespace
+func ownerOptions(owner *corev1.ConfigMap, namespace string, opts Options) (Options, error) {
+	saved := savedOptions{}
+	if err := yaml.Unmarshal([]byte(owner.Data[ownerHostKey]), &saved); err != nil {
+		return opts, errors.Wrapf(err, "failed to decode host options of cluster owner %s", owner.Name)
+	}
+	opts.Namespace = namespace
+	opts.NamespacePerCluster = saved.NamespacePerCluster
+	opts.ServiceType = saved.ServiceType
+	opts.PersistentStorage = saved.PersistentStorage
+	opts.StorageClass = saved.StorageClass
+	opts.StorageSize = saved.StorageSize
+	return opts, nil
+}
+
+// findClusterOwner returns the ConfigMap representing cluster and its
+// namespace, or nil if it is not found
+// The namespaces clusters are created in are checked first, then every
+// namespace, which the host credentials may not be allowed to list
+func findClusterOwner(h *host, cluster string, opts Options) (*corev1.ConfigMap, error) {
+	name := clusterOwnerName(cluster)
+	candidates := []string{h.namespace, clusterNamespace(cluster)}
+	if opts.NamespacePerCluster {
+		candidates = []string{clusterNamespace(cluster), h.namespace}
+	}
+	for _, namespace := range candidates {
+		owner, err := h.client.CoreV1().ConfigMaps(namespace).Get(name, metav1.GetOptions{})
+		if err == nil {
+			return owner, nil
+		}
+		if !apierrors.IsNotFound(err) && !apierrors.IsForbidden(err) {
+			return nil, errors.Wrapf(err, "failed to get cluster owner %s", name)
+		}
+	}
+	owners, err := listClusterOwners(h, labels.Set{clusterLabelKey: cluster}.String())
+	if err != nil {
+		return nil, err
+	}
+	if len(owners) == 0 {
+		return nil, nil
+	}
+	return &owners[0], nil
+}
+
+// listClusterOwners returns the ConfigMaps representing the clusters
+// matching selector in every namespace, or none if the host credentials
+// are not allowed to list them
+func listClusterOwners(h *host, selector string) ([]corev1.ConfigMap, error) {
+	list, err := h.client.CoreV1().ConfigMaps(metav1.NamespaceAll).List(metav1.ListOptions{
+		LabelSelector: selector,
+	})
+	if apierrors.IsForbidden(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list cluster owners")
+	}
+	owners := []corev1.ConfigMap{}
+	for _, owner := range list.Items {
+		if owner.DeletionTimestamp == nil && owner.Name == clusterOwnerName(owner.Labels[clusterLabelKey]) {
+			owners = append(owners, owner)
+		}
+	}
+	return owners, nil
+}
+
+// ownerReference returns a reference to the cluster owner for the cluster
+// objects
+func ownerReference(owner *corev1.ConfigMap) metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion: "v1",
+		Kind:       "ConfigMap",
+		Name:       owner.Name,
+		UID:        owner.UID,
+	}
+}
+
+// deleteClusterOwnerIfEmpty deletes the ConfigMap representing cluster once
+// no nodes remain, collecting any objects left behind
+func deleteClusterOwnerIfEmpty(h *host, cluster string) error {
+	remaining, err := hasNodes(h, h.namespace, labels.Set{clusterLabelKey: cluster}.String())
+	if err != nil {
+		return errors.Wrapf(err, "failed to list nodes of cluster %s", cluster)
+	}
+	if remaining {
+		return nil
+	}
+	name := clusterOwnerName(cluster)
+	propagation := metav1.DeletePropagationBackground
+	err = h.client.CoreV1().ConfigMaps(h.namespace).Delete(name, &metav1.DeleteOptions{
+		PropagationPolicy: &propagation,
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to delete cluster owner %s", name)
+	}
+	return nil
+}
+
+// hasNodes returns true if any node pods, StatefulSets or claims matching
+// selector remain in namespace
+// Stopped nodes with persistent storage have no pod, but keep the others
+func hasNodes(h *host, namespace, selector string) (bool, error) {
+	opts := metav1.ListOptions{LabelSelector: selector}
+	pods, err := h.client.CoreV1().Pods(namespace).List(opts)
+	if err != nil {
+		return false, err
+	}
+	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp == nil {
+			return true, nil
+		}
+	}
+	statefulSets, err := h.client.AppsV1().StatefulSets(namespace).List(opts)
+	if err != nil {
+		return false, err
+	}
+	for _, statefulSet := range statefulSets.Items {
+		if statefulSet.DeletionTimestamp == nil {
+			return true, nil
+		}
+	}
+	claims, err := h.client.CoreV1().PersistentVolumeClaims(namespace).List(opts)
+	if err != nil {
+		return false, err
+	}
+	for _, claim := range claims.Items {
+		if claim.DeletionTimestamp == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -1,89 +1,343 @@
 package kubernetes
 
 import (
+	"context"
 	"fmt"
-	"strings"
+	"sync"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/kind/pkg/cluster/events"
 	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider"
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
 	"sigs.k8s.io/kind/pkg/errors"
-	"sigs.k8s.io/kind/pkg/exec"
 	"sigs.k8s.io/kind/pkg/internal/apis/config"
 	"sigs.k8s.io/kind/pkg/internal/cli"
 	"sigs.k8s.io/kind/pkg/log"
 )
 
-// NewProvider returns a new provider based on executing `docker ...`
-func NewProvider(logger log.Logger) provider.Provider {
+// Options configures the host cluster used by the provider
+type Options struct {
+	// Kubeconfig is the path to the host cluster kubeconfig
+	// If unset the default kubectl loading rules are used
+	Kubeconfig string
+	// Context is the host kubeconfig context to use
+	// If unset the current context is used
+	Context string
+	// Namespace is the host namespace to create nodes in
+	// If unset the namespace of the context is used, or "default"
+	Namespace string
+	// NamespacePerCluster creates each cluster in its own host namespace,
+	// which is created and deleted along with the cluster, see clusterNamespace
+	// Namespace is ignored when this is set
+	NamespacePerCluster bool
+	// ServiceType is the type of the Services exposing the API server of
+	// control plane nodes and the ExtraPortMappings of all nodes,
+	// either NodePort or LoadBalancer
+	// Defaults to NodePort
+	ServiceType string
+	// PersistentStorage runs each node as a single replica StatefulSet with
+	// /var on a PersistentVolumeClaim instead of a bare pod with an emptyDir
+	// Nodes survive pod restarts on the same IP, rescheduled node pods get a
+	// new IP that the cluster is only updated for by starting it again
+	// NOTE: the StatefulSet controller names the pod and its hostname
+	// <node>-0, so Kubernetes node names in the cluster carry the suffix
+	PersistentStorage bool
+	// StorageClass is the StorageClass of the node claims
+	// If unset the default StorageClass of the host cluster is used
+	StorageClass string
+	// StorageSize is the requested size of the node claims
+	// Defaults to defaultStorageSize
+	StorageSize string
+}
+
+// serviceType returns the defaulted ServiceType
+func (o Options) serviceType() corev1.ServiceType {
+	if o.ServiceType == "" {
+		return corev1.ServiceTypeNodePort
+	}
+	return corev1.ServiceType(o.ServiceType)
+}
+
+// validate returns an error if the options cannot be used to create nodes
+func (o Options) validate() error {
+	switch o.serviceType() {
+	case corev1.ServiceTypeNodePort, corev1.ServiceTypeLoadBalancer:
+	default:
+		return errors.Errorf("unsupported service type: %q", o.ServiceType)
+	}
+	if o.PersistentStorage {
+		if _, err := o.storageSize(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewProvider returns a new provider based on a client-go clientset for
+// the host cluster selected by opts
+func NewProvider(logger log.Logger, opts Options) provider.Provider {
 	return &Provider{
-		logger: logger,
+		logger:  logger,
+		options: opts,
 	}
 }
 
 // Provider implements provider.Provider
 // see NewProvider
 type Provider struct {
-	logger log.Logger
+	logger  log.Logger
+	options Options
+
+	// the host cluster clients are created lazily on first use
+	hostOnce sync.Once
+	hostErr  error
+	h        *host
+
+	// clusters caches the host namespace and options of existing clusters
+	clustersMu sync.Mutex
+	clusters   map[string]clusterHost
+}
+
+// clusterHost is the host namespace and options of a cluster
+type clusterHost struct {
+	h       *host
+	options Options
 }
 
 var _ provider.Provider = &Provider{}
 
+// host returns the host cluster clients, creating them if necessary
+func (p *Provider) host() (*host, error) {
+	p.hostOnce.Do(func() {
+		if p.h != nil {
+			return
+		}
+		p.h, p.hostErr = newHost(p.options)
+	})
+	return p.h, p.hostErr
+}
+
+// hostFor returns the host cluster clients scoped to the namespace of cluster
+func (p *Provider) hostFor(cluster string) (*host, error) {
+	c, err := p.clusterHost(cluster)
+	if err != nil {
+		return nil, err
+	}
+	return c.h, nil
+}
+
+// clusterHost returns the host namespace and options cluster was created
+// with, which are kept in its owner, or else the ones it would be created
+// with by this provider
+func (p *Provider) clusterHost(cluster string) (clusterHost, error) {
+	h, err := p.host()
+	if err != nil {
+		return clusterHost{}, err
+	}
+	p.clustersMu.Lock()
+	defer p.clustersMu.Unlock()
+	if c, ok := p.clusters[cluster]; ok {
+		return c, nil
+	}
+	owner, err := findClusterOwner(h, cluster, p.options)
+	if err != nil {
+		return clusterHost{}, err
+	}
+	// clusters that do not exist yet are not cached, they may be created
+	if owner == nil {
+		if p.options.NamespacePerCluster {
+			h = h.withNamespace(clusterNamespace(cluster))
+		}
+		return clusterHost{h: h, options: p.options}, nil
+	}
+	options, err := ownerOptions(owner, owner.Namespace, p.options)
+	if err != nil {
+		return clusterHost{}, err
+	}
+	c := clusterHost{h: h.withNamespace(owner.Namespace), options: options}
+	if p.clusters == nil {
+		p.clusters = map[string]clusterHost{}
+	}
+	p.clusters[cluster] = c
+	return c, nil
+}
+
 // Provision should create and start the nodes, just short of
 // actually starting up Kubernetes, based on the given cluster config
-func (p *Provider) Provision(status *cli.Status, cluster string, cfg *config.Cluster) (err error) {
+func (p *Provider) Provision(ctx context.Context, status *cli.Status, cluster string, cfg *config.Cluster) (err error) {
+	c, err := p.clusterHost(cluster)
+	if err != nil {
+		return err
+	}
+	if err := c.options.validate(); err != nil {
+		return err
+	}
+	h := c.h
+	if c.options.NamespacePerCluster {
+		if err := ensureNamespace(h, cluster); err != nil {
+			return err
+		}
+	}
 
 	// actually provision the cluster
 	// TODO: strings.Repeat("📦", len(desiredNodes))
 	status.Start("Preparing nodes 📦")
 	defer func() { status.End(err == nil) }()
 
+	// nodes must be running before this deadline, returning on the first
+	// failure cancels waiting for the remaining nodes
+	ctx, cancel := context.WithTimeout(ctx, time.Minute*5)
+	defer cancel()
+
+	// every object of the cluster is owned by a single object, so that
+	// deleting it collects anything left behind
+	owner, err := ensureClusterOwner(h, cluster, cfg, c.options)
+	if err != nil {
+		return err
+	}
+	if c.options.PersistentStorage {
+		if err := ensureNodesService(h, cluster, owner); err != nil {
+			return err
+		}
+	}
+
 	// plan creating the containers
-	createContainerFuncs, err := planCreation(p.logger, cluster, cfg)
+	createContainerFuncs, err := planCreation(p.logger, h, c.options, owner, cluster, cfg)
 	if err != nil {
 		return err
 	}
 
-	// actually create nodes
-	return errors.UntilErrorConcurrent(createContainerFuncs)
+	// actually create nodes, the node images are pulled by the host cluster
+	// and the first failure cancels creating the others
+	return events.RunContext(ctx, "create-nodes", func() error {
+		return errors.UntilErrorConcurrentContext(ctx, createContainerFuncs)
+	})
+}
+
+// ProvisionNode creates and starts the single node name for an existing
+// cluster, just short of joining it to Kubernetes
+// The node is created with the options the cluster was created with
+func (p *Provider) ProvisionNode(ctx context.Context, status *cli.Status, cluster, name string, cfg *config.Cluster, node *config.Node) (err error) {
+	c, err := p.clusterHost(cluster)
+	if err != nil {
+		return err
+	}
+	h := c.h
+
+	status.Start(fmt.Sprintf("Preparing node %s 📦", name))
+	defer func() { status.End(err == nil) }()
+
+	ctx, cancel := context.WithTimeout(ctx, time.Minute*5)
+	defer cancel()
+
+	// this reuses the owner of the existing cluster
+	owner, err := ensureClusterOwner(h, cluster, cfg, c.options)
+	if err != nil {
+		return err
+	}
+	if c.options.PersistentStorage {
+		if err := ensureNodesService(h, cluster, owner); err != nil {
+			return err
+		}
+	}
+
+	// added control plane nodes are behind the external load balancer
+	createContainer, err := planNodeCreation(p.logger, h, c.options, owner, cluster, cfg, node, name, true)
+	if err != nil {
+		return err
+	}
+	return createContainer(ctx)
 }
 
 // ListClusters discovers the clusters that currently have resources
 // under this providers
+// Clusters are found in the host namespace, in their own labeled namespace
+// and by their owner in any other namespace the host credentials can list
 func (p *Provider) ListClusters() ([]string, error) {
-	cmd := exec.Command("kubectl",
-		"get",
-		"pod",
-		"-l", clusterLabelKey,
-		// format to include the cluster name
-		"-o", fmt.Sprintf(`jsonpath={.items[*]['metadata.labels.%s']}`, labelForJSONPath(clusterLabelKey)),
-	)
-	lines, err := exec.OutputLines(cmd)
+	h, err := p.host()
+	if err != nil {
+		return nil, err
+	}
+	clusters := sets.NewString()
+	// stopped clusters have StatefulSets without pods
+	selector := metav1.ListOptions{LabelSelector: clusterLabelKey}
+	pods, err := h.client.CoreV1().Pods(h.namespace).List(selector)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list clusters")
+	}
+	for _, pod := range pods.Items {
+		clusters.Insert(pod.Labels[clusterLabelKey])
+	}
+	statefulSets, err := h.client.AppsV1().StatefulSets(h.namespace).List(selector)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to list clusters")
 	}
-	return sets.NewString(lines...).List(), nil
+	for _, statefulSet := range statefulSets.Items {
+		clusters.Insert(statefulSet.Labels[clusterLabelKey])
+	}
+	// listing namespaces is only required of credentials creating them
+	namespaced, err := listNamespacedClusters(h)
+	if err != nil {
+		if p.options.NamespacePerCluster || !apierrors.IsForbidden(err) {
+			return nil, errors.Wrap(err, "failed to list clusters")
+		}
+		p.logger.V(1).Infof("Not listing clusters in their own namespace: %v", err)
+	}
+	clusters.Insert(namespaced...)
+	owners, err := listClusterOwners(h, clusterLabelKey)
+	if err != nil {
+		return nil, err
+	}
+	for _, owner := range owners {
+		clusters.Insert(owner.Labels[clusterLabelKey])
+	}
+	return clusters.List(), nil
 }
 
 // ListNodes returns the nodes under this provider for the given
 // cluster name, they may or may not be running correctly
 func (p *Provider) ListNodes(cluster string) ([]nodes.Node, error) {
-	cmd := exec.Command("kubectl",
-		"get", "pod",
-		"-l", fmt.Sprintf("%s=%s", clusterLabelKey, cluster),
-		// only name
-		"-o", "custom-columns=NAME:.metadata.name",
-		// remove header
-		"--no-headers",
-	)
-	lines, err := exec.OutputLines(cmd)
+	h, err := p.hostFor(cluster)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to list clusters")
+		return nil, err
+	}
+	selector := metav1.ListOptions{
+		LabelSelector: labels.Set{clusterLabelKey: cluster}.String(),
+	}
+	pods, err := h.client.CoreV1().Pods(h.namespace).List(selector)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list nodes")
 	}
 	// convert names to node handles
-	ret := make([]nodes.Node, 0, len(lines))
-	for _, name := range lines {
-		ret = append(ret, p.node(name))
+	ret := make([]nodes.Node, 0, len(pods.Items))
+	for _, pod := range pods.Items {
+		// pods of StatefulSets are named after the node with a suffix
+		name := pod.Name
+		if nodeName, ok := pod.Labels[nodeNameLabelKey]; ok {
+			name = nodeName
+		}
+		ret = append(ret, p.node(h, cluster, name, pod.Name))
+	}
+	// stopped nodes have a StatefulSet without a pod
+	statefulSets, err := h.client.AppsV1().StatefulSets(h.namespace).List(selector)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list nodes")
+	}
+	listed := sets.NewString()
+	for _, n := range ret {
+		listed.Insert(n.String())
+	}
+	for _, statefulSet := range statefulSets.Items {
+		if !listed.Has(statefulSet.Name) {
+			ret = append(ret, p.node(h, cluster, statefulSet.Name, statefulSetPodName(statefulSet.Name)))
+		}
 	}
 	return ret, nil
 }
@@ -91,79 +345,125 @@ func (p *Provider) ListNodes(cluster string) ([]nodes.Node, error) {
 // DeleteNodes deletes the provided list of nodes
 // These should be from results previously returned by this provider
 // E.G. by ListNodes()
-func (p *Provider) DeleteNodes(n []nodes.Node) error {
+func (p *Provider) DeleteNodes(ctx context.Context, n []nodes.Node) error {
 	if len(n) == 0 {
 		return nil
 	}
-	const command = "kubectl"
-	args := make([]string, 0, len(n)+3) // allocate once
-	args = append(args,
-		"delete", "pod",
-		"--grace-period=1", // force the container to be delete now
-		"--wait=false",     //do not wait pods to be really deleted
-	)
-	for _, node := range n {
-		args = append(args, node.String())
+	h, err := p.host()
+	if err != nil {
+		return err
+	}
+	// group the nodes by namespace
+	namespaces := []string{}
+	byNamespace := map[string][]*node{}
+	for _, nodeHandle := range n {
+		kn := asNode(h, nodeHandle)
+		namespace := kn.host.namespace
+		if _, seen := byNamespace[namespace]; !seen {
+			namespaces = append(namespaces, namespace)
+		}
+		byNamespace[namespace] = append(byNamespace[namespace], kn)
 	}
-	if err := exec.Command(command, args...).Run(); err != nil {
-		return errors.Wrap(err, "failed to delete pods")
+	for _, namespace := range namespaces {
+		// the API calls are not cancellable, so ctx is only checked here
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		scoped := h.withNamespace(namespace)
+		if err := deleteNodes(scoped, byNamespace[namespace]); err != nil {
+			return err
+		}
+		// once the last node of a cluster is gone delete the cluster owner,
+		// which garbage collects any remaining objects of the cluster
+		clusters := sets.NewString()
+		for _, n := range byNamespace[namespace] {
+			if n.cluster != "" {
+				clusters.Insert(n.cluster)
+			}
+		}
+		for _, cluster := range clusters.List() {
+			if err := deleteClusterOwnerIfEmpty(scoped, cluster); err != nil {
+				return err
+			}
+			if namespace != clusterNamespace(cluster) {
+				continue
+			}
+			if err := deleteNamespaceIfEmpty(h, namespace, cluster); err != nil {
+				return err
+			}
+		}
 	}
+	return nil
+}
 
-	// delete services
-	args = args[:]
-	args = append(args,
-		"delete", "svc",
-	)
-	for _, node := range n {
-		args = append(args, node.String())
+// deleteNodes deletes the pods, services, StatefulSets and claims of the nodes
+func deleteNodes(h *host, toDelete []*node) error {
+	// delete StatefulSets and claims first so the pods are not recreated
+	for _, n := range toDelete {
+		if err := deleteNodeStorage(h, n.name); err != nil {
+			return err
+		}
+	}
+	// force the pods to be deleted now, without waiting for them to go away
+	gracePeriod := int64(1)
+	pods := h.client.CoreV1().Pods(h.namespace)
+	for _, n := range toDelete {
+		err := pods.Delete(n.pod, &metav1.DeleteOptions{
+			GracePeriodSeconds: &gracePeriod,
+		})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete pod %s", n.pod)
+		}
 	}
-	if err := exec.Command(command, args...).Run(); err != nil {
-		return errors.Wrap(err, "failed to delete services")
+	// delete services
+	services := h.client.CoreV1().Services(h.namespace)
+	for _, n := range toDelete {
+		err := services.Delete(n.name, &metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete service %s", n.name)
+		}
 	}
 	return nil
 }
 
 // GetAPIServerEndpoint returns the host endpoint for the cluster's API server
 func (p *Provider) GetAPIServerEndpoint(cluster string) (string, error) {
+	h, err := p.hostFor(cluster)
+	if err != nil {
+		return "", err
+	}
 	// locate the node that hosts this
-	// allNodes, err := p.ListNodes(cluster)
-	// if err != nil {
-	// 	return "", errors.Wrap(err, "failed to list nodes")
-	// }
-	// n, err := nodeutils.APIServerEndpointNode(allNodes)
-	// if err != nil {
-	// 	return "", errors.Wrap(err, "failed to get cluster node")
-	// }
-
-	// kubectl get cluster master ip
-	cmd := exec.Command("kubectl", "cluster-info")
-	lines, err := exec.OutputLines(cmd)
+	allNodes, err := p.ListNodes(cluster)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to list nodes")
+	}
+	n, err := nodeutils.APIServerEndpointNode(allNodes)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to get api server endpoint")
 	}
-	if len(lines) == 0 || !strings.Contains(lines[0], "Kubernetes master is running at ") {
-		return "", errors.Wrap(err, "failed to get api server endpoint from cluster-info")
+	// the node's Service exposes the API server
+	endpoint, err := serviceEndpoint(h, n.String())
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get api server endpoint")
 	}
-	masterAddress := strings.ReplaceAll(lines[0], "Kubernetes master is running at ", "")
-	// cmd = exec.Command("kubectl",
-	// 	"get", "svc",
-	// 	n.String(),
-	// 	"-o", "jsonpath={.spec.ports[*]['nodePort']}",
-	// )
-	// lines, err := exec.OutputLines(cmd)
-	// if err != nil {
-	// 	return "", errors.Wrap(err, "failed to get api server endpoint")
-	// }
-	// if len(lines) != 1 {
-	// 	return "", errors.Wrap(err, "failed to get api server service endpoint port")
-	// }
+	return endpoint, nil
+}
 
-	return masterAddress, nil
+// asNode returns the node handle n was created from, or a handle for a
+// node named after n in the namespace of h
+func asNode(h *host, n nodes.Node) *node {
+	if kn, ok := n.(*node); ok {
+		return kn
+	}
+	return &node{name: n.String(), pod: n.String(), host: h}
 }
 
 // node returns a new node handle for this provider
-func (p *Provider) node(name string) nodes.Node {
+func (p *Provider) node(h *host, cluster, name, pod string) nodes.Node {
 	return &node{
-		name: name,
+		cluster: cluster,
+		name:    name,
+		pod:     pod,
+		host:    h,
 	}
 }
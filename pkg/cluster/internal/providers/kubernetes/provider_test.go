@@ -0,0 +1,370 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+// newTestProvider returns a Provider backed by a fake clientset containing objects
+func newTestProvider(objects ...runtime.Object) (*Provider, *host) {
+	h := &host{
+		client:    fake.NewSimpleClientset(objects...),
+		namespace: "default",
+	}
+	return &Provider{
+		logger: log.NoopLogger{},
+		h:      h,
+	}, h
+}
+
+func nodePod(name, cluster, role, ip string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels: map[string]string{
+				clusterLabelKey:  cluster,
+				nodeRoleLabelKey: role,
+				nodeNameLabelKey: name,
+			},
+		},
+		Status: corev1.PodStatus{
+			PodIP: ip,
+		},
+	}
+}
+
+func nodeNames(n []nodes.Node) []string {
+	names := []string{}
+	for _, node := range n {
+		names = append(names, node.String())
+	}
+	return names
+}
+
+func TestListClusters(t *testing.T) {
+	t.Parallel()
+	p, _ := newTestProvider(
+		nodePod("kind-control-plane", "kind", "control-plane", ""),
+		nodePod("kind-worker", "kind", "worker", ""),
+		nodePod("other-control-plane", "other", "control-plane", ""),
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: "default"}},
+	)
+	clusters, err := p.ListClusters()
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, []string{"kind", "other"}, clusters)
+}
+
+func TestListNodes(t *testing.T) {
+	t.Parallel()
+	p, _ := newTestProvider(
+		nodePod("kind-control-plane", "kind", "control-plane", ""),
+		nodePod("kind-worker", "kind", "worker", ""),
+		nodePod("other-control-plane", "other", "control-plane", ""),
+	)
+	n, err := p.ListNodes("kind")
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, []string{"kind-control-plane", "kind-worker"}, nodeNames(n))
+}
+
+func TestDeleteNodes(t *testing.T) {
+	t.Parallel()
+	p, h := newTestProvider(
+		nodePod("kind-control-plane", "kind", "control-plane", ""),
+		nodePod("kind-worker", "kind", "worker", ""),
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "kind-control-plane", Namespace: "default"}},
+	)
+	n, err := p.ListNodes("kind")
+	assert.ExpectError(t, false, err)
+	assert.ExpectError(t, false, p.DeleteNodes(context.Background(), n))
+	pods, err := h.client.CoreV1().Pods(h.namespace).List(metav1.ListOptions{})
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, 0, len(pods.Items))
+	services, err := h.client.CoreV1().Services(h.namespace).List(metav1.ListOptions{})
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, 0, len(services.Items))
+}
+
+func TestNodeRoleAndIP(t *testing.T) {
+	t.Parallel()
+	p, h := newTestProvider(
+		nodePod("kind-control-plane", "kind", "control-plane", "10.0.0.2"),
+		nodePod("kind-worker", "kind", "worker", ""),
+	)
+	controlPlane := p.node(h, "kind", "kind-control-plane", "kind-control-plane")
+	role, err := controlPlane.Role()
+	assert.ExpectError(t, false, err)
+	assert.StringEqual(t, "control-plane", role)
+	ipv4, ipv6, err := controlPlane.IP()
+	assert.ExpectError(t, false, err)
+	assert.StringEqual(t, "10.0.0.2", ipv4)
+	assert.StringEqual(t, "", ipv6)
+
+	// a pod that has not been scheduled yet has no IP
+	_, _, err = p.node(h, "kind", "kind-worker", "kind-worker").IP()
+	assert.ExpectError(t, true, err)
+
+	// missing pods are an error
+	_, err = p.node(h, "kind", "missing", "missing").Role()
+	assert.ExpectError(t, true, err)
+}
+
+func TestNamespacePerCluster(t *testing.T) {
+	t.Parallel()
+	controlPlane := nodePod("kind-control-plane", "kind", "control-plane", "")
+	controlPlane.Namespace = clusterNamespace("kind")
+	worker := nodePod("kind-worker", "kind", "worker", "")
+	worker.Namespace = clusterNamespace("kind")
+	p, h := newTestProvider(controlPlane, worker)
+	p.options.NamespacePerCluster = true
+	scoped, err := p.hostFor("kind")
+	assert.ExpectError(t, false, err)
+	assert.ExpectError(t, false, ensureNamespace(scoped, "kind"))
+
+	clusters, err := p.ListClusters()
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, []string{"kind"}, clusters)
+
+	n, err := p.ListNodes("kind")
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, []string{"kind-control-plane", "kind-worker"}, nodeNames(n))
+
+	// deleting some of the nodes keeps the namespace
+	assert.ExpectError(t, false, p.DeleteNodes(context.Background(), n[1:]))
+	_, err = h.client.CoreV1().Namespaces().Get(clusterNamespace("kind"), metav1.GetOptions{})
+	assert.ExpectError(t, false, err)
+
+	// deleting the last node removes it
+	assert.ExpectError(t, false, p.DeleteNodes(context.Background(), n[:1]))
+	_, err = h.client.CoreV1().Namespaces().Get(clusterNamespace("kind"), metav1.GetOptions{})
+	assert.ExpectError(t, true, err)
+}
+
+func TestPersistentStorage(t *testing.T) {
+	t.Parallel()
+	controlPlane := nodePod(statefulSetPodName("kind-control-plane"), "kind", "control-plane", "10.0.0.2")
+	controlPlane.Labels[nodeNameLabelKey] = "kind-control-plane"
+	p, h := newTestProvider(
+		controlPlane,
+		&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "kind-control-plane", Namespace: "default"}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+			Name:      "dind-storage-kind-control-plane-0",
+			Namespace: "default",
+			Labels:    map[string]string{nodeNameLabelKey: "kind-control-plane"},
+		}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: "default"}},
+	)
+	p.options.PersistentStorage = true
+
+	// nodes are named after the StatefulSet, not the pod
+	n, err := p.ListNodes("kind")
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, []string{"kind-control-plane"}, nodeNames(n))
+	ipv4, _, err := n[0].IP()
+	assert.ExpectError(t, false, err)
+	assert.StringEqual(t, "10.0.0.2", ipv4)
+
+	assert.ExpectError(t, false, p.DeleteNodes(context.Background(), n))
+	statefulSets, err := h.client.AppsV1().StatefulSets(h.namespace).List(metav1.ListOptions{})
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, 0, len(statefulSets.Items))
+	pods, err := h.client.CoreV1().Pods(h.namespace).List(metav1.ListOptions{})
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, 0, len(pods.Items))
+	claims, err := h.client.CoreV1().PersistentVolumeClaims(h.namespace).List(metav1.ListOptions{})
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, 1, len(claims.Items))
+	assert.StringEqual(t, "unrelated", claims.Items[0].Name)
+}
+
+func TestClusterOwner(t *testing.T) {
+	t.Parallel()
+	p, h := newTestProvider(
+		nodePod("kind-control-plane", "kind", "control-plane", ""),
+		nodePod("kind-worker", "kind", "worker", ""),
+	)
+	owner, err := ensureClusterOwner(h, "kind", &config.Cluster{}, p.options)
+	assert.ExpectError(t, false, err)
+	assert.StringEqual(t, "ConfigMap", owner.Kind)
+	assert.StringEqual(t, clusterOwnerName("kind"), owner.Name)
+
+	// ensuring the owner again reuses it
+	again, err := ensureClusterOwner(h, "kind", &config.Cluster{}, p.options)
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, owner, again)
+
+	n, err := p.ListNodes("kind")
+	assert.ExpectError(t, false, err)
+
+	// deleting some of the nodes keeps the owner
+	assert.ExpectError(t, false, p.DeleteNodes(context.Background(), n[1:]))
+	_, err = h.client.CoreV1().ConfigMaps(h.namespace).Get(owner.Name, metav1.GetOptions{})
+	assert.ExpectError(t, false, err)
+
+	// deleting the last node removes it
+	assert.ExpectError(t, false, p.DeleteNodes(context.Background(), n[:1]))
+	_, err = h.client.CoreV1().ConfigMaps(h.namespace).Get(owner.Name, metav1.GetOptions{})
+	assert.ExpectError(t, true, err)
+}
+
+func TestStopNodes(t *testing.T) {
+	t.Parallel()
+	controlPlane := nodePod(statefulSetPodName("kind-control-plane"), "kind", "control-plane", "10.0.0.2")
+	controlPlane.Labels[nodeNameLabelKey] = "kind-control-plane"
+	replicas := int32(1)
+	p, h := newTestProvider(
+		controlPlane,
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "kind-control-plane",
+				Namespace: "default",
+				Labels:    map[string]string{clusterLabelKey: "kind", nodeNameLabelKey: "kind-control-plane"},
+			},
+			Spec: appsv1.StatefulSetSpec{
+				Replicas: &replicas,
+				Template: corev1.PodTemplateSpec{ObjectMeta: controlPlane.ObjectMeta},
+			},
+		},
+		nodePod("kind-external-load-balancer", "kind", "external-load-balancer", "10.0.0.3"),
+	)
+	p.options.PersistentStorage = true
+	n, err := p.ListNodes("kind")
+	assert.ExpectError(t, false, err)
+	assert.ExpectError(t, false, p.StopNodes(context.Background(), n))
+
+	// the node is scaled down and the load balancer is left running
+	statefulSet, err := h.client.AppsV1().StatefulSets(h.namespace).Get("kind-control-plane", metav1.GetOptions{})
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, int32(0), *statefulSet.Spec.Replicas)
+	_, err = h.client.CoreV1().Pods(h.namespace).Get("kind-external-load-balancer", metav1.GetOptions{})
+	assert.ExpectError(t, false, err)
+
+	// stopped nodes and clusters are still listed, with their role
+	clusters, err := p.ListClusters()
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, []string{"kind"}, clusters)
+	n, err = p.ListNodes("kind")
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, []string{"kind-external-load-balancer", "kind-control-plane"}, nodeNames(n))
+	role, err := n[1].Role()
+	assert.ExpectError(t, false, err)
+	assert.StringEqual(t, "control-plane", role)
+
+	// nodes without persistent storage cannot be stopped
+	p, _ = newTestProvider(nodePod("kind-control-plane", "kind", "control-plane", ""))
+	n, err = p.ListNodes("kind")
+	assert.ExpectError(t, false, err)
+	assert.ExpectError(t, true, p.StopNodes(context.Background(), n))
+}
+
+func TestDeleteStoppedNode(t *testing.T) {
+	t.Parallel()
+	p, h := newTestProvider()
+	p.options.PersistentStorage = true
+	cfg := &config.Cluster{}
+	owner, err := ensureClusterOwner(h, "kind", cfg, p.options)
+	assert.ExpectError(t, false, err)
+	for _, name := range []string{"kind-control-plane", "kind-worker"} {
+		node := &config.Node{Role: config.WorkerRole}
+		assert.ExpectError(t, false, createStatefulSetForNode(p.logger, h, node, name, "kind", p.options, owner))
+	}
+	n, err := p.ListNodes("kind")
+	assert.ExpectError(t, false, err)
+	assert.ExpectError(t, false, p.StopNodes(context.Background(), n))
+
+	// deleting one node of the stopped cluster keeps the other node and the
+	// cluster owner, which would garbage collect it
+	n, err = p.ListNodes("kind")
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, []string{"kind-control-plane", "kind-worker"}, nodeNames(n))
+	assert.ExpectError(t, false, p.DeleteNodes(context.Background(), n[1:]))
+	_, err = h.client.CoreV1().ConfigMaps(h.namespace).Get(owner.Name, metav1.GetOptions{})
+	assert.ExpectError(t, false, err)
+	n, err = p.ListNodes("kind")
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, []string{"kind-control-plane"}, nodeNames(n))
+}
+
+func TestClusterHostFromOwner(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name      string
+		Namespace string
+		Options   Options
+	}{
+		{
+			Name:      "namespace per cluster",
+			Namespace: clusterNamespace("kind"),
+			Options:   Options{NamespacePerCluster: true, PersistentStorage: true, StorageSize: "5Gi"},
+		},
+		{
+			Name:      "explicit namespace",
+			Namespace: "other",
+			Options:   Options{Namespace: "other", ServiceType: "LoadBalancer"},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			// the cluster was created with options this provider is not given
+			controlPlane := nodePod("kind-control-plane", "kind", "control-plane", "")
+			controlPlane.Namespace = tc.Namespace
+			p, h := newTestProvider(controlPlane)
+			created := h.withNamespace(tc.Namespace)
+			if tc.Options.NamespacePerCluster {
+				assert.ExpectError(t, false, ensureNamespace(created, "kind"))
+			}
+			_, err := ensureClusterOwner(created, "kind", &config.Cluster{}, tc.Options)
+			assert.ExpectError(t, false, err)
+
+			clusters, err := p.ListClusters()
+			assert.ExpectError(t, false, err)
+			assert.DeepEqual(t, []string{"kind"}, clusters)
+			c, err := p.clusterHost("kind")
+			assert.ExpectError(t, false, err)
+			assert.StringEqual(t, tc.Namespace, c.h.namespace)
+			expected := tc.Options
+			expected.Namespace = tc.Namespace
+			assert.DeepEqual(t, expected, c.options)
+
+			n, err := p.ListNodes("kind")
+			assert.ExpectError(t, false, err)
+			assert.DeepEqual(t, []string{"kind-control-plane"}, nodeNames(n))
+			assert.ExpectError(t, false, p.DeleteNodes(context.Background(), n))
+			_, err = h.client.CoreV1().ConfigMaps(tc.Namespace).Get(clusterOwnerName("kind"), metav1.GetOptions{})
+			assert.ExpectError(t, true, err)
+			if tc.Options.NamespacePerCluster {
+				_, err = h.client.CoreV1().Namespaces().Get(tc.Namespace, metav1.GetOptions{})
+				assert.ExpectError(t, true, err)
+			}
+		})
+	}
+}
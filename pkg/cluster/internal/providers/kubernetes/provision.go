@@ -1,17 +1,16 @@
 package kubernetes
 
 import (
-	"bytes"
 	"context"
-	"fmt"
-	"io/ioutil"
-	"os"
-	"os/exec"
 	"path/filepath"
-	"strings"
-	"text/template"
-	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/internal/patch"
 	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/internal/apis/config"
@@ -19,241 +18,295 @@ import (
 )
 
 // planCreation creates a slice of funcs that will create the containers
-func planCreation(logger log.Logger, cluster string, cfg *config.Cluster) (createContainerFuncs []func() error, err error) {
+func planCreation(logger log.Logger, h *host, opts Options, owner metav1.OwnerReference, cluster string, cfg *config.Cluster) (createContainerFuncs []func(context.Context) error, err error) {
 	// these apply to all container creation
 	nodeNamer := common.MakeNodeNamer(cluster)
-	// only the external LB should reflect the port if we have multiple control planes
-	// apiServerPort := cfg.Networking.APIServerPort
-	// apiServerAddress := cfg.Networking.APIServerAddress
-
-	// load balancer? if it is a kubernetes a service should suffice?
-	// skip load balancer stuff
-
-	//  create context with deadline
-	ctx, _ := context.WithDeadline(context.TODO(), time.Now().Add(time.Minute*5))
-	// plan normal nodes
-	for _, node := range cfg.Nodes {
-		node := node.DeepCopy()              // copy so we can modify
-		name := nodeNamer(string(node.Role)) // name the node
 
-		// fixup relative paths, docker can only handle absolute paths
-		for i := range node.ExtraMounts {
-			hostPath := node.ExtraMounts[i].HostPath
-			absHostPath, err := filepath.Abs(hostPath)
-			if err != nil {
-				return nil, errors.Wrapf(err, "unable to resolve absolute path for hostPath: %q", hostPath)
+	// only the external LB should expose the API server if we have multiple
+	// control planes
+	loadBalancer := clusterHasImplicitLoadBalancer(cfg)
+	if loadBalancer {
+		// plan loadbalancer node
+		name := nodeNamer(constants.ExternalLoadBalancerNodeRoleValue)
+		createContainerFuncs = append(createContainerFuncs, func(ctx context.Context) error {
+			if err := createLoadBalancerPod(logger, h, name, cluster, cfg.ProviderPatches, owner); err != nil {
+				return err
 			}
-			node.ExtraMounts[i].HostPath = absHostPath
-		}
+			ports := []corev1.ServicePort{apiServerServicePort()}
+			if err := createServiceForNode(h, name, cluster, opts.serviceType(), ports, owner); err != nil {
+				return err
+			}
+			if err := waitForPodReady(ctx, logger, h, name); err != nil {
+				return err
+			}
+			return waitForServiceEndpoint(ctx, h, name)
+		})
+	}
 
-		switch node.Role {
-		case config.ControlPlaneRole:
-			createContainerFuncs = append(createContainerFuncs, func() error {
-				// getPodTemplate(node, name, cluster)
-				node.ExtraPortMappings = append(node.ExtraPortMappings,
-					config.PortMapping{
-						ListenAddress: "0.0.0.0",
-						HostPort:      common.APIServerInternalPort,
-						ContainerPort: common.APIServerInternalPort,
-					},
-				)
-				if err := createPodForNode(logger, node, name, cluster); err != nil {
-					return err
-				}
-				return waitUntilRead(logger, ctx, node, name, cluster)
-			})
-		case config.WorkerRole:
-			createContainerFuncs = append(createContainerFuncs, func() error {
-				// getPodTemplate(node, name, cluster)
-				if err := createPodForNode(logger, node, name, cluster); err != nil {
-					return err
-				}
-				return waitUntilRead(logger, ctx, node, name, cluster)
-			})
-		default:
-			return nil, errors.Errorf("unknown node role: %q", node.Role)
+	// plan normal nodes
+	for i := range cfg.Nodes {
+		node := &cfg.Nodes[i]
+		createContainerFunc, err := planNodeCreation(logger, h, opts, owner, cluster, cfg, node, nodeNamer(string(node.Role)), loadBalancer)
+		if err != nil {
+			return nil, err
 		}
+		createContainerFuncs = append(createContainerFuncs, createContainerFunc)
 	}
 	return
 }
 
-// func createPod(command string) error {
-// 	if err := exec.Command(command).Run(); err != nil {
-// 		return errors.Wrap(err, "kubectl apply error")
-// 	}
-// 	return nil
-// }
-
-func waitUntilRead(logger log.Logger, ctx context.Context, node *config.Node, name, cluster string) error {
-
-	deadline, ok := ctx.Deadline()
-	if !ok {
-		deadline = time.Now().Add(time.Minute * 30)
+// planNodeCreation returns a func creating the pod or StatefulSet for node
+// with name, exposing the API server of control plane nodes unless the
+// cluster has a loadBalancer
+func planNodeCreation(logger log.Logger, h *host, opts Options, owner metav1.OwnerReference, cluster string, cfg *config.Cluster, node *config.Node, name string, loadBalancer bool) (func(context.Context) error, error) {
+	node, err := prepareNode(cfg, node)
+	if err != nil {
+		return nil, err
 	}
-	logger.V(2).Infof("deadline for node %s is %v", name, deadline)
-	done := make(chan error)
-	deadlineTimer := time.NewTimer(deadline.Sub(time.Now()))
-	everySecond := time.Tick(time.Second)
-	go func() {
-		stillRunning := 0
-		targetRunningTimes := 10
-		for range everySecond {
-			output, err := exec.Command("kubectl", "get", "pod", name, "--no-headers").Output()
-			if err != nil {
-				done <- err
-				return
+
+	switch node.Role {
+	case config.ControlPlaneRole:
+		return func(ctx context.Context) error {
+			if err := createNode(logger, h, node, name, cluster, opts, owner); err != nil {
+				return err
+			}
+			// expose the API server and port mappings outside of the host cluster
+			ports := servicePortsForNode(node, opts.serviceType(), !loadBalancer)
+			if err := createServiceForNode(h, name, cluster, opts.serviceType(), ports, owner); err != nil {
+				return err
+			}
+			if err := waitForPodReady(ctx, logger, h, podNameForNode(name, opts)); err != nil {
+				return err
+			}
+			if loadBalancer {
+				return nil
 			}
-			logger.V(2).Infof("pod %s status %s", name, output)
-			columns := strings.Fields(string(output))
-			if len(columns) < 3 {
-				done <- errors.Errorf("pod %s status returned unexpected result: %s", name, output)
-				return
+			return waitForServiceEndpoint(ctx, h, name)
+		}, nil
+	case config.WorkerRole:
+		return func(ctx context.Context) error {
+			if err := createNode(logger, h, node, name, cluster, opts, owner); err != nil {
+				return err
 			}
-			status := columns[2]
-			switch status {
-			case "Running":
-				stillRunning++
-				if stillRunning >= targetRunningTimes {
-					done <- nil
-					return
-				}
-				// break
-			case "CrashLoopBackOff", "ImagePullBackOff":
-				done <- errors.Errorf("pod %s is crashing or with invalid status: %s", name, status)
-				return
-			default:
-				// I a transitional state, needs to reset the
-				stillRunning = 0
+			// expose the port mappings outside of the host cluster
+			ports := servicePortsForNode(node, opts.serviceType(), false)
+			if err := createServiceForNode(h, name, cluster, opts.serviceType(), ports, owner); err != nil {
+				return err
 			}
+			return waitForPodReady(ctx, logger, h, podNameForNode(name, opts))
+		}, nil
+	default:
+		return nil, errors.Errorf("unknown node role: %q", node.Role)
+	}
+}
+
+// prepareNode returns a copy of node with the cluster-level provider
+// patches and absolute mount paths
+func prepareNode(cfg *config.Cluster, node *config.Node) (*config.Node, error) {
+	node = node.DeepCopy() // copy so we can modify
+
+	// cluster-level provider patches apply before the node-level ones
+	node.ProviderPatches = append(append([]string{}, cfg.ProviderPatches...), node.ProviderPatches...)
+
+	// fixup relative paths, docker can only handle absolute paths
+	for i := range node.ExtraMounts {
+		hostPath := node.ExtraMounts[i].HostPath
+		absHostPath, err := filepath.Abs(hostPath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to resolve absolute path for hostPath: %q", hostPath)
 		}
-	}()
-	select {
-	case <-deadlineTimer.C:
-		return errors.Errorf("waiting pod %s reached deadline: %s", name, deadline)
-	case err := <-done:
+		node.ExtraMounts[i].HostPath = absHostPath
+	}
+	return node, nil
+}
+
+// createNode creates the pod or StatefulSet implementing node
+func createNode(logger log.Logger, h *host, node *config.Node, name, cluster string, opts Options, owner metav1.OwnerReference) error {
+	if opts.PersistentStorage {
+		return createStatefulSetForNode(logger, h, node, name, cluster, opts, owner)
+	}
+	return createPodForNode(logger, h, node, name, cluster, owner)
+}
+
+// podNameForNode returns the name of the pod implementing the node name
+func podNameForNode(name string, opts Options) string {
+	if opts.PersistentStorage {
+		return statefulSetPodName(name)
+	}
+	return name
+}
+
+func createPodForNode(logger log.Logger, h *host, node *config.Node, name, cluster string, owner metav1.OwnerReference) error {
+	pod, err := ownedPodForNode(node, name, cluster, owner)
+	if err != nil {
 		return err
 	}
+	if manifest, err := yaml.Marshal(pod); err == nil {
+		logger.V(2).Infof("pod manifest for %s\n%s", name, manifest)
+	}
+	if _, err := h.client.CoreV1().Pods(h.namespace).Create(pod); err != nil {
+		return errors.Wrapf(err, "failed to create pod %s", name)
+	}
+	return nil
+}
+
+// ownedPodForNode returns the pod implementing node with its provider
+// patches applied, owned by owner
+func ownedPodForNode(node *config.Node, name, cluster string, owner metav1.OwnerReference) (*corev1.Pod, error) {
+	pod, err := podForNode(node, name, cluster)
+	if err != nil {
+		return nil, err
+	}
+	pod, err = patchPod(pod, node.ProviderPatches)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to apply provider patches to pod %s", name)
+	}
+	pod.OwnerReferences = append(pod.OwnerReferences, owner)
+	return pod, nil
 }
 
-func createPodForNode(logger log.Logger, node *config.Node, name, cluster string) (err error) {
-	tem, _ := podTemplateInst.Parse(podTemplate)
-	writer := &bytes.Buffer{}
-	if err = tem.Execute(writer, map[string]interface{}{
-		"name": name,
-		"labels": map[string]string{
-			clusterLabelKey:  cluster,
-			nodeRoleLabelKey: string(node.Role),
+// podForNode returns the pod implementing node
+func podForNode(node *config.Node, name, cluster string) (*corev1.Pod, error) {
+	privileged := true
+	automountServiceAccountToken := false
+	resources, err := resourceRequirements(node.Resources)
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Pod",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				clusterLabelKey:  cluster,
+				nodeRoleLabelKey: string(node.Role),
+				nodeNameLabelKey: name,
+			},
+		},
+		Spec: corev1.PodSpec{
+			Hostname:                     name,
+			AutomountServiceAccountToken: &automountServiceAccountToken,
+			// seed the /var and /run volumes from the image
+			InitContainers: []corev1.Container{
+				{
+					Name:    "initvolume",
+					Image:   node.Image,
+					Command: []string{"/bin/bash"},
+					Args:    []string{"-c", "ls -lha /var && cp -R /var/* /storage &&  cp -R /run/* /runstg "},
+					VolumeMounts: []corev1.VolumeMount{
+						{Name: "dind-storage", MountPath: "/storage"},
+						{Name: "tmp", MountPath: "/tmpstg"},
+						{Name: "run-folder", MountPath: "/runstg"},
+					},
+				},
+			},
+			Containers: []corev1.Container{
+				{
+					Name:  name,
+					Image: node.Image,
+					SecurityContext: &corev1.SecurityContext{
+						Privileged: &privileged,
+					},
+					Resources: resources,
+					VolumeMounts: []corev1.VolumeMount{
+						{Name: "modules", MountPath: "/lib/modules", ReadOnly: true},
+						{Name: "dind-storage", MountPath: "/var"},
+						{Name: "run-folder", MountPath: "/run"},
+						{Name: "tmp", MountPath: "/tmp"},
+					},
+				},
+			},
+			Volumes: []corev1.Volume{
+				hostPathVolume("modules", "/lib/modules"),
+				hostPathVolume("cgroup", "/sys/fs/cgroup"),
+				emptyDirVolume("dind-storage"),
+				emptyDirVolume("run-folder"),
+				emptyDirVolume("tmp"),
+			},
 		},
-		"image":   node.Image,
-		"volumes": node.ExtraMounts,
-		"ports":   node.ExtraPortMappings,
-	}); err != nil {
-		return
+	}, nil
+}
+
+// resourceRequirements converts the node resources to container resources,
+// unset quantities are left unconstrained
+func resourceRequirements(resources config.Resources) (corev1.ResourceRequirements, error) {
+	requirements := corev1.ResourceRequirements{}
+	quantities := []struct {
+		list  *corev1.ResourceList
+		name  corev1.ResourceName
+		value string
+	}{
+		{&requirements.Requests, corev1.ResourceCPU, resources.Requests.CPU},
+		{&requirements.Requests, corev1.ResourceMemory, resources.Requests.Memory},
+		{&requirements.Limits, corev1.ResourceCPU, resources.Limits.CPU},
+		{&requirements.Limits, corev1.ResourceMemory, resources.Limits.Memory},
 	}
-	fileName := fmt.Sprintf("%s-%s.yaml", cluster, name)
-	logger.V(2).Infof("filename %s", fileName)
-	logger.V(2).Infof("file content\n%s", writer.String())
-	if err = ioutil.WriteFile(fileName, writer.Bytes(), os.ModePerm); err != nil {
-		err = errors.Wrap(err, "write temporary file error")
-		return
+	for _, q := range quantities {
+		if q.value == "" {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(q.value)
+		if err != nil {
+			return requirements, errors.Wrapf(err, "invalid %s quantity %q", q.name, q.value)
+		}
+		if *q.list == nil {
+			*q.list = corev1.ResourceList{}
+		}
+		(*q.list)[q.name] = quantity
 	}
-	defer os.Remove(fileName)
+	return requirements, nil
+}
 
-	if err = exec.Command("kubectl", "apply", "-f", fileName).Run(); err != nil {
-		err = errors.Wrap(err, "kubectl apply error")
+// patchPod applies the merge patches to pod, returning the patched pod
+func patchPod(pod *corev1.Pod, patches []string) (*corev1.Pod, error) {
+	if len(patches) == 0 {
+		return pod, nil
 	}
-	return
+	patched := &corev1.Pod{}
+	if err := patchObject(pod, patched, patches); err != nil {
+		return nil, err
+	}
+	return patched, nil
 }
 
-var podTemplateInst = template.New("podTemplate")
+// patchObject applies the merge patches to obj, decoding the result into
+// the empty object out
+func patchObject(obj, out interface{}, patches []string) error {
+	manifest, err := yaml.Marshal(obj)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode object")
+	}
+	patched, err := patch.KubeYAML(string(manifest), patches, nil)
+	if err != nil {
+		return err
+	}
+	if err := yaml.UnmarshalStrict([]byte(patched), out); err != nil {
+		return errors.Wrap(err, "failed to decode patched object")
+	}
+	return nil
+}
 
-const podTemplate = `kind: Pod
-apiVersion: v1
-metadata:
-    name: {{.name}}
-    labels:
-        {{- range $key, $val := .labels }}
-        {{$key}}: {{$val}}
-        {{- end }}
-spec:
-    #securityContext:
-    #    sysctls:
-    #    - name: net.ipv6.conf.all.disable_ipv6
-    #      value: "1"
-    #    - name: net.ipv6.conf.all.forwarding
-    #      value: "0"
-    hostname: {{.name}}
-    automountServiceAccountToken: false
-    initContainers:
-    - name: initvolume
-      image: {{.image}}
-      command:
-      - "/bin/bash"
-      args:
-      - "-c"
-      - "ls -lha /var && cp -R /var/* /storage &&  cp -R /run/* /runstg "
-      volumeMounts:
-      - name: dind-storage
-        mountPath: /storage
-      - name: tmp
-        mountPath: /tmpstg
-      - name: run-folder
-        mountPath: /runstg
-    containers:
-    - name: {{.name}}
-      image: {{.image}}
-      securityContext:
-          privileged: true
-      resources:
-          limits:
-             cpu: "1000m"
-             memory: "1024Mi"
-      volumeMounts:
-      - name: modules
-        mountPath: /lib/modules
-        readOnly: true
-      - name: dind-storage
-        mountPath: /var
-        #mountPath: /var/lib/docker
-      - name: run-folder
-        mountPath: /run
-      - name: tmp
-        mountPath: /tmp
-    #hostNetwork: true
-    #nodeSelector:
-    #  kubernetes.io/hostname: zxd-region-192.168.23.2
-    volumes:
-    - name: modules
-      hostPath:
-        path: /lib/modules
-        type: Directory
-    - name: cgroup
-      hostPath:
-        path: /sys/fs/cgroup
-        type: Directory
-    - name: dind-storage
-      emptyDir: {}
-    - name: run-folder
-      emptyDir: {}
-    - name: tmp
-      emptyDir: {}
----
-# kind: Service
-# apiVersion: v1
-# metadata:
-#    name: {{.name}}
-# spec:
-#    selector:
-#        {{- range $key, $val := .labels }}
-#        {{$key}}: {{$val}}
-#		{{- end }}
-#	{{- if .ports}}
-#    ports:
-#    {{- range $key, $val := .ports }}
-#    - protocol: TCP
-#      port: {{$val.ContainerPort}}
-#      targetPort: {{$val.ContainerPort}}
-#	{{- end }}
-#	{{- end }}
-#    type: NodePort
-`
+func hostPathVolume(name, path string) corev1.Volume {
+	hostPathType := corev1.HostPathDirectory
+	return corev1.Volume{
+		Name: name,
+		VolumeSource: corev1.VolumeSource{
+			HostPath: &corev1.HostPathVolumeSource{
+				Path: path,
+				Type: &hostPathType,
+			},
+		},
+	}
+}
+
+func emptyDirVolume(name string) corev1.Volume {
+	return corev1.Volume{
+		Name: name,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		},
+	}
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+func TestResourceRequirements(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name        string
+		Resources   config.Resources
+		Expected    corev1.ResourceRequirements
+		ExpectError bool
+	}{
+		{
+			Name:      "unset",
+			Resources: config.Resources{},
+			Expected:  corev1.ResourceRequirements{},
+		},
+		{
+			Name: "limits and requests",
+			Resources: config.Resources{
+				Requests: config.ResourceList{CPU: "500m", Memory: "2Gi"},
+				Limits:   config.ResourceList{Memory: "4Gi"},
+			},
+			Expected: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("500m"),
+					corev1.ResourceMemory: resource.MustParse("2Gi"),
+				},
+				Limits: corev1.ResourceList{
+					corev1.ResourceMemory: resource.MustParse("4Gi"),
+				},
+			},
+		},
+		{
+			Name: "invalid quantity",
+			Resources: config.Resources{
+				Limits: config.ResourceList{CPU: "two"},
+			},
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			result, err := resourceRequirements(tc.Resources)
+			assert.ExpectError(t, tc.ExpectError, err)
+			if !tc.ExpectError {
+				assert.DeepEqual(t, tc.Expected, result)
+			}
+		})
+	}
+}
+
+func TestPatchPod(t *testing.T) {
+	t.Parallel()
+	node := &config.Node{Role: config.WorkerRole, Image: "kindest/node:latest"}
+	pod, err := podForNode(node, "kind-worker", "kind")
+	assert.ExpectError(t, false, err)
+
+	// no patches is a no-op
+	unpatched, err := patchPod(pod, nil)
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, pod, unpatched)
+
+	patched, err := patchPod(pod, []string{
+		`kind: Pod
+spec:
+  nodeSelector:
+    disktype: ssd
+  runtimeClassName: kata`,
+		`kind: Pod
+apiVersion: v1
+spec:
+  nodeSelector:
+    zone: a
+  tolerations:
+  - key: dedicated
+    operator: Exists`,
+		// patches for other kinds are ignored
+		`kind: Service
+spec:
+  type: LoadBalancer`,
+	})
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, map[string]string{"disktype": "ssd", "zone": "a"}, patched.Spec.NodeSelector)
+	assert.StringEqual(t, "kata", *patched.Spec.RuntimeClassName)
+	assert.DeepEqual(t, []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists}}, patched.Spec.Tolerations)
+	assert.DeepEqual(t, pod.Spec.Containers, patched.Spec.Containers)
+
+	// unknown fields are rejected rather than silently dropped
+	_, err = patchPod(pod, []string{"kind: Pod\nspec:\n  nodeSelectr: {}"})
+	assert.ExpectError(t, true, err)
+}
+
+func TestStatefulSetForNode(t *testing.T) {
+	t.Parallel()
+	node := &config.Node{Role: config.WorkerRole, Image: "kindest/node:latest"}
+	pod, err := podForNode(node, "kind-worker", "kind")
+	assert.ExpectError(t, false, err)
+
+	statefulSet, err := statefulSetForNode(pod, "kind-worker", "kind", Options{StorageClass: "fast"})
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, int32(1), *statefulSet.Spec.Replicas)
+	assert.DeepEqual(t, pod.Labels, statefulSet.Spec.Template.Labels)
+	// the governing Service is headless, unlike the Services of the nodes
+	assert.StringEqual(t, nodesServiceName("kind"), statefulSet.Spec.ServiceName)
+	assert.StringEqual(t, corev1.ClusterIPNone, ownedNodesService("kind", metav1.OwnerReference{}).Spec.ClusterIP)
+	for _, volume := range statefulSet.Spec.Template.Spec.Volumes {
+		if volume.Name == storageVolumeName {
+			t.Errorf("expected %s to be replaced by a claim", storageVolumeName)
+		}
+	}
+	claims := statefulSet.Spec.VolumeClaimTemplates
+	assert.DeepEqual(t, 1, len(claims))
+	assert.StringEqual(t, storageVolumeName, claims[0].Name)
+	assert.StringEqual(t, "fast", *claims[0].Spec.StorageClassName)
+	assert.DeepEqual(t, resource.MustParse(defaultStorageSize), claims[0].Spec.Resources.Requests[corev1.ResourceStorage])
+
+	// the pod template is not modified
+	assert.DeepEqual(t, 5, len(pod.Spec.Volumes))
+
+	_, err = statefulSetForNode(pod, "kind-worker", "kind", Options{StorageSize: "lots"})
+	assert.ExpectError(t, true, err)
+}
+
+func TestLoadBalancerPod(t *testing.T) {
+	t.Parallel()
+	single := &config.Cluster{Nodes: []config.Node{
+		{Role: config.ControlPlaneRole},
+		{Role: config.WorkerRole},
+	}}
+	assert.DeepEqual(t, false, clusterHasImplicitLoadBalancer(single))
+	ha := &config.Cluster{Nodes: []config.Node{
+		{Role: config.ControlPlaneRole},
+		{Role: config.ControlPlaneRole},
+		{Role: config.WorkerRole},
+	}}
+	assert.DeepEqual(t, true, clusterHasImplicitLoadBalancer(ha))
+
+	// the load balancer is found by role like any other node
+	p, h := newTestProvider()
+	owner := metav1.OwnerReference{Kind: "ConfigMap", Name: clusterOwnerName("kind")}
+	assert.ExpectError(t, false, createLoadBalancerPod(log.NoopLogger{}, h, "kind-external-load-balancer", "kind", nil, owner))
+	n, err := p.ListNodes("kind")
+	assert.ExpectError(t, false, err)
+	lb, err := nodeutils.ExternalLoadBalancerNode(n)
+	assert.ExpectError(t, false, err)
+	assert.StringEqual(t, "kind-external-load-balancer", lb.String())
+}
@@ -0,0 +1,266 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// apiServerPortName is the name of the API server port on node Services
+const apiServerPortName = "apiserver"
+
+// servicePortsForNode returns the ports of node to expose outside of the
+// host cluster, the API server port is only exposed for the node hosting
+// the API server endpoint and named apiServerPortName
+func servicePortsForNode(node *config.Node, serviceType corev1.ServiceType, exposeAPIServer bool) []corev1.ServicePort {
+	ports := []corev1.ServicePort{}
+	if exposeAPIServer {
+		ports = append(ports, apiServerServicePort())
+	}
+	for _, pm := range node.ExtraPortMappings {
+		ports = append(ports, servicePortForMapping(pm, serviceType))
+	}
+	return ports
+}
+
+// apiServerServicePort returns the Service port for the API server
+func apiServerServicePort() corev1.ServicePort {
+	return corev1.ServicePort{
+		Name:       apiServerPortName,
+		Protocol:   corev1.ProtocolTCP,
+		Port:       common.APIServerInternalPort,
+		TargetPort: intstr.FromInt(common.APIServerInternalPort),
+	}
+}
+
+// serviceForNode returns the Service exposing ports of the node pod name
+// outside of the host cluster, or nil if there are no ports to expose
+func serviceForNode(name, cluster string, serviceType corev1.ServiceType, ports []corev1.ServicePort) *corev1.Service {
+	if len(ports) == 0 {
+		return nil
+	}
+	return &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Service",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				clusterLabelKey:  cluster,
+				nodeNameLabelKey: name,
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Type: serviceType,
+			Selector: map[string]string{
+				nodeNameLabelKey: name,
+			},
+			Ports: ports,
+		},
+	}
+}
+
+// ownedServiceForNode returns the Service for the node pod name owned by
+// owner, or nil if there are no ports to expose, see serviceForNode
+func ownedServiceForNode(name, cluster string, serviceType corev1.ServiceType, ports []corev1.ServicePort, owner metav1.OwnerReference) *corev1.Service {
+	svc := serviceForNode(name, cluster, serviceType, ports)
+	if svc == nil {
+		return nil
+	}
+	svc.OwnerReferences = append(svc.OwnerReferences, owner)
+	return svc
+}
+
+// servicePortForMapping converts a port mapping to a Service port
+// The HostPort is the port of LoadBalancers or the node port of NodePorts,
+// if unset a port is allocated
+func servicePortForMapping(pm config.PortMapping, serviceType corev1.ServiceType) corev1.ServicePort {
+	protocol := corev1.ProtocolTCP // TCP is the default
+	switch pm.Protocol {
+	case config.PortMappingProtocolUDP:
+		protocol = corev1.ProtocolUDP
+	case config.PortMappingProtocolSCTP:
+		protocol = corev1.ProtocolSCTP
+	default: // also covers config.PortMappingProtocolTCP
+	}
+	port := corev1.ServicePort{
+		Protocol:   protocol,
+		Port:       pm.ContainerPort,
+		TargetPort: intstr.FromInt(int(pm.ContainerPort)),
+	}
+	if pm.HostPort > 0 {
+		switch serviceType {
+		case corev1.ServiceTypeLoadBalancer:
+			port.Port = pm.HostPort
+		case corev1.ServiceTypeNodePort:
+			port.NodePort = pm.HostPort
+		}
+	}
+	port.Name = fmt.Sprintf("%s-%d", strings.ToLower(string(protocol)), port.Port)
+	return port
+}
+
+// createServiceForNode creates the Service for the node pod name, if any
+func createServiceForNode(h *host, name, cluster string, serviceType corev1.ServiceType, ports []corev1.ServicePort, owner metav1.OwnerReference) error {
+	svc := ownedServiceForNode(name, cluster, serviceType, ports, owner)
+	if svc == nil {
+		return nil
+	}
+	if _, err := h.client.CoreV1().Services(h.namespace).Create(svc); err != nil {
+		for _, port := range ports {
+			if port.NodePort != 0 {
+				return errors.Wrapf(err, "failed to create service %s, hostPort must be in the node port range of the host cluster", name)
+			}
+		}
+		return errors.Wrapf(err, "failed to create service %s", name)
+	}
+	return nil
+}
+
+// waitForServiceEndpoint waits until the API server Service for the node pod
+// name has a reachable address, which may take a while for LoadBalancers
+func waitForServiceEndpoint(ctx context.Context, h *host, name string) error {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		_, err := serviceEndpoint(h, name)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(err, "timed out waiting for service %s", name)
+		case <-ticker.C:
+		}
+	}
+}
+
+// serviceEndpoint returns the host:port on which the API server Service for
+// the node name can be reached from outside of the host cluster
+func serviceEndpoint(h *host, name string) (string, error) {
+	svc, err := h.client.CoreV1().Services(h.namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get service %s", name)
+	}
+	for i := range svc.Spec.Ports {
+		if svc.Spec.Ports[i].Name == apiServerPortName {
+			return serviceAddress(h, svc, &svc.Spec.Ports[i])
+		}
+	}
+	return "", errors.Errorf("service %s has no %s port", name, apiServerPortName)
+}
+
+// serviceAddress returns the host:port on which port of svc can be reached
+// from outside of the host cluster
+func serviceAddress(h *host, svc *corev1.Service, port *corev1.ServicePort) (string, error) {
+	switch svc.Spec.Type {
+	case corev1.ServiceTypeLoadBalancer:
+		for _, ingress := range svc.Status.LoadBalancer.Ingress {
+			address := ingress.IP
+			if address == "" {
+				address = ingress.Hostname
+			}
+			if address != "" {
+				return net.JoinHostPort(address, fmt.Sprintf("%d", port.Port)), nil
+			}
+		}
+		return "", errors.Errorf("load balancer for service %s has no ingress yet", svc.Name)
+	case corev1.ServiceTypeNodePort:
+		if port.NodePort == 0 {
+			return "", errors.Errorf("service %s has no node port allocated", svc.Name)
+		}
+		address, err := hostNodeAddress(h, svc.Spec.Selector[nodeNameLabelKey])
+		if err != nil {
+			return "", err
+		}
+		return net.JoinHostPort(address, fmt.Sprintf("%d", port.NodePort)), nil
+	default:
+		return "", errors.Errorf("unsupported type %q for service %s", svc.Spec.Type, svc.Name)
+	}
+}
+
+// servicePorts returns the ports of the Services of the node name in the form
+// address:port->containerPort/protocol, like docker ps
+func servicePorts(h *host, name string) ([]string, error) {
+	services, err := h.client.CoreV1().Services(h.namespace).List(metav1.ListOptions{
+		LabelSelector: labels.Set{nodeNameLabelKey: name}.String(),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list services of node %s", name)
+	}
+	ports := []string{}
+	for i := range services.Items {
+		svc := &services.Items[i]
+		for j := range svc.Spec.Ports {
+			port := &svc.Spec.Ports[j]
+			address, err := serviceAddress(h, svc, port)
+			if err != nil {
+				address = "<pending>"
+			}
+			ports = append(ports, fmt.Sprintf("%s->%s/%s", address, port.TargetPort.String(), port.Protocol))
+		}
+	}
+	return ports, nil
+}
+
+// hostNodeAddress returns the address of the host cluster node running the
+// pod of the node name, preferring external addresses
+func hostNodeAddress(h *host, name string) (string, error) {
+	pods, err := h.client.CoreV1().Pods(h.namespace).List(metav1.ListOptions{
+		LabelSelector: labels.Set{nodeNameLabelKey: name}.String(),
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get pod for node %s", name)
+	}
+	if len(pods.Items) == 0 {
+		return "", errors.Errorf("no pod found for node %s", name)
+	}
+	pod := pods.Items[0]
+	if pod.Spec.NodeName == "" {
+		return "", errors.Errorf("pod %s is not scheduled yet", pod.Name)
+	}
+	hostNode, err := h.client.CoreV1().Nodes().Get(pod.Spec.NodeName, metav1.GetOptions{})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get host node %s", pod.Spec.NodeName)
+	}
+	for _, addressType := range []corev1.NodeAddressType{
+		corev1.NodeExternalIP,
+		corev1.NodeExternalDNS,
+		corev1.NodeInternalIP,
+	} {
+		for _, address := range hostNode.Status.Addresses {
+			if address.Type == addressType {
+				return address.Address, nil
+			}
+		}
+	}
+	return "", errors.Errorf("host node %s has no usable address", pod.Spec.NodeName)
+}
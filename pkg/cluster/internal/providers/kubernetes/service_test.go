@@ -0,0 +1,174 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestServiceEndpoint(t *testing.T) {
+	t.Parallel()
+	scheduledPod := nodePod("kind-control-plane", "kind", "control-plane", "10.0.0.2")
+	scheduledPod.Spec.NodeName = "host-node"
+	hostNode := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "host-node"},
+		Status: corev1.NodeStatus{
+			Addresses: []corev1.NodeAddress{
+				{Type: corev1.NodeInternalIP, Address: "192.168.0.10"},
+				{Type: corev1.NodeExternalIP, Address: "203.0.113.10"},
+			},
+		},
+	}
+	apiServerPorts := []corev1.ServicePort{apiServerServicePort()}
+	nodePortService := serviceForNode("kind-control-plane", "kind", corev1.ServiceTypeNodePort, apiServerPorts)
+	nodePortService.Namespace = "default"
+	nodePortService.Spec.Ports[0].NodePort = 31443
+	loadBalancerService := serviceForNode("kind-control-plane", "kind", corev1.ServiceTypeLoadBalancer, apiServerPorts)
+	loadBalancerService.Namespace = "default"
+	loadBalancerService.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{Hostname: "lb.example.com"}}
+	pendingService := serviceForNode("kind-control-plane", "kind", corev1.ServiceTypeLoadBalancer, apiServerPorts)
+	pendingService.Namespace = "default"
+	cases := []struct {
+		name        string
+		objects     []runtime.Object
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "NodePort uses the host node external address",
+			objects:  []runtime.Object{scheduledPod, hostNode, nodePortService},
+			expected: "203.0.113.10:31443",
+		},
+		{
+			name:     "LoadBalancer uses the ingress address",
+			objects:  []runtime.Object{scheduledPod, loadBalancerService},
+			expected: "lb.example.com:6443",
+		},
+		{
+			name:        "LoadBalancer without ingress",
+			objects:     []runtime.Object{scheduledPod, pendingService},
+			expectError: true,
+		},
+		{
+			name:        "missing service",
+			objects:     []runtime.Object{scheduledPod},
+			expectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			_, h := newTestProvider(tc.objects...)
+			endpoint, err := serviceEndpoint(h, "kind-control-plane")
+			assert.ExpectError(t, tc.expectError, err)
+			assert.StringEqual(t, tc.expected, endpoint)
+		})
+	}
+}
+
+func TestServicePortsForNode(t *testing.T) {
+	t.Parallel()
+	mappings := []config.PortMapping{
+		{ContainerPort: 80, HostPort: 30080},
+		{ContainerPort: 53, Protocol: config.PortMappingProtocolUDP},
+	}
+	cases := []struct {
+		name            string
+		node            *config.Node
+		serviceType     corev1.ServiceType
+		exposeAPIServer bool
+		expected        []corev1.ServicePort
+	}{
+		{
+			name:        "worker without mappings",
+			node:        &config.Node{Role: config.WorkerRole},
+			serviceType: corev1.ServiceTypeNodePort,
+			expected:    []corev1.ServicePort{},
+		},
+		{
+			name:        "NodePort uses hostPort as the node port",
+			node:        &config.Node{Role: config.WorkerRole, ExtraPortMappings: mappings},
+			serviceType: corev1.ServiceTypeNodePort,
+			expected: []corev1.ServicePort{
+				{Name: "tcp-80", Protocol: corev1.ProtocolTCP, Port: 80, NodePort: 30080, TargetPort: intstr.FromInt(80)},
+				{Name: "udp-53", Protocol: corev1.ProtocolUDP, Port: 53, TargetPort: intstr.FromInt(53)},
+			},
+		},
+		{
+			name:            "LoadBalancer uses hostPort as the port",
+			node:            &config.Node{Role: config.ControlPlaneRole, ExtraPortMappings: mappings},
+			serviceType:     corev1.ServiceTypeLoadBalancer,
+			exposeAPIServer: true,
+			expected: []corev1.ServicePort{
+				{Name: apiServerPortName, Protocol: corev1.ProtocolTCP, Port: 6443, TargetPort: intstr.FromInt(6443)},
+				{Name: "tcp-30080", Protocol: corev1.ProtocolTCP, Port: 30080, TargetPort: intstr.FromInt(80)},
+				{Name: "udp-53", Protocol: corev1.ProtocolUDP, Port: 53, TargetPort: intstr.FromInt(53)},
+			},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ports := servicePortsForNode(tc.node, tc.serviceType, tc.exposeAPIServer)
+			assert.DeepEqual(t, tc.expected, ports)
+			svc := serviceForNode("kind-worker", "kind", tc.serviceType, ports)
+			if len(ports) == 0 {
+				if svc != nil {
+					t.Errorf("expected no service but got %v", svc)
+				}
+				return
+			}
+			assert.DeepEqual(t, tc.serviceType, svc.Spec.Type)
+		})
+	}
+}
+
+func TestServicePorts(t *testing.T) {
+	t.Parallel()
+	pod := nodePod("kind-worker", "kind", "worker", "10.0.0.3")
+	pod.Spec.NodeName = "host-node"
+	hostNode := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "host-node"},
+		Status: corev1.NodeStatus{
+			Addresses: []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: "192.168.0.10"}},
+		},
+	}
+	worker := &config.Node{
+		Role: config.WorkerRole,
+		ExtraPortMappings: []config.PortMapping{
+			{ContainerPort: 80, HostPort: 30080},
+			{ContainerPort: 443},
+		},
+	}
+	svc := serviceForNode("kind-worker", "kind", corev1.ServiceTypeNodePort, servicePortsForNode(worker, corev1.ServiceTypeNodePort, false))
+	svc.Namespace = "default"
+	p, h := newTestProvider(pod, hostNode, svc)
+	ports, err := p.node(h, "kind", "kind-worker", "kind-worker").(nodes.PortLister).Ports()
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, []string{"192.168.0.10:30080->80/TCP", "<pending>->443/TCP"}, ports)
+}
@@ -0,0 +1,259 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+// defaultStorageSize is the default size of persistent node storage
+const defaultStorageSize = "20Gi"
+
+// storageVolumeName is the volume backing /var in node pods
+const storageVolumeName = "dind-storage"
+
+// seedPersistentVolumes only seeds /var from the image the first time a claim
+// is used, so restarted node pods keep their state
+const seedPersistentVolumes = "if [ ! -e /storage/.kind-seeded ]; then cp -a /var/. /storage/ && touch /storage/.kind-seeded; fi && cp -R /run/* /runstg"
+
+func (o Options) storageSize() (resource.Quantity, error) {
+	size := o.StorageSize
+	if size == "" {
+		size = defaultStorageSize
+	}
+	quantity, err := resource.ParseQuantity(size)
+	if err != nil {
+		return quantity, errors.Wrapf(err, "invalid storage size %q", size)
+	}
+	return quantity, nil
+}
+
+// nodesServiceName returns the name of the headless Service governing the
+// StatefulSets of the nodes of cluster, which gives their pods DNS names
+func nodesServiceName(cluster string) string {
+	return cluster + "-nodes"
+}
+
+// ownedNodesService returns the headless Service governing the StatefulSets
+// of the nodes of cluster, owned by owner
+func ownedNodesService(cluster string, owner metav1.OwnerReference) *corev1.Service {
+	return &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Service",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: nodesServiceName(cluster),
+			Labels: map[string]string{
+				clusterLabelKey: cluster,
+			},
+			OwnerReferences: []metav1.OwnerReference{owner},
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			Selector: map[string]string{
+				clusterLabelKey: cluster,
+			},
+			PublishNotReadyAddresses: true,
+		},
+	}
+}
+
+// ensureNodesService creates the headless Service governing the
+// StatefulSets of the nodes of cluster, if it does not already exist
+func ensureNodesService(h *host, cluster string, owner metav1.OwnerReference) error {
+	svc := ownedNodesService(cluster, owner)
+	_, err := h.client.CoreV1().Services(h.namespace).Create(svc)
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "failed to create service %s", svc.Name)
+	}
+	return nil
+}
+
+// statefulSetPodName returns the name of the only pod of the StatefulSet
+// implementing the node name
+func statefulSetPodName(name string) string {
+	return fmt.Sprintf("%s-0", name)
+}
+
+// statefulSetForNode returns a single replica StatefulSet running pod,
+// with /var on a PersistentVolumeClaim instead of an emptyDir
+func statefulSetForNode(pod *corev1.Pod, name, cluster string, opts Options) (*appsv1.StatefulSet, error) {
+	size, err := opts.storageSize()
+	if err != nil {
+		return nil, err
+	}
+	var storageClassName *string
+	if opts.StorageClass != "" {
+		storageClassName = &opts.StorageClass
+	}
+
+	// the claim replaces the emptyDir volume
+	template := pod.DeepCopy()
+	volumes := []corev1.Volume{}
+	for _, volume := range template.Spec.Volumes {
+		if volume.Name != storageVolumeName {
+			volumes = append(volumes, volume)
+		}
+	}
+	template.Spec.Volumes = volumes
+	for i := range template.Spec.InitContainers {
+		if template.Spec.InitContainers[i].Name == "initvolume" {
+			template.Spec.InitContainers[i].Args = []string{"-c", seedPersistentVolumes}
+		}
+	}
+
+	replicas := int32(1)
+	nodeLabels := map[string]string{
+		clusterLabelKey:  cluster,
+		nodeNameLabelKey: name,
+	}
+	return &appsv1.StatefulSet{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "StatefulSet",
+			APIVersion: "apps/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: nodeLabels,
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:    &replicas,
+			ServiceName: nodesServiceName(cluster),
+			Selector: &metav1.LabelSelector{
+				MatchLabels: nodeLabels,
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: template.ObjectMeta,
+				Spec:       template.Spec,
+			},
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   storageVolumeName,
+						Labels: nodeLabels,
+					},
+					Spec: corev1.PersistentVolumeClaimSpec{
+						AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+						StorageClassName: storageClassName,
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceStorage: size,
+							},
+						},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func createStatefulSetForNode(logger log.Logger, h *host, node *config.Node, name, cluster string, opts Options, owner metav1.OwnerReference) error {
+	statefulSet, err := ownedStatefulSetForNode(node, name, cluster, opts, owner)
+	if err != nil {
+		return err
+	}
+	if manifest, err := yaml.Marshal(statefulSet); err == nil {
+		logger.V(2).Infof("statefulset manifest for %s\n%s", name, manifest)
+	}
+	if _, err := h.client.AppsV1().StatefulSets(h.namespace).Create(statefulSet); err != nil {
+		return errors.Wrapf(err, "failed to create statefulset %s", name)
+	}
+	return nil
+}
+
+// ownedStatefulSetForNode returns the StatefulSet implementing node with
+// its provider patches applied, owned by owner
+func ownedStatefulSetForNode(node *config.Node, name, cluster string, opts Options, owner metav1.OwnerReference) (*appsv1.StatefulSet, error) {
+	pod, err := podForNode(node, name, cluster)
+	if err != nil {
+		return nil, err
+	}
+	// Pod patches apply to the template, StatefulSet patches to the result
+	pod, err = patchPod(pod, node.ProviderPatches)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to apply provider patches to pod %s", name)
+	}
+	statefulSet, err := statefulSetForNode(pod, name, cluster, opts)
+	if err != nil {
+		return nil, err
+	}
+	statefulSet, err = patchStatefulSet(statefulSet, node.ProviderPatches)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to apply provider patches to statefulset %s", name)
+	}
+	// the claims are not owned by the StatefulSet, so they need to be owned
+	// by the cluster directly
+	statefulSet.OwnerReferences = append(statefulSet.OwnerReferences, owner)
+	for i := range statefulSet.Spec.VolumeClaimTemplates {
+		claim := &statefulSet.Spec.VolumeClaimTemplates[i]
+		claim.OwnerReferences = append(claim.OwnerReferences, owner)
+	}
+	return statefulSet, nil
+}
+
+// patchStatefulSet applies the merge patches to statefulSet, returning the
+// patched StatefulSet
+func patchStatefulSet(statefulSet *appsv1.StatefulSet, patches []string) (*appsv1.StatefulSet, error) {
+	if len(patches) == 0 {
+		return statefulSet, nil
+	}
+	patched := &appsv1.StatefulSet{}
+	if err := patchObject(statefulSet, patched, patches); err != nil {
+		return nil, err
+	}
+	return patched, nil
+}
+
+// deleteNodeStorage deletes the StatefulSet and claims implementing the
+// node name, if any
+func deleteNodeStorage(h *host, name string) error {
+	propagation := metav1.DeletePropagationBackground
+	err := h.client.AppsV1().StatefulSets(h.namespace).Delete(name, &metav1.DeleteOptions{
+		PropagationPolicy: &propagation,
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to delete statefulset %s", name)
+	}
+	// claims created from volumeClaimTemplates outlive the StatefulSet
+	claims := h.client.CoreV1().PersistentVolumeClaims(h.namespace)
+	list, err := claims.List(metav1.ListOptions{
+		LabelSelector: labels.Set{nodeNameLabelKey: name}.String(),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to list claims of node %s", name)
+	}
+	for _, claim := range list.Items {
+		err := claims.Delete(claim.Name, &metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete claim %s", claim.Name)
+		}
+	}
+	return nil
+}
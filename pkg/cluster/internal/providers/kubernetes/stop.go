@@ -0,0 +1,133 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// StopNodes scales the StatefulSets of the nodes to zero, so only nodes with
+// persistent storage can be stopped
+// The load balancer pod holds no state and is left running
+func (p *Provider) StopNodes(ctx context.Context, n []nodes.Node) error {
+	h, err := p.host()
+	if err != nil {
+		return err
+	}
+	stopped := []*node{}
+	for _, nodeHandle := range n {
+		kn := asNode(h, nodeHandle)
+		scaled, err := scaleNode(kn, 0)
+		if err != nil {
+			return err
+		}
+		if scaled {
+			stopped = append(stopped, kn)
+		}
+	}
+	// wait for the pods to go away, so that starting the nodes again does
+	// not find the old pods
+	ctx, cancel := context.WithTimeout(ctx, time.Minute*5)
+	defer cancel()
+	for _, kn := range stopped {
+		if err := waitForPodDeleted(ctx, kn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StartNodes scales the StatefulSets of the nodes back to one, and waits
+// for the node pods to be ready
+func (p *Provider) StartNodes(ctx context.Context, n []nodes.Node) error {
+	h, err := p.host()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Minute*5)
+	defer cancel()
+	for _, nodeHandle := range n {
+		kn := asNode(h, nodeHandle)
+		scaled, err := scaleNode(kn, 1)
+		if err != nil {
+			return err
+		}
+		if !scaled {
+			continue
+		}
+		if err := waitForPodReady(ctx, p.logger, kn.host, kn.pod); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scaleNode scales the StatefulSet of n to replicas, returning false if n
+// is the load balancer, which is not scaled
+func scaleNode(n *node, replicas int32) (bool, error) {
+	statefulSets := n.host.client.AppsV1().StatefulSets(n.host.namespace)
+	statefulSet, err := statefulSets.Get(n.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		role, rerr := n.Role()
+		if rerr == nil && role == constants.ExternalLoadBalancerNodeRoleValue {
+			return false, nil
+		}
+		return false, errors.Errorf(
+			"node %s has no persistent storage, only nodes created with persistent storage can be stopped and started",
+			n.name,
+		)
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get statefulset %s", n.name)
+	}
+	statefulSet.Spec.Replicas = &replicas
+	if _, err := statefulSets.Update(statefulSet); err != nil {
+		return false, errors.Wrapf(err, "failed to scale statefulset %s", n.name)
+	}
+	return true, nil
+}
+
+// waitForPodDeleted waits for the pod of n to be deleted, until ctx is done
+func waitForPodDeleted(ctx context.Context, n *node) error {
+	pods := n.host.client.CoreV1().Pods(n.host.namespace)
+	// the StatefulSet controller deletes the pod gracefully, we only delete
+	// it ourselves in case the controller is slow to notice
+	err := pods.Delete(n.pod, &metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to delete pod %s", n.pod)
+	}
+	err = wait.PollImmediateUntil(time.Second, func() (bool, error) {
+		_, err := pods.Get(n.pod, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}, ctx.Done())
+	if err != nil {
+		return errors.Wrapf(err, "failed waiting for pod %s to be deleted", n.pod)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+// waitForPodReady watches the pod name until all of its containers are ready,
+// returning an error as soon as the pod fails or ctx is done
+func waitForPodReady(ctx context.Context, logger log.Logger, h *host, name string) error {
+	pods := h.client.CoreV1().Pods(h.namespace)
+	nameSelector := fields.OneTermEqualSelector("metadata.name", name).String()
+	lw := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = nameSelector
+			return pods.List(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = nameSelector
+			return pods.Watch(options)
+		},
+	}
+	// the last observed reason the pod is not ready, for timeouts
+	status := "pod not found"
+	_, err := watchtools.UntilWithSync(ctx, lw, &corev1.Pod{}, nil, func(event watch.Event) (bool, error) {
+		pod, ok := event.Object.(*corev1.Pod)
+		if !ok || pod.Name != name {
+			return false, nil
+		}
+		if event.Type == watch.Deleted {
+			return false, errors.Errorf("pod %s was deleted", name)
+		}
+		if err := podFailure(pod); err != nil {
+			return false, errors.Wrapf(err, "pod %s failed", name)
+		}
+		ready, reason := podReady(pod)
+		status = reason
+		logger.V(2).Infof("pod %s phase %s: %s", name, pod.Status.Phase, reason)
+		return ready, nil
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return errors.Errorf("timed out waiting for pod %s to be ready: %s", name, status)
+		}
+		return err
+	}
+	return nil
+}
+
+// podReady returns true if pod and all of its containers are ready,
+// otherwise the reason it is not
+func podReady(pod *corev1.Pod) (bool, string) {
+	if pod.Status.Phase != corev1.PodRunning {
+		for _, status := range pod.Status.InitContainerStatuses {
+			if waiting := status.State.Waiting; waiting != nil {
+				return false, fmt.Sprintf("init container %s is waiting: %s", status.Name, waiting.Reason)
+			}
+		}
+		return false, fmt.Sprintf("pod is %s", pod.Status.Phase)
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if !status.Ready {
+			if waiting := status.State.Waiting; waiting != nil {
+				return false, fmt.Sprintf("container %s is waiting: %s", status.Name, waiting.Reason)
+			}
+			return false, fmt.Sprintf("container %s is not ready", status.Name)
+		}
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			if condition.Status == corev1.ConditionTrue {
+				return true, "pod is ready"
+			}
+			return false, fmt.Sprintf("pod is not ready: %s", condition.Reason)
+		}
+	}
+	return false, "pod has no ready condition"
+}
+
+// podFailure returns an error describing why pod will not become ready,
+// or nil if it may still become ready
+func podFailure(pod *corev1.Pod) error {
+	switch pod.Status.Phase {
+	case corev1.PodFailed, corev1.PodSucceeded:
+		return errors.Errorf("pod is %s: %s %s", pod.Status.Phase, pod.Status.Reason, pod.Status.Message)
+	}
+	statuses := append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...)
+	statuses = append(statuses, pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if waiting := status.State.Waiting; waiting != nil {
+			switch waiting.Reason {
+			case "ErrImagePull", "ImagePullBackOff", "InvalidImageName", "CreateContainerConfigError", "CreateContainerError":
+				return errors.Errorf("container %s cannot start: %s: %s", status.Name, waiting.Reason, waiting.Message)
+			case "CrashLoopBackOff":
+				return errors.Errorf("container %s is crashing: %s", status.Name, terminationMessage(status.LastTerminationState.Terminated))
+			}
+		}
+		// only init containers are expected to exit, successfully
+		if terminated := status.State.Terminated; terminated != nil && terminated.ExitCode != 0 {
+			return errors.Errorf("container %s terminated: %s", status.Name, terminationMessage(terminated))
+		}
+	}
+	return nil
+}
+
+// terminationMessage describes why a container terminated
+func terminationMessage(terminated *corev1.ContainerStateTerminated) string {
+	if terminated == nil {
+		return "unknown reason"
+	}
+	message := fmt.Sprintf("%s (exit code %d)", terminated.Reason, terminated.ExitCode)
+	if terminated.Message != "" {
+		message = fmt.Sprintf("%s: %s", message, terminated.Message)
+	}
+	return message
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+func readyPod(name string) *corev1.Pod {
+	pod := nodePod(name, "kind", "worker", "10.0.0.3")
+	pod.Status.Phase = corev1.PodRunning
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+		{Name: name, Ready: true},
+	}
+	pod.Status.Conditions = []corev1.PodCondition{
+		{Type: corev1.PodReady, Status: corev1.ConditionTrue},
+	}
+	return pod
+}
+
+func TestWaitForPodReady(t *testing.T) {
+	t.Parallel()
+	pending := nodePod("kind-worker", "kind", "worker", "")
+	pending.Status.Phase = corev1.PodPending
+
+	imagePullFailure := nodePod("kind-worker", "kind", "worker", "")
+	imagePullFailure.Status.Phase = corev1.PodPending
+	imagePullFailure.Status.ContainerStatuses = []corev1.ContainerStatus{
+		{
+			Name: "kind-worker",
+			State: corev1.ContainerState{
+				Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "not found"},
+			},
+		},
+	}
+
+	crashing := readyPod("kind-worker")
+	crashing.Status.ContainerStatuses[0].Ready = false
+	crashing.Status.ContainerStatuses[0].State.Waiting = &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}
+	crashing.Status.ContainerStatuses[0].LastTerminationState.Terminated = &corev1.ContainerStateTerminated{Reason: "Error", ExitCode: 255}
+
+	cases := []struct {
+		Name          string
+		Pod           *corev1.Pod
+		ExpectedError string
+	}{
+		{
+			Name: "ready",
+			Pod:  readyPod("kind-worker"),
+		},
+		{
+			Name:          "image pull failure",
+			Pod:           imagePullFailure,
+			ExpectedError: "ImagePullBackOff: not found",
+		},
+		{
+			Name:          "crash loop",
+			Pod:           crashing,
+			ExpectedError: "Error (exit code 255)",
+		},
+		{
+			Name:          "timeout",
+			Pod:           pending,
+			ExpectedError: "timed out waiting for pod kind-worker to be ready: pod is Pending",
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			_, h := newTestProvider(tc.Pod)
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			err := waitForPodReady(ctx, log.NoopLogger{}, h, "kind-worker")
+			assert.ExpectError(t, tc.ExpectedError != "", err)
+			if err != nil && !strings.Contains(err.Error(), tc.ExpectedError) {
+				t.Errorf("expected error to contain %q but got %q", tc.ExpectedError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podman
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// portMapping is a port mapping as reported by podman 1.x inspect
+type portMapping struct {
+	HostPort      int32  `json:"hostPort"`
+	ContainerPort int32  `json:"containerPort"`
+	Protocol      string `json:"protocol"`
+	HostIP        string `json:"hostIP"`
+}
+
+// portBinding is a port binding as reported by podman 2.x and later inspect,
+// which are keyed by "<containerPort>/<protocol>" like docker
+type portBinding struct {
+	HostIP   string `json:"HostIp"`
+	HostPort string `json:"HostPort"`
+}
+
+// hostPortFor returns the host address and port that containerPort is
+// published on, given the json encoded .NetworkSettings.Ports of a container
+func hostPortFor(ports []byte, containerPort int32, protocol string) (string, string, error) {
+	host, port := "", ""
+	// podman 1.x reports a list of mappings
+	var mappings []portMapping
+	if err := json.Unmarshal(ports, &mappings); err == nil {
+		for _, m := range mappings {
+			if m.ContainerPort == containerPort && strings.EqualFold(m.Protocol, protocol) {
+				host, port = m.HostIP, strconv.Itoa(int(m.HostPort))
+				break
+			}
+		}
+	} else {
+		// later versions report bindings by port and protocol
+		var bindings map[string][]portBinding
+		if err := json.Unmarshal(ports, &bindings); err != nil {
+			return "", "", errors.Wrap(err, "failed to decode port mappings")
+		}
+		key := fmt.Sprintf("%d/%s", containerPort, strings.ToLower(protocol))
+		if b := bindings[key]; len(b) > 0 {
+			host, port = b[0].HostIP, b[0].HostPort
+		}
+	}
+	if port == "" {
+		return "", "", errors.Errorf("port %d/%s is not published", containerPort, protocol)
+	}
+	// an unset host address means the port is published on all addresses
+	if host == "" || host == "0.0.0.0" {
+		host = "127.0.0.1"
+	}
+	return host, port, nil
+}
+
+// network is the subset of podman network inspect output needed to find
+// the network subnets, which is a CNI config list before podman 4
+type network struct {
+	Plugins []struct {
+		IPAM struct {
+			Ranges [][]struct {
+				Subnet string `json:"subnet"`
+			} `json:"ranges"`
+		} `json:"ipam"`
+	} `json:"plugins"`
+	Subnets []struct {
+		Subnet string `json:"subnet"`
+	} `json:"subnets"`
+}
+
+// networkSubnets returns the subnets of the json encoded podman network
+// inspect output
+func networkSubnets(inspect []byte) ([]string, error) {
+	var networks []network
+	if err := json.Unmarshal(inspect, &networks); err != nil {
+		return nil, errors.Wrap(err, "failed to decode network details")
+	}
+	subnets := []string{}
+	for _, n := range networks {
+		for _, plugin := range n.Plugins {
+			for _, ranges := range plugin.IPAM.Ranges {
+				for _, r := range ranges {
+					subnets = append(subnets, r.Subnet)
+				}
+			}
+		}
+		for _, s := range n.Subnets {
+			subnets = append(subnets, s.Subnet)
+		}
+	}
+	return subnets, nil
+}
+
+// getSubnets returns the subnets of the podman network networkName
+func getSubnets(networkName string) ([]string, error) {
+	cmd := exec.Command("podman", "network", "inspect", networkName)
+	lines, err := exec.OutputLines(cmd)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get subnets")
+	}
+	return networkSubnets([]byte(strings.Join(lines, "\n")))
+}
+
+// createVolumeArgs returns the podman args creating the named volume for
+// the /var of a node in cluster
+// podman does not remove anonymous volumes created with --volume on rm -v,
+// so the node storage is a named volume deleted along with the node
+func createVolumeArgs(name, cluster string) []string {
+	return []string{"volume", "create",
+		"--label", fmt.Sprintf("%s=%s", clusterLabelKey, cluster),
+		name,
+	}
+}
+
+// deleteVolumes deletes the node volumes created by createNode for the
+// nodes named names, if they exist
+func deleteVolumes(ctx context.Context, names []string) error {
+	cmd := exec.CommandContext(ctx, "podman", "volume", "ls",
+		"-q", // quiet output for parsing
+		"--filter", "label="+clusterLabelKey,
+	)
+	lines, err := exec.OutputLines(cmd)
+	if err != nil {
+		return errors.Wrap(err, "failed to list volumes")
+	}
+	volumes := sets.NewString(lines...).Intersection(sets.NewString(names...))
+	if volumes.Len() == 0 {
+		return nil
+	}
+	args := append([]string{"volume", "rm", "-f"}, volumes.List()...)
+	if err := exec.CommandContext(ctx, "podman", args...).Run(); err != nil {
+		return errors.Wrap(err, "failed to delete volumes")
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podman
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestHostPortFor(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name         string
+		Ports        string
+		ExpectedHost string
+		ExpectedPort string
+		ExpectError  bool
+	}{
+		{
+			Name:         "podman 1.x mappings",
+			Ports:        `[{"hostPort":80,"containerPort":80,"protocol":"tcp","hostIP":""},{"hostPort":38427,"containerPort":6443,"protocol":"tcp","hostIP":"127.0.0.1"}]`,
+			ExpectedHost: "127.0.0.1",
+			ExpectedPort: "38427",
+		},
+		{
+			Name:         "podman 2.x bindings",
+			Ports:        `{"6443/tcp":[{"HostIp":"10.0.0.1","HostPort":"38427"}],"80/tcp":[{"HostIp":"","HostPort":"80"}]}`,
+			ExpectedHost: "10.0.0.1",
+			ExpectedPort: "38427",
+		},
+		{
+			Name:         "all addresses",
+			Ports:        `{"6443/tcp":[{"HostIp":"0.0.0.0","HostPort":"38427"}]}`,
+			ExpectedHost: "127.0.0.1",
+			ExpectedPort: "38427",
+		},
+		{
+			Name:        "not published",
+			Ports:       `[{"hostPort":6443,"containerPort":6443,"protocol":"udp","hostIP":""}]`,
+			ExpectError: true,
+		},
+		{
+			Name:        "invalid",
+			Ports:       `<no value>`,
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			host, port, err := hostPortFor([]byte(tc.Ports), 6443, "tcp")
+			assert.ExpectError(t, tc.ExpectError, err)
+			assert.StringEqual(t, tc.ExpectedHost, host)
+			assert.StringEqual(t, tc.ExpectedPort, port)
+		})
+	}
+}
+
+func TestNetworkSubnets(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name     string
+		Inspect  string
+		Expected []string
+	}{
+		{
+			Name:     "CNI config list",
+			Inspect:  `[{"cniVersion":"0.4.0","name":"podman","plugins":[{"type":"bridge","ipam":{"type":"host-local","ranges":[[{"subnet":"10.88.0.0/16","gateway":"10.88.0.1"}],[{"subnet":"fd00::/64"}]]}},{"type":"portmap"}]}]`,
+			Expected: []string{"10.88.0.0/16", "fd00::/64"},
+		},
+		{
+			Name:     "netavark network",
+			Inspect:  `[{"name":"podman","driver":"bridge","subnets":[{"subnet":"10.88.0.0/16","gateway":"10.88.0.1"}]}]`,
+			Expected: []string{"10.88.0.0/16"},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			subnets, err := networkSubnets([]byte(tc.Inspect))
+			assert.ExpectError(t, false, err)
+			assert.DeepEqual(t, tc.Expected, subnets)
+		})
+	}
+}
+
+func TestGeneratePortMappings(t *testing.T) {
+	t.Parallel()
+	args := generatePortMappings(
+		config.PortMapping{ListenAddress: "127.0.0.1", HostPort: 8080, ContainerPort: 80},
+		config.PortMapping{HostPort: 53, ContainerPort: 53, Protocol: config.PortMappingProtocolUDP},
+		config.PortMapping{ListenAddress: "::1", HostPort: 6443, ContainerPort: 6443},
+	)
+	assert.DeepEqual(t, []string{
+		"--publish=127.0.0.1:8080:80/tcp",
+		"--publish=53:53/udp",
+		"--publish=[::1]:6443:6443/tcp",
+	}, args)
+}
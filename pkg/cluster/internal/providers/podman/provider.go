@@ -0,0 +1,240 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podman
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/kind/pkg/cluster/events"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/cli"
+)
+
+// NewProvider returns a new provider based on executing `podman ...`
+func NewProvider(logger log.Logger) provider.Provider {
+	return &Provider{
+		logger: logger,
+	}
+}
+
+// Provider implements provider.Provider
+// see NewProvider
+type Provider struct {
+	logger log.Logger
+}
+
+// Provision is part of the providers.Provider interface
+func (p *Provider) Provision(ctx context.Context, status *cli.Status, cluster string, cfg *config.Cluster) (err error) {
+	// ensure node images are pulled before actually provisioning
+	_ = events.RunContext(ctx, "pull-images", func() error {
+		ensureNodeImages(p.logger, status, cfg)
+		return nil
+	})
+
+	// actually provision the cluster
+	status.Start("Preparing nodes 📦")
+	defer func() { status.End(err == nil) }()
+
+	// plan creating the containers
+	createContainerFuncs, err := planCreation(cluster, cfg, runPodman)
+	if err != nil {
+		return err
+	}
+
+	// actually create nodes, the first failure cancels creating the others
+	return events.RunContext(ctx, "create-nodes", func() error {
+		return errors.UntilErrorConcurrentContext(ctx, createContainerFuncs)
+	})
+}
+
+// ProvisionDryRun is part of the providers.Provider interface
+func (p *Provider) ProvisionDryRun(cluster string, cfg *config.Cluster) (string, error) {
+	recorder := &common.CommandRecorder{Command: "podman"}
+	createContainerFuncs, err := planCreation(cluster, cfg, func(_ context.Context, args []string) error {
+		return recorder.Record(args)
+	})
+	if err != nil {
+		return "", err
+	}
+	// record the commands in order rather than concurrently
+	for _, createContainer := range createContainerFuncs {
+		if err := createContainer(context.Background()); err != nil {
+			return "", err
+		}
+	}
+	return recorder.String(), nil
+}
+
+// ProvisionNode is part of the providers.Provider interface
+func (p *Provider) ProvisionNode(ctx context.Context, status *cli.Status, cluster, name string, cfg *config.Cluster, node *config.Node) (err error) {
+	// ensure the node image is pulled before actually provisioning
+	ensureNodeImages(p.logger, status, cfg)
+
+	status.Start(fmt.Sprintf("Preparing node %s 📦", name))
+	defer func() { status.End(err == nil) }()
+
+	createContainer, err := planNodeAddition(cluster, name, cfg, node)
+	if err != nil {
+		return err
+	}
+	return createContainer(ctx)
+}
+
+// ListClusters is part of the providers.Provider interface
+func (p *Provider) ListClusters() ([]string, error) {
+	cmd := exec.Command("podman",
+		"ps",
+		"-a", // show stopped nodes
+		// filter for nodes with the cluster label
+		"--filter", "label="+clusterLabelKey,
+		// format to include the cluster name
+		// podman exposes labels as a map rather than with .Label
+		"--format", fmt.Sprintf(`{{index .Labels "%s"}}`, clusterLabelKey),
+	)
+	lines, err := exec.OutputLines(cmd)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list clusters")
+	}
+	return sets.NewString(lines...).List(), nil
+}
+
+// ListNodes is part of the providers.Provider interface
+func (p *Provider) ListNodes(cluster string) ([]nodes.Node, error) {
+	cmd := exec.Command("podman",
+		"ps",
+		"-a", // show stopped nodes
+		// filter for nodes with the cluster label
+		"--filter", fmt.Sprintf("label=%s=%s", clusterLabelKey, cluster),
+		// format to include the node name
+		"--format", `{{.Names}}`,
+	)
+	lines, err := exec.OutputLines(cmd)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list nodes")
+	}
+	// convert names to node handles
+	ret := make([]nodes.Node, 0, len(lines))
+	for _, name := range lines {
+		ret = append(ret, p.node(name))
+	}
+	return ret, nil
+}
+
+// DeleteNodes is part of the providers.Provider interface
+func (p *Provider) DeleteNodes(ctx context.Context, n []nodes.Node) error {
+	if len(n) == 0 {
+		return nil
+	}
+	const command = "podman"
+	args := make([]string, 0, len(n)+3) // allocate once
+	args = append(args,
+		"rm",
+		"-f", // force the container to be delete now
+		"-v", // delete anonymous volumes
+	)
+	names := make([]string, 0, len(n))
+	for _, node := range n {
+		names = append(names, node.String())
+	}
+	args = append(args, names...)
+	if err := exec.CommandContext(ctx, command, args...).Run(); err != nil {
+		return errors.Wrap(err, "failed to delete nodes")
+	}
+	// the named /var volumes are not removed along with the containers
+	return deleteVolumes(ctx, names)
+}
+
+// StopNodes is part of the providers.Provider interface
+func (p *Provider) StopNodes(ctx context.Context, n []nodes.Node) error {
+	if len(n) == 0 {
+		return nil
+	}
+	args := []string{"stop"}
+	for _, node := range n {
+		args = append(args, node.String())
+	}
+	if err := exec.CommandContext(ctx, "podman", args...).Run(); err != nil {
+		return errors.Wrap(err, "failed to stop nodes")
+	}
+	return nil
+}
+
+// StartNodes is part of the providers.Provider interface
+func (p *Provider) StartNodes(ctx context.Context, n []nodes.Node) error {
+	// start the nodes one at a time, so they are likely to be assigned the
+	// same addresses as when they were created
+	for _, node := range n {
+		if err := exec.CommandContext(ctx, "podman", "start", node.String()).Run(); err != nil {
+			return errors.Wrapf(err, "failed to start node %s", node.String())
+		}
+	}
+	return nil
+}
+
+// GetAPIServerEndpoint is part of the providers.Provider interface
+func (p *Provider) GetAPIServerEndpoint(cluster string) (string, error) {
+	// locate the node that hosts this
+	allNodes, err := p.ListNodes(cluster)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to list nodes")
+	}
+	n, err := nodeutils.APIServerEndpointNode(allNodes)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get api server endpoint")
+	}
+
+	// retrieve the port mappings using podman inspect, the index template
+	// used by docker does not work across podman versions
+	cmd := exec.Command(
+		"podman", "inspect",
+		"--format", "{{ json .NetworkSettings.Ports }}",
+		n.String(),
+	)
+	lines, err := exec.OutputLines(cmd)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get api server port")
+	}
+	host, port, err := hostPortFor(
+		[]byte(strings.Join(lines, "\n")), common.APIServerInternalPort, "tcp",
+	)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get api server port")
+	}
+
+	// join host and port
+	return net.JoinHostPort(host, port), nil
+}
+
+// node returns a new node handle for this provider
+func (p *Provider) node(name string) nodes.Node {
+	return &node{
+		name: name,
+	}
+}
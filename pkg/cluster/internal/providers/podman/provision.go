@@ -0,0 +1,382 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podman
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/loadbalancer"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/provider/common"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// planCreation creates a slice of funcs that will create the containers
+// by calling run with the podman args creating them
+func planCreation(cluster string, cfg *config.Cluster, run func(ctx context.Context, args []string) error) (createContainerFuncs []func(context.Context) error, err error) {
+	// these apply to all container creation
+	nodeNamer := common.MakeNodeNamer(cluster)
+	genericArgs, err := commonArgs(cluster, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// only the external LB should reflect the port if we have multiple control planes
+	apiServerPort := cfg.Networking.APIServerPort
+	apiServerAddress := cfg.Networking.APIServerAddress
+	if clusterHasImplicitLoadBalancer(cfg) {
+		apiServerPort = 0              // replaced with random ports
+		apiServerAddress = "127.0.0.1" // only the LB needs to be non-local
+		if clusterIsIPv6(cfg) {
+			apiServerAddress = "::1" // only the LB needs to be non-local
+		}
+		// plan loadbalancer node
+		name := nodeNamer(constants.ExternalLoadBalancerNodeRoleValue)
+		createContainerFuncs = append(createContainerFuncs, func(ctx context.Context) error {
+			args, err := runArgsForLoadBalancer(cfg, name, genericArgs)
+			if err != nil {
+				return err
+			}
+			return createContainer(ctx, run, args)
+		})
+	}
+
+	// plan normal nodes
+	for i := range cfg.Nodes {
+		node := &cfg.Nodes[i]
+		createContainerFunc, err := planNodeCreation(node, nodeNamer(string(node.Role)), cluster, genericArgs, apiServerAddress, apiServerPort, run)
+		if err != nil {
+			return nil, err
+		}
+		createContainerFuncs = append(createContainerFuncs, createContainerFunc)
+	}
+	return createContainerFuncs, nil
+}
+
+// planNodeCreation returns a func creating the container for node with name,
+// publishing the API server of control plane nodes on apiServerAddress and
+// apiServerPort, or a random port if apiServerPort is zero
+func planNodeCreation(node *config.Node, name, cluster string, genericArgs []string, apiServerAddress string, apiServerPort int32, run func(ctx context.Context, args []string) error) (func(context.Context) error, error) {
+	node = node.DeepCopy() // copy so we can modify
+
+	// fixup relative paths, podman can only handle absolute paths
+	for i := range node.ExtraMounts {
+		hostPath := node.ExtraMounts[i].HostPath
+		absHostPath, err := filepath.Abs(hostPath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to resolve absolute path for hostPath: %q", hostPath)
+		}
+		node.ExtraMounts[i].HostPath = absHostPath
+	}
+
+	// plan actual creation based on role
+	switch node.Role {
+	case config.ControlPlaneRole:
+		return func(ctx context.Context) error {
+			port, err := common.PortOrGetFreePort(apiServerPort, apiServerAddress)
+			if err != nil {
+				return errors.Wrap(err, "failed to get port for API server")
+			}
+			node.ExtraPortMappings = append(node.ExtraPortMappings,
+				config.PortMapping{
+					ListenAddress: apiServerAddress,
+					HostPort:      port,
+					ContainerPort: common.APIServerInternalPort,
+				},
+			)
+			return createNode(ctx, node, name, cluster, genericArgs, run)
+		}, nil
+	case config.WorkerRole:
+		return func(ctx context.Context) error {
+			return createNode(ctx, node, name, cluster, genericArgs, run)
+		}, nil
+	default:
+		return nil, errors.Errorf("unknown node role: %q", node.Role)
+	}
+}
+
+// planNodeAddition returns a func creating the container for node with
+// name in an existing cluster
+func planNodeAddition(cluster, name string, cfg *config.Cluster, node *config.Node) (func(context.Context) error, error) {
+	genericArgs, err := commonArgs(cluster, cfg)
+	if err != nil {
+		return nil, err
+	}
+	// added control plane nodes are behind the external load balancer
+	apiServerAddress := "127.0.0.1"
+	if clusterIsIPv6(cfg) {
+		apiServerAddress = "::1"
+	}
+	return planNodeCreation(node, name, cluster, genericArgs, apiServerAddress, 0, runPodman)
+}
+
+// createNode creates the /var volume of the node name, then its container
+func createNode(ctx context.Context, node *config.Node, name, cluster string, genericArgs []string, run func(ctx context.Context, args []string) error) error {
+	if err := run(ctx, createVolumeArgs(name, cluster)); err != nil {
+		return errors.Wrapf(err, "failed to create volume %s", name)
+	}
+	args, err := runArgsForNode(node, name, genericArgs)
+	if err != nil {
+		return err
+	}
+	return createContainer(ctx, run, args)
+}
+
+func createContainer(ctx context.Context, run func(ctx context.Context, args []string) error, args []string) error {
+	if err := run(ctx, args); err != nil {
+		return errors.Wrap(err, "podman run error")
+	}
+	return nil
+}
+
+// runPodman runs podman with args
+func runPodman(ctx context.Context, args []string) error {
+	return exec.CommandContext(ctx, "podman", args...).Run()
+}
+
+func clusterIsIPv6(cfg *config.Cluster) bool {
+	return cfg.Networking.IPFamily == "ipv6"
+}
+
+func clusterHasImplicitLoadBalancer(cfg *config.Cluster) bool {
+	controlPlanes := 0
+	for _, configNode := range cfg.Nodes {
+		role := string(configNode.Role)
+		if role == constants.ControlPlaneNodeRoleValue {
+			controlPlanes++
+		}
+	}
+	return controlPlanes > 1
+}
+
+// commonArgs computes static arguments that apply to all containers
+func commonArgs(cluster string, cfg *config.Cluster) ([]string, error) {
+	// standard arguments all nodes containers need, computed once
+	args := []string{
+		"--detach", // run the container detached
+		"--tty",    // allocate a tty for entrypoint logs
+		// label the node with the cluster ID
+		"--label", fmt.Sprintf("%s=%s", clusterLabelKey, cluster),
+	}
+
+	// enable IPv6 if necessary
+	if clusterIsIPv6(cfg) {
+		args = append(args, "--sysctl=net.ipv6.conf.all.disable_ipv6=0", "--sysctl=net.ipv6.conf.all.forwarding=1")
+	}
+
+	// pass proxy environment variables
+	proxyEnv, err := getProxyEnv(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "proxy setup error")
+	}
+	for key, val := range proxyEnv {
+		args = append(args, "-e", fmt.Sprintf("%s=%s", key, val))
+	}
+	return args, nil
+}
+
+func runArgsForNode(node *config.Node, name string, args []string) ([]string, error) {
+	args = append([]string{
+		"run",
+		"--hostname", name, // make hostname match container name
+		"--name", name, // ... and set the container name
+		// label the node with the role ID
+		"--label", fmt.Sprintf("%s=%s", nodeRoleLabelKey, node.Role),
+		// running containers in a container requires privileged
+		"--privileged",
+		"--security-opt", "seccomp=unconfined", // also ignore seccomp
+		// runtime temporary storage
+		"--tmpfs", "/tmp", // various things depend on working /tmp
+		"--tmpfs", "/run", // systemd wants a writable /run
+		// runtime persistent storage, on the named volume created for the node
+		// this ensures that E.G. pods, logs etc. are not on the container
+		// filesystem, podman mounts named volumes nosuid,noexec,nodev otherwise
+		"--volume", fmt.Sprintf("%s:/var:suid,exec,dev", name),
+		// some k8s things want to read /lib/modules
+		"--volume", "/lib/modules:/lib/modules:ro",
+	},
+		args...,
+	)
+
+	// convert mounts and port mappings to container run args
+	args = append(args, generateMountBindings(node.ExtraMounts...)...)
+	args = append(args, generatePortMappings(node.ExtraPortMappings...)...)
+
+	// constrain the container to the node resources
+	resourceArgs, err := generateResourceArgs(node.Resources)
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, resourceArgs...)
+
+	// finally, specify the image to run
+	return append(args, node.Image), nil
+}
+
+func runArgsForLoadBalancer(cfg *config.Cluster, name string, args []string) ([]string, error) {
+	args = append([]string{
+		"run",
+		"--hostname", name, // make hostname match container name
+		"--name", name, // ... and set the container name
+		// label the node with the role ID
+		"--label", fmt.Sprintf("%s=%s", nodeRoleLabelKey, constants.ExternalLoadBalancerNodeRoleValue),
+	},
+		args...,
+	)
+
+	// load balancer port mapping
+	listenAddress := cfg.Networking.APIServerAddress
+	port, err := common.PortOrGetFreePort(cfg.Networking.APIServerPort, listenAddress)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get port for api server load balancer")
+	}
+	args = append(args, generatePortMappings(config.PortMapping{
+		ListenAddress: listenAddress,
+		HostPort:      port,
+		ContainerPort: common.APIServerInternalPort,
+	})...)
+
+	// finally, specify the image to run
+	return append(args, loadbalancer.Image), nil
+}
+
+func getProxyEnv(cfg *config.Cluster) (map[string]string, error) {
+	envs := common.GetProxyEnvs(cfg)
+	// Specifically add the podman network subnets to NO_PROXY if we are using a proxy
+	if len(envs) > 0 {
+		// the podman default network is named "podman"
+		subnets, err := getSubnets("podman")
+		if err != nil {
+			return nil, err
+		}
+		noProxyList := strings.Join(append(subnets, envs[common.NOProxy]), ",")
+		envs[common.NOProxy] = noProxyList
+		envs[strings.ToLower(common.NOProxy)] = noProxyList
+	}
+	return envs, nil
+}
+
+// generateMountBindings converts the mount list to a list of args for podman
+// '<HostPath>:<ContainerPath>[:options]', where 'options'
+// is a comma-separated list of the following strings:
+// 'ro', if the path is read only
+// 'Z', if the volume requires SELinux relabeling
+func generateMountBindings(mounts ...config.Mount) []string {
+	args := make([]string, 0, len(mounts))
+	for _, m := range mounts {
+		bind := fmt.Sprintf("%s:%s", m.HostPath, m.ContainerPath)
+		var attrs []string
+		if m.Readonly {
+			attrs = append(attrs, "ro")
+		}
+		// Only request relabeling if the pod provides an SELinux context. If the pod
+		// does not provide an SELinux context relabeling will label the volume with
+		// the container's randomly allocated MCS label. This would restrict access
+		// to the volume to the container which mounts it first.
+		if m.SelinuxRelabel {
+			attrs = append(attrs, "Z")
+		}
+		switch m.Propagation {
+		case config.MountPropagationNone:
+			// noop, private is default
+		case config.MountPropagationBidirectional:
+			attrs = append(attrs, "rshared")
+		case config.MountPropagationHostToContainer:
+			attrs = append(attrs, "rslave")
+		default: // Falls back to "private"
+		}
+		if len(attrs) > 0 {
+			bind = fmt.Sprintf("%s:%s", bind, strings.Join(attrs, ","))
+		}
+		args = append(args, fmt.Sprintf("--volume=%s", bind))
+	}
+	return args
+}
+
+// generatePortMappings converts the portMappings list to a list of args for podman
+// unlike docker, podman only accepts lower case protocols
+func generatePortMappings(portMappings ...config.PortMapping) []string {
+	args := make([]string, 0, len(portMappings))
+	for _, pm := range portMappings {
+		var hostPortBinding string
+		if pm.ListenAddress != "" {
+			hostPortBinding = net.JoinHostPort(pm.ListenAddress, fmt.Sprintf("%d", pm.HostPort))
+		} else {
+			hostPortBinding = fmt.Sprintf("%d", pm.HostPort)
+		}
+		protocol := "tcp" // TCP is the default
+		switch pm.Protocol {
+		case config.PortMappingProtocolUDP:
+			protocol = "udp"
+		case config.PortMappingProtocolSCTP:
+			protocol = "sctp"
+		default: // also covers cri.PortMappingProtocolTCP
+		}
+		args = append(args, fmt.Sprintf("--publish=%s:%d/%s", hostPortBinding, pm.ContainerPort, protocol))
+	}
+	return args
+}
+
+// generateResourceArgs converts the node resources to a list of args for podman
+// podman has no notion of a cpu request, so it is mapped to relative cpu shares
+func generateResourceArgs(resources config.Resources) ([]string, error) {
+	args := []string{}
+	if resources.Limits.CPU != "" {
+		cpus, err := resource.ParseQuantity(resources.Limits.CPU)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid cpu limit %q", resources.Limits.CPU)
+		}
+		args = append(args, fmt.Sprintf("--cpus=%s", strconv.FormatFloat(float64(cpus.MilliValue())/1000, 'f', -1, 64)))
+	}
+	if resources.Limits.Memory != "" {
+		memory, err := resource.ParseQuantity(resources.Limits.Memory)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid memory limit %q", resources.Limits.Memory)
+		}
+		args = append(args, fmt.Sprintf("--memory=%d", memory.Value()))
+	}
+	if resources.Requests.CPU != "" {
+		cpus, err := resource.ParseQuantity(resources.Requests.CPU)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid cpu request %q", resources.Requests.CPU)
+		}
+		// 1024 shares is one cpu, the kernel requires at least 2
+		shares := cpus.MilliValue() * 1024 / 1000
+		if shares < 2 {
+			shares = 2
+		}
+		args = append(args, fmt.Sprintf("--cpu-shares=%d", shares))
+	}
+	if resources.Requests.Memory != "" {
+		memory, err := resource.ParseQuantity(resources.Requests.Memory)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid memory request %q", resources.Requests.Memory)
+		}
+		args = append(args, fmt.Sprintf("--memory-reservation=%d", memory.Value()))
+	}
+	return args, nil
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"strings"
+
+	"github.com/alessio/shellescape"
+)
+
+// CommandRecorder records the command lines of commands instead of running
+// them, for dry runs
+// A CommandRecorder is not safe for concurrent use
+type CommandRecorder struct {
+	// Command is the command run with the recorded args
+	Command string
+
+	lines []string
+}
+
+// Record records the command line running Command with args
+func (r *CommandRecorder) Record(args []string) error {
+	quoted := []string{shellescape.Quote(r.Command)}
+	for _, arg := range args {
+		quoted = append(quoted, shellescape.Quote(arg))
+	}
+	r.lines = append(r.lines, strings.Join(quoted, " "))
+	return nil
+}
+
+// String returns the recorded command lines, one per line
+func (r *CommandRecorder) String() string {
+	var b strings.Builder
+	for _, line := range r.lines {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
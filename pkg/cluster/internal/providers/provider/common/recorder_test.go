@@ -0,0 +1,31 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestCommandRecorder(t *testing.T) {
+	t.Parallel()
+	r := &CommandRecorder{Command: "docker"}
+	assert.ExpectError(t, false, r.Record([]string{"run", "--name", "kind-control-plane", "kindest/node:v1.17.0"}))
+	assert.ExpectError(t, false, r.Record([]string{"run", "--label", "a=b c"}))
+	assert.StringEqual(t, "docker run --name kind-control-plane kindest/node:v1.17.0\ndocker run --label 'a=b c'\n", r.String())
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package provider
 
 import (
+	"context"
+
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
 
 	"sigs.k8s.io/kind/pkg/internal/apis/config"
@@ -25,10 +27,20 @@ import (
 
 // Provider represents a provider of cluster / node infrastructure
 // This is an alpha-grade internal API
+// Methods taking a context should stop what they are doing once it is done
 type Provider interface {
 	// Provision should create and start the nodes, just short of
 	// actually starting up Kubernetes, based on the given cluster config
-	Provision(status *cli.Status, cluster string, cfg *config.Cluster) error
+	// Its phases, such as pulling images, are emitted with events.RunContext
+	Provision(ctx context.Context, status *cli.Status, cluster string, cfg *config.Cluster) error
+	// ProvisionDryRun should return the provider objects Provision would
+	// create for the given cluster config, such as container run commands
+	// or manifests, without creating anything
+	ProvisionDryRun(cluster string, cfg *config.Cluster) (string, error)
+	// ProvisionNode should create and start the single node name for an
+	// existing cluster, just short of joining it to Kubernetes
+	// cfg holds the cluster wide settings, with node as its only node
+	ProvisionNode(ctx context.Context, status *cli.Status, cluster, name string, cfg *config.Cluster, node *config.Node) error
 	// ListClusters discovers the clusters that currently have resources
 	// under this providers
 	ListClusters() ([]string, error)
@@ -38,7 +50,13 @@ type Provider interface {
 	// DeleteNodes deletes the provided list of nodes
 	// These should be from results previously returned by this provider
 	// E.G. by ListNodes()
-	DeleteNodes([]nodes.Node) error
+	DeleteNodes(context.Context, []nodes.Node) error
+	// StopNodes stops the provided list of nodes without deleting them,
+	// so that they can be started again with StartNodes
+	StopNodes(context.Context, []nodes.Node) error
+	// StartNodes starts the provided list of nodes previously stopped with
+	// StopNodes, in order, the nodes may have new addresses afterwards
+	StartNodes(context.Context, []nodes.Node) error
 	// GetAPIServerEndpoint returns the host endpoint for the cluster's API server
 	GetAPIServerEndpoint(cluster string) (string, error)
 }
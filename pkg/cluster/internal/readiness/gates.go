@@ -0,0 +1,189 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package readiness
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// Gate is a condition the cluster must meet to be ready
+type Gate string
+
+const (
+	// NodesReady requires every node to be registered and Ready
+	NodesReady Gate = "nodes"
+	// SystemWorkloads requires the Deployments and DaemonSets in kube-system,
+	// such as CoreDNS, kindnetd and kube-proxy, to be available
+	SystemWorkloads Gate = "system-workloads"
+	// DefaultServiceAccount requires the default ServiceAccount to exist,
+	// pods cannot be created in the default namespace before it does
+	DefaultServiceAccount Gate = "default-serviceaccount"
+	// DefaultStorageClass requires a default StorageClass to exist
+	DefaultStorageClass Gate = "storageclass"
+)
+
+// AllGates are the known gates, in the order they are checked
+var AllGates = []Gate{
+	NodesReady,
+	SystemWorkloads,
+	DefaultServiceAccount,
+	DefaultStorageClass,
+}
+
+// DefaultGates returns the gates that can pass for a cluster created with cfg
+func DefaultGates(cfg *config.Cluster) []Gate {
+	gates := []Gate{}
+	for _, gate := range AllGates {
+		if gate == DefaultStorageClass && cfg.Storage.DisableDefaultStorageClass {
+			continue
+		}
+		gates = append(gates, gate)
+	}
+	return gates
+}
+
+// ParseGates returns the gates named by names, or an error if any of them
+// is not known
+func ParseGates(names []string) ([]Gate, error) {
+	gates := []Gate{}
+	for _, name := range names {
+		known := false
+		for _, gate := range AllGates {
+			if Gate(name) == gate {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, errors.Errorf("unknown readiness gate %q, expected one of %v", name, AllGates)
+		}
+		gates = append(gates, Gate(name))
+	}
+	return gates, nil
+}
+
+// check returns why the gate does not pass yet, or "" if it passes
+// node is a control-plane node and nodeCount the number of expected nodes
+type check func(ctx context.Context, node nodes.Node, nodeCount int) string
+
+var checks = map[Gate]check{
+	NodesReady:            checkNodes,
+	SystemWorkloads:       checkSystemWorkloads,
+	DefaultServiceAccount: checkDefaultServiceAccount,
+	DefaultStorageClass:   checkDefaultStorageClass,
+}
+
+// kubectl returns the output lines of kubectl with args on node
+func kubectl(ctx context.Context, node nodes.Node, args ...string) ([]string, error) {
+	args = append([]string{"--kubeconfig=/etc/kubernetes/admin.conf"}, args...)
+	return exec.OutputLines(exec.CommandContextFor(ctx, node, "kubectl", args...))
+}
+
+func checkNodes(ctx context.Context, node nodes.Node, nodeCount int) string {
+	lines, err := kubectl(ctx, node,
+		"get", "nodes",
+		`-o=jsonpath={range .items[*]}{.metadata.name}{" "}{.status.conditions[?(@.type=="Ready")].status}{"\n"}{end}`,
+	)
+	if err != nil {
+		return "failed to list nodes"
+	}
+	registered := 0
+	notReady := []string{}
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		registered++
+		if len(fields) < 2 || fields[1] != "True" {
+			notReady = append(notReady, fields[0])
+		}
+	}
+	if len(notReady) > 0 {
+		return "not Ready: " + strings.Join(notReady, ", ")
+	}
+	if registered < nodeCount {
+		return fmt.Sprintf("%d of %d nodes registered", registered, nodeCount)
+	}
+	return ""
+}
+
+func checkSystemWorkloads(ctx context.Context, node nodes.Node, nodeCount int) string {
+	queries := []struct {
+		kind     string
+		jsonpath string
+	}{
+		{"deployment", `{range .items[*]}{.metadata.name}{" "}{.spec.replicas}{" "}{.status.availableReplicas}{"\n"}{end}`},
+		{"daemonset", `{range .items[*]}{.metadata.name}{" "}{.status.desiredNumberScheduled}{" "}{.status.numberAvailable}{"\n"}{end}`},
+	}
+	unavailable := []string{}
+	for _, q := range queries {
+		lines, err := kubectl(ctx, node,
+			"get", q.kind+"s", "--namespace=kube-system", "-o=jsonpath="+q.jsonpath,
+		)
+		if err != nil {
+			return "failed to list " + q.kind + "s"
+		}
+		for _, line := range lines {
+			fields := strings.Fields(line)
+			if len(fields) < 2 {
+				continue
+			}
+			available := "0"
+			if len(fields) > 2 {
+				available = fields[2]
+			}
+			if available != fields[1] {
+				unavailable = append(unavailable, q.kind+"/"+fields[0]+" "+available+"/"+fields[1])
+			}
+		}
+	}
+	if len(unavailable) > 0 {
+		return "not available: " + strings.Join(unavailable, ", ")
+	}
+	return ""
+}
+
+func checkDefaultServiceAccount(ctx context.Context, node nodes.Node, nodeCount int) string {
+	if _, err := kubectl(ctx, node, "get", "serviceaccount", "default", "--namespace=default", "-o=name"); err != nil {
+		return "the default ServiceAccount does not exist"
+	}
+	return ""
+}
+
+func checkDefaultStorageClass(ctx context.Context, node nodes.Node, nodeCount int) string {
+	lines, err := kubectl(ctx, node,
+		"get", "storageclasses",
+		`-o=jsonpath={range .items[*]}{.metadata.annotations.storageclass\.kubernetes\.io/is-default-class}{"\n"}{end}`,
+	)
+	if err != nil {
+		return "failed to list storageclasses"
+	}
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "true" {
+			return ""
+		}
+	}
+	return "no default StorageClass"
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package readiness implements waiting for clusters to be ready
+package readiness
+
+import (
+	stdcontext "context"
+	"fmt"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/context"
+	configaction "sigs.k8s.io/kind/pkg/cluster/internal/create/actions/config"
+)
+
+const (
+	// minInterval is the initial interval between checks
+	minInterval = 250 * time.Millisecond
+	// maxInterval is the interval checks back off to
+	maxInterval = 5 * time.Second
+)
+
+// Cluster waits up to timeout for the cluster identified by ctx to pass
+// gates, returning an error summarizing the failing gates if it does not
+// Nil gates are the gates that can pass for the config the cluster was created
+// with. Waiting stops early once the context of ctx is done
+func Cluster(logger log.Logger, ctx *context.Context, gates []Gate, timeout time.Duration) (err error) {
+	allNodes, err := ctx.ListInternalNodes()
+	if err != nil {
+		return errors.Wrap(err, "error listing nodes")
+	}
+	if len(allNodes) == 0 {
+		return errors.Errorf("no nodes found for cluster %q", ctx.Name())
+	}
+
+	if gates == nil {
+		gates, err = clusterGates(ctx.Context(), logger, allNodes)
+		if err != nil {
+			return err
+		}
+	}
+
+	status := cli.StatusForLogger(logger)
+	status.Start(fmt.Sprintf("Waiting ≤ %s for cluster = Ready ⏳", timeout.Round(time.Second)))
+	defer func() { status.End(err == nil) }()
+
+	return Wait(ctx.Context(), allNodes, gates, timeout)
+}
+
+// clusterGates returns the default gates for the config the cluster of
+// allNodes was created with, clusters created before the config was kept on
+// the nodes get all of the gates
+func clusterGates(ctx stdcontext.Context, logger log.Logger, allNodes []nodes.Node) ([]Gate, error) {
+	controlPlanes, err := nodeutils.ControlPlaneNodes(allNodes)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := configaction.ReadClusterConfig(ctx, controlPlanes[0])
+	if err != nil {
+		logger.V(1).Infof("Using all of the readiness gates: %v", err)
+		return AllGates, nil
+	}
+	return DefaultGates(cfg), nil
+}
+
+// Wait checks gates against the cluster of allNodes until they all pass,
+// timeout elapses or ctx is done, backing off between checks
+// The returned error summarizes why each failing gate did not pass
+func Wait(ctx stdcontext.Context, allNodes []nodes.Node, gates []Gate, timeout time.Duration) error {
+	return newPoller().wait(ctx, allNodes, gates, timeout)
+}
+
+// poller polls gates, the clock is replaceable for testing
+type poller struct {
+	now   func() time.Time
+	sleep func(stdcontext.Context, time.Duration)
+}
+
+func newPoller() *poller {
+	return &poller{
+		now:   time.Now,
+		sleep: sleep,
+	}
+}
+
+// sleep waits for d to elapse or ctx to be done, whichever comes first
+func sleep(ctx stdcontext.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	}
+}
+
+func (p *poller) wait(ctx stdcontext.Context, allNodes []nodes.Node, gates []Gate, timeout time.Duration) error {
+	// get a control plane node to use to check cluster status
+	controlPlanes, err := nodeutils.ControlPlaneNodes(allNodes)
+	if err != nil {
+		return err
+	}
+	node := controlPlanes[0] // kind expects at least one always
+
+	// gates that passed once are not checked again
+	failures := map[Gate]string{}
+	pending := append([]Gate{}, gates...)
+	until := p.now().Add(timeout)
+	interval := minInterval
+	for {
+		remaining := []Gate{}
+		for _, gate := range pending {
+			if reason := checks[gate](ctx, node, len(allNodes)); reason != "" {
+				failures[gate] = reason
+				remaining = append(remaining, gate)
+			}
+		}
+		pending = remaining
+		if len(pending) == 0 {
+			return nil
+		}
+
+		left := until.Sub(p.now())
+		if left <= 0 {
+			break
+		}
+		if interval > left {
+			interval = left
+		}
+		p.sleep(ctx, interval)
+		if err := ctx.Err(); err != nil {
+			return errors.Wrap(err, "stopped waiting for the cluster to be ready")
+		}
+		if interval *= 2; interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+
+	summary := []string{}
+	for _, gate := range pending {
+		summary = append(summary, fmt.Sprintf("%s: %s", gate, failures[gate]))
+	}
+	return errors.Errorf("timed out after %s waiting for the cluster to be ready; %s", timeout.Round(time.Second), strings.Join(summary, "; "))
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package readiness
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/fake"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+	"sigs.k8s.io/kind/pkg/log"
+
+	configaction "sigs.k8s.io/kind/pkg/cluster/internal/create/actions/config"
+)
+
+const kubectlPrefix = "kubectl --kubeconfig=/etc/kubernetes/admin.conf "
+
+// newTestNodes returns a control-plane and a worker node, with the
+// control-plane scripted to report a ready cluster
+func newTestNodes() (*fake.Node, []nodes.Node) {
+	controlPlane := fake.NewNode("kind-control-plane", "control-plane", "172.17.0.2", "")
+	worker := fake.NewNode("kind-worker", "worker", "172.17.0.3", "")
+	controlPlane.Script(kubectlPrefix+"get nodes", "kind-control-plane True\nkind-worker True\n", nil)
+	controlPlane.Script(kubectlPrefix+"get deployments", "coredns 2 2\n", nil)
+	controlPlane.Script(kubectlPrefix+"get daemonsets", "kindnet 2 2\nkube-proxy 2 2\n", nil)
+	controlPlane.Script(kubectlPrefix+"get storageclasses", "true\n", nil)
+	return controlPlane, []nodes.Node{worker, controlPlane}
+}
+
+// newTestPoller returns a poller with a fake clock, recording its sleeps
+func newTestPoller() (*poller, *[]time.Duration) {
+	now := time.Unix(0, 0)
+	sleeps := []time.Duration{}
+	return &poller{
+		now: func() time.Time { return now },
+		sleep: func(ctx context.Context, d time.Duration) {
+			sleeps = append(sleeps, d)
+			now = now.Add(d)
+		},
+	}, &sleeps
+}
+
+func TestParseGates(t *testing.T) {
+	t.Parallel()
+	gates, err := ParseGates([]string{"storageclass", "nodes"})
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, []Gate{DefaultStorageClass, NodesReady}, gates)
+	_, err = ParseGates([]string{"nodes", "coffee"})
+	assert.ExpectError(t, true, err)
+}
+
+func TestWaitReady(t *testing.T) {
+	t.Parallel()
+	_, allNodes := newTestNodes()
+	p, sleeps := newTestPoller()
+	assert.ExpectError(t, false, p.wait(context.Background(), allNodes, AllGates, time.Minute))
+	assert.DeepEqual(t, []time.Duration{}, *sleeps)
+}
+
+func TestWaitBecomesReady(t *testing.T) {
+	t.Parallel()
+	controlPlane, allNodes := newTestNodes()
+	p, sleeps := newTestPoller()
+	// the worker registers after the second check
+	checks := 0
+	sleep := p.sleep
+	p.sleep = func(ctx context.Context, d time.Duration) {
+		if checks++; checks == 2 {
+			controlPlane.Script(kubectlPrefix+"get nodes", "kind-control-plane True\nkind-worker True\n", nil)
+		}
+		sleep(ctx, d)
+	}
+	controlPlane.Script(kubectlPrefix+"get nodes", "kind-control-plane True\n", nil)
+	assert.ExpectError(t, false, p.wait(context.Background(), allNodes, []Gate{NodesReady, DefaultServiceAccount}, time.Minute))
+	assert.DeepEqual(t, []time.Duration{250 * time.Millisecond, 500 * time.Millisecond}, *sleeps)
+
+	// the service account passed on the first check, so it is checked once
+	count := 0
+	for _, c := range controlPlane.Commands() {
+		if strings.HasPrefix(c.String(), kubectlPrefix+"get serviceaccount") {
+			count++
+		}
+	}
+	assert.DeepEqual(t, 1, count)
+}
+
+func TestWaitTimeout(t *testing.T) {
+	t.Parallel()
+	controlPlane, allNodes := newTestNodes()
+	controlPlane.Script(kubectlPrefix+"get nodes", "kind-control-plane True\nkind-worker False\n", nil)
+	controlPlane.Script(kubectlPrefix+"get deployments", "coredns 2 \n", nil)
+	controlPlane.Script(kubectlPrefix+"get serviceaccount", "", errors.New("exit status 1"))
+	controlPlane.Script(kubectlPrefix+"get storageclasses", "\n", nil)
+	p, sleeps := newTestPoller()
+	err := p.wait(context.Background(), allNodes, AllGates, 10*time.Second)
+	assert.ExpectError(t, true, err)
+	assert.DeepEqual(t, []time.Duration{
+		250 * time.Millisecond,
+		500 * time.Millisecond,
+		time.Second,
+		2 * time.Second,
+		4 * time.Second,
+		2250 * time.Millisecond,
+	}, *sleeps)
+	assert.StringEqual(t, "timed out after 10s waiting for the cluster to be ready; "+
+		"nodes: not Ready: kind-worker; "+
+		"system-workloads: not available: deployment/coredns 0/2; "+
+		"default-serviceaccount: the default ServiceAccount does not exist; "+
+		"storageclass: no default StorageClass", err.Error())
+}
+
+func TestCheckNodesRegistered(t *testing.T) {
+	t.Parallel()
+	controlPlane, _ := newTestNodes()
+	controlPlane.Script(kubectlPrefix+"get nodes", "kind-control-plane True\n", nil)
+	assert.StringEqual(t, "1 of 2 nodes registered", checkNodes(context.Background(), controlPlane, 2))
+	assert.StringEqual(t, "", checkNodes(context.Background(), controlPlane, 1))
+}
+
+func TestWaitCancelled(t *testing.T) {
+	t.Parallel()
+	controlPlane, allNodes := newTestNodes()
+	controlPlane.Script(kubectlPrefix+"get nodes", "kind-control-plane True\n", nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	p, sleeps := newTestPoller()
+	// cancel while waiting for the worker to register
+	sleep := p.sleep
+	p.sleep = func(ctx context.Context, d time.Duration) {
+		cancel()
+		sleep(ctx, d)
+	}
+	err := p.wait(ctx, allNodes, AllGates, time.Minute)
+	assert.ExpectError(t, true, err)
+	assert.StringEqual(t, "stopped waiting for the cluster to be ready: context canceled", err.Error())
+	assert.DeepEqual(t, []time.Duration{250 * time.Millisecond}, *sleeps)
+}
+
+func TestClusterGates(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name     string
+		Config   string
+		Expected []Gate
+	}{
+		{
+			Name:     "config not kept on the nodes",
+			Expected: AllGates,
+		},
+		{
+			Name: "default storage class",
+			Config: `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+`,
+			Expected: AllGates,
+		},
+		{
+			Name: "no default storage class",
+			Config: `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+storage:
+  disableDefaultStorageClass: true
+`,
+			Expected: []Gate{NodesReady, SystemWorkloads, DefaultServiceAccount},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			controlPlane, allNodes := newTestNodes()
+			if tc.Config != "" {
+				controlPlane.SetFile(configaction.ClusterConfigPath, tc.Config)
+			}
+			gates, err := clusterGates(context.Background(), log.NoopLogger{}, allNodes)
+			assert.ExpectError(t, false, err)
+			assert.DeepEqual(t, tc.Expected, gates)
+		})
+	}
+}
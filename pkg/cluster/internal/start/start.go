@@ -0,0 +1,365 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package start implements starting stopped clusters
+package start
+
+import (
+	"bytes"
+	stdcontext "context"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/context"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/loadbalancer"
+	"sigs.k8s.io/kind/pkg/cluster/internal/kubeadm"
+	"sigs.k8s.io/kind/pkg/cluster/internal/kubeconfig"
+)
+
+// addressFiles are the files on kubernetes nodes that may contain node or
+// control plane endpoint addresses
+var addressFiles = []string{
+	kubeadm.ConfigPath,
+	"/var/lib/kubelet/kubeadm-flags.env",
+	"/etc/kubernetes/admin.conf",
+	"/etc/kubernetes/kubelet.conf",
+	"/etc/kubernetes/controller-manager.conf",
+	"/etc/kubernetes/scheduler.conf",
+	"/etc/kubernetes/manifests/kube-apiserver.yaml",
+	"/etc/kubernetes/manifests/etcd.yaml",
+	"/etc/kubernetes/manifests/kube-controller-manager.yaml",
+	"/etc/kubernetes/manifests/kube-scheduler.yaml",
+}
+
+// addressConfigMaps are the namespaced ConfigMaps that may contain node or
+// control plane endpoint addresses
+var addressConfigMaps = [][2]string{
+	{"kube-system", "kube-proxy"},
+	{"kube-system", "kubeadm-config"},
+	{"kube-public", "cluster-info"},
+}
+
+// servingCerts are the certificates including the node address, keyed by
+// the kubeadm init phase generating them
+var servingCerts = map[string][]string{
+	"apiserver": {
+		"/etc/kubernetes/pki/apiserver.crt",
+		"/etc/kubernetes/pki/apiserver.key",
+	},
+	"etcd-server": {
+		"/etc/kubernetes/pki/etcd/server.crt",
+		"/etc/kubernetes/pki/etcd/server.key",
+	},
+	"etcd-peer": {
+		"/etc/kubernetes/pki/etcd/peer.crt",
+		"/etc/kubernetes/pki/etcd/peer.key",
+	},
+}
+
+var (
+	controlPlaneEndpointRE = regexp.MustCompile(`controlPlaneEndpoint: "?([^"\s]+)`)
+	addressRE              = regexp.MustCompile(`[0-9]+(?:\.[0-9]+){3}|[0-9a-fA-F]*:[0-9a-fA-F:]*[0-9a-fA-F]`)
+)
+
+// Cluster starts the stopped cluster identified by ctx
+// Node addresses may change while the nodes are stopped, so anything
+// depending on them is updated: the kubeadm and kubelet configs, the
+// control plane serving certificates and manifests, the load balancer
+// backends and the kubeconfig server
+// Etcd cannot follow its members to new addresses, so clusters with
+// multiple control plane nodes fail to start if their addresses changed
+// explicitKubeconfigPath is --kubeconfig, following the rules from
+// https://kubernetes.io/docs/reference/generated/kubectl/kubectl-commands
+func Cluster(logger log.Logger, ctx *context.Context, explicitKubeconfigPath string) error {
+	n, err := ctx.ListNodes()
+	if err != nil {
+		return errors.Wrap(err, "error listing nodes")
+	}
+	if len(n) == 0 {
+		return errors.Errorf("no nodes found for cluster %q", ctx.Name())
+	}
+
+	status := cli.StatusForLogger(logger)
+	status.Start("Starting nodes 🔌")
+	if err := ctx.Provider().StartNodes(ctx.Context(), n); err != nil {
+		status.End(false)
+		return err
+	}
+	status.End(true)
+
+	// node handles may be tied to the stopped nodes
+	allNodes, err := ctx.ListNodes()
+	if err != nil {
+		return errors.Wrap(err, "error listing nodes")
+	}
+
+	status.Start("Updating node addresses 📝")
+	ipv6, err := fixupAddresses(ctx.Context(), logger, allNodes)
+	status.End(err == nil)
+	if err != nil {
+		return err
+	}
+
+	// the load balancer backends are the control plane addresses
+	ipFamily := config.IPv4Family
+	if ipv6 {
+		ipFamily = config.IPv6Family
+	}
+	cfg := &config.Cluster{Networking: config.Networking{IPFamily: ipFamily}}
+	if err := loadbalancer.NewAction().Execute(actions.NewActionContext(logger, cfg, ctx, status)); err != nil {
+		return err
+	}
+
+	return kubeconfig.Export(ctx, explicitKubeconfigPath)
+}
+
+// fixupAddresses replaces the addresses the kubernetes nodes were configured
+// with before stopping with their current addresses, returning true if the
+// nodes were configured with IPv6 addresses
+func fixupAddresses(ctx stdcontext.Context, logger log.Logger, allNodes []nodes.Node) (bool, error) {
+	endpointIPv4, endpointIPv6, err := nodeutils.GetControlPlaneEndpoint(allNodes)
+	if err != nil {
+		return false, err
+	}
+
+	// collect the old addresses of every node first, nodes may have taken
+	// each other's addresses and refer to each other
+	ipv6 := false
+	replacements := map[string]string{}
+	configured := []nodes.Node{}
+	controlPlanes := 0
+	moved := []string{}
+	for _, n := range allNodes {
+		role, err := n.Role()
+		if err != nil {
+			return false, err
+		}
+		// the load balancer is reconfigured from scratch
+		if role == constants.ExternalLoadBalancerNodeRoleValue {
+			continue
+		}
+		kubeadmConfig, err := readFile(ctx, n, kubeadm.ConfigPath)
+		if err != nil {
+			// nodes created without kubeadm config have nothing to update
+			logger.V(1).Infof("Skipping node %s without kubeadm config: %v", n.String(), err)
+			continue
+		}
+		configured = append(configured, n)
+		nodeIPv4, nodeIPv6, err := n.IP()
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to get IP for node %s", n.String())
+		}
+		if address := kubeadm.NodeAddress(kubeadmConfig); address != "" {
+			if addReplacement(replacements, address, nodeIPv4, nodeIPv6) && role == constants.ControlPlaneNodeRoleValue {
+				moved = append(moved, n.String())
+			}
+			ipv6 = ipv6 || isIPv6(address)
+		}
+		if role == constants.ControlPlaneNodeRoleValue {
+			controlPlanes++
+		}
+		if match := controlPlaneEndpointRE.FindStringSubmatch(kubeadmConfig); match != nil {
+			if host, _, err := net.SplitHostPort(match[1]); err == nil {
+				addReplacement(replacements, host, endpointHost(endpointIPv4), endpointHost(endpointIPv6))
+			}
+		}
+	}
+	if len(replacements) == 0 {
+		return ipv6, nil
+	}
+	// the etcd members only know each other by their old peer addresses
+	// so they cannot regain quorum to update them
+	if controlPlanes > 1 && len(moved) > 0 {
+		return false, errors.Errorf(
+			"the addresses of control plane nodes %s changed while stopped, clusters with multiple control plane nodes cannot be started with new addresses, please delete and recreate the cluster",
+			strings.Join(moved, ", "),
+		)
+	}
+
+	logger.V(1).Infof("Updating node addresses: %v", replacements)
+	for _, n := range configured {
+		if err := fixupNode(ctx, n, replacements); err != nil {
+			return false, err
+		}
+	}
+	bootstrap, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return false, err
+	}
+	if err := fixupConfigMaps(ctx, logger, bootstrap, replacements); err != nil {
+		return false, err
+	}
+	return ipv6, nil
+}
+
+// fixupNode rewrites the addresses on n, regenerating the serving
+// certificates of control plane nodes, and restarts the kubelet
+func fixupNode(ctx stdcontext.Context, n nodes.Node, replacements map[string]string) error {
+	for _, file := range addressFiles {
+		content, err := readFile(ctx, n, file)
+		if err != nil {
+			// not every node has every file
+			continue
+		}
+		updated := replaceAddresses(content, replacements)
+		if updated == content {
+			continue
+		}
+		if err := nodeutils.WriteFileContext(ctx, n, file, updated); err != nil {
+			return errors.Wrapf(err, "failed to update %s on node %s", file, n.String())
+		}
+	}
+
+	role, err := n.Role()
+	if err != nil {
+		return err
+	}
+	if role == constants.ControlPlaneNodeRoleValue {
+		// the kubelet recreates the static pods from the updated manifests
+		for _, phase := range []string{"apiserver", "etcd-server", "etcd-peer"} {
+			args := append([]string{"-f"}, servingCerts[phase]...)
+			if err := exec.CommandContextFor(ctx, n, "rm", args...).Run(); err != nil {
+				return errors.Wrapf(err, "failed to remove %s certificate on node %s", phase, n.String())
+			}
+			if err := exec.CommandContextFor(ctx, n,
+				"kubeadm", "init", "phase", "certs", phase, "--config", kubeadm.ConfigPath,
+			).Run(); err != nil {
+				return errors.Wrapf(err, "failed to regenerate %s certificate on node %s", phase, n.String())
+			}
+		}
+	}
+
+	if err := exec.CommandContextFor(ctx, n, "systemctl", "restart", "kubelet").Run(); err != nil {
+		return errors.Wrapf(err, "failed to restart kubelet on node %s", n.String())
+	}
+	return nil
+}
+
+// fixupConfigMaps updates the addresses in the ConfigMaps used by
+// kube-proxy and by nodes joining the cluster, then restarts kube-proxy
+func fixupConfigMaps(ctx stdcontext.Context, logger log.Logger, n nodes.Node, replacements map[string]string) error {
+	for _, configMap := range addressConfigMaps {
+		namespace, name := configMap[0], configMap[1]
+		// the API server is restarting with the kubelet
+		var content string
+		err := tryUntil(ctx, time.Now().Add(time.Minute*2), func() error {
+			var buff bytes.Buffer
+			err := exec.CommandContextFor(ctx, n,
+				"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf",
+				"get", "configmap", name, "--namespace", namespace, "-o", "yaml",
+			).SetStdout(&buff).Run()
+			content = buff.String()
+			return err
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to get configmap %s/%s", namespace, name)
+		}
+		updated := replaceAddresses(content, replacements)
+		if updated == content {
+			continue
+		}
+		logger.V(1).Infof("Updating configmap %s/%s", namespace, name)
+		if err := exec.CommandContextFor(ctx, n,
+			"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf", "replace", "-f", "-",
+		).SetStdin(strings.NewReader(updated)).Run(); err != nil {
+			return errors.Wrapf(err, "failed to update configmap %s/%s", namespace, name)
+		}
+	}
+	if err := exec.CommandContextFor(ctx, n,
+		"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf",
+		"delete", "pods", "--namespace", "kube-system", "--selector", "k8s-app=kube-proxy",
+	).Run(); err != nil {
+		return errors.Wrap(err, "failed to restart kube-proxy")
+	}
+	return nil
+}
+
+// addReplacement maps old to the new address of the same IP family,
+// unless they are equal or the new address is unknown, returning true if
+// old was mapped
+func addReplacement(replacements map[string]string, old, ipv4, ipv6 string) bool {
+	updated := ipv4
+	if isIPv6(old) {
+		updated = ipv6
+	}
+	if net.ParseIP(old) == nil || updated == "" || updated == old {
+		return false
+	}
+	replacements[old] = updated
+	return true
+}
+
+// endpointHost returns the host of endpoint, if any
+func endpointHost(endpoint string) string {
+	host, _, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return ""
+	}
+	return host
+}
+
+func isIPv6(address string) bool {
+	ip := net.ParseIP(address)
+	return ip != nil && ip.To4() == nil
+}
+
+// replaceAddresses replaces the IP addresses in s found in replacements
+// Addresses are replaced in a single pass, so swapped addresses are not
+// replaced twice
+func replaceAddresses(s string, replacements map[string]string) string {
+	return addressRE.ReplaceAllStringFunc(s, func(address string) string {
+		if updated, ok := replacements[address]; ok {
+			return updated
+		}
+		return address
+	})
+}
+
+// readFile returns the content of file on n
+func readFile(ctx stdcontext.Context, n nodes.Node, file string) (string, error) {
+	var buff bytes.Buffer
+	if err := exec.CommandContextFor(ctx, n, "cat", file).SetStdout(&buff).Run(); err != nil {
+		return "", errors.Wrapf(err, "failed to read %s on node %s", file, n.String())
+	}
+	return buff.String(), nil
+}
+
+// tryUntil calls try until it succeeds, the deadline passes or ctx is done
+func tryUntil(ctx stdcontext.Context, until time.Time, try func() error) error {
+	for {
+		err := try()
+		if err == nil || time.Now().After(until) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Second):
+		}
+	}
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package start
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/cluster/fake"
+	"sigs.k8s.io/kind/pkg/internal/apis/config/encoding"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/context"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create"
+	"sigs.k8s.io/kind/pkg/cluster/internal/kubeadm"
+	"sigs.k8s.io/kind/pkg/cluster/internal/stop"
+)
+
+const adminKubeconfig = `apiVersion: v1
+clusters:
+- cluster:
+    certificate-authority-data: definitelyacert
+    server: https://172.17.0.2:6443
+  name: kind
+contexts:
+- context:
+    cluster: kind
+    user: kubernetes-admin
+  name: kubernetes-admin@kind
+current-context: kubernetes-admin@kind
+kind: Config
+preferences: {}
+users:
+- name: kubernetes-admin
+  user:
+    client-certificate-data: seemslegit
+    client-key-data: yep
+`
+
+func TestReplaceAddresses(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name         string
+		Input        string
+		Replacements map[string]string
+		Expected     string
+	}{
+		{
+			Name:         "ipv4 addresses",
+			Input:        `node-ip: "172.17.0.2", server: https://172.17.0.3:6443`,
+			Replacements: map[string]string{"172.17.0.2": "172.17.0.5", "172.17.0.3": "172.17.0.6"},
+			Expected:     `node-ip: "172.17.0.5", server: https://172.17.0.6:6443`,
+		},
+		{
+			Name:         "longer addresses are not partially replaced",
+			Input:        "--node-ip=172.17.0.20",
+			Replacements: map[string]string{"172.17.0.2": "172.17.0.5"},
+			Expected:     "--node-ip=172.17.0.20",
+		},
+		{
+			Name:         "swapped addresses",
+			Input:        "172.17.0.2 172.17.0.3",
+			Replacements: map[string]string{"172.17.0.2": "172.17.0.3", "172.17.0.3": "172.17.0.2"},
+			Expected:     "172.17.0.3 172.17.0.2",
+		},
+		{
+			Name:         "ipv6 addresses",
+			Input:        "server: https://[fc00::2]:6443",
+			Replacements: map[string]string{"fc00::2": "fc00::5"},
+			Expected:     "server: https://[fc00::5]:6443",
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			assert.StringEqual(t, tc.Expected, replaceAddresses(tc.Input, tc.Replacements))
+		})
+	}
+}
+
+// newStoppedCluster creates the cluster kind from rawConfig with the fake
+// provider and stops it, returning the context for the cluster
+func newStoppedCluster(t *testing.T, kubeconfigPath, rawConfig string) (*fake.Provider, *context.Context) {
+	cfg, err := encoding.Parse([]byte(rawConfig))
+	assert.ExpectError(t, false, err)
+	p := fake.NewProvider()
+	p.Setup = func(n *fake.Node) {
+		n.SetFile("/kind/version", "v1.17.0\n")
+		n.SetFile("/kind/manifests/default-cni.yaml", "kind: DaemonSet\n")
+		n.SetFile("/etc/kubernetes/admin.conf", adminKubeconfig)
+		n.Script("cat /etc/kubernetes/pki/", "definitelyacert", nil)
+	}
+	ctx := context.NewProviderContext(p, "kind")
+	opts := &create.ClusterOptions{
+		Config:         cfg,
+		KubeconfigPath: kubeconfigPath,
+	}
+	assert.ExpectError(t, false, create.Cluster(log.NoopLogger{}, ctx, opts))
+	assert.ExpectError(t, false, stop.Cluster(log.NoopLogger{}, ctx))
+	for _, n := range p.Nodes("kind") {
+		assert.DeepEqual(t, false, n.Running())
+	}
+	assert.ExpectError(t, false, os.Remove(kubeconfigPath))
+	return p, ctx
+}
+
+func TestCluster(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kind-start-test")
+	assert.ExpectError(t, false, err)
+	defer os.RemoveAll(dir)
+	kubeconfigPath := filepath.Join(dir, "kubeconfig")
+	p, ctx := newStoppedCluster(t, kubeconfigPath, `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+nodes:
+- role: control-plane
+- role: worker
+`)
+	assert.ExpectError(t, false, Cluster(log.NoopLogger{}, ctx, kubeconfigPath))
+
+	for _, n := range p.Nodes("kind") {
+		assert.DeepEqual(t, true, n.Running())
+		ipv4, _, err := n.IP()
+		assert.ExpectError(t, false, err)
+		role, err := n.Role()
+		assert.ExpectError(t, false, err)
+		if role == "control-plane" {
+			// serving certificates are regenerated for the new address
+			_, ok := n.Ran("rm -f /etc/kubernetes/pki/apiserver.crt")
+			assert.DeepEqual(t, true, ok)
+			_, ok = n.Ran("kubeadm init phase certs apiserver --config /kind/kubeadm.conf")
+			assert.DeepEqual(t, true, ok)
+		}
+		kubeadmConfig, ok := n.File(kubeadm.ConfigPath)
+		assert.DeepEqual(t, true, ok)
+		if !strings.Contains(kubeadmConfig, "node-ip: "+ipv4) {
+			t.Errorf("expected node %s kubeadm config to use address %s:\n%s", n, ipv4, kubeadmConfig)
+		}
+		_, ok = n.Ran("systemctl restart kubelet")
+		assert.DeepEqual(t, true, ok)
+	}
+
+	// the kubeconfig is exported again
+	_, err = os.Stat(kubeconfigPath)
+	assert.ExpectError(t, false, err)
+}
+
+func TestClusterHA(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kind-start-test")
+	assert.ExpectError(t, false, err)
+	defer os.RemoveAll(dir)
+	kubeconfigPath := filepath.Join(dir, "kubeconfig")
+	p, ctx := newStoppedCluster(t, kubeconfigPath, `kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+nodes:
+- role: control-plane
+- role: control-plane
+- role: worker
+`)
+	// the fake provider assigns new addresses, which etcd cannot follow
+	err = Cluster(log.NoopLogger{}, ctx, kubeconfigPath)
+	assert.ExpectError(t, true, err)
+	if !strings.Contains(err.Error(), "clusters with multiple control plane nodes cannot be started with new addresses") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	// nothing was rewritten
+	for _, n := range p.Nodes("kind") {
+		if _, ok := n.Ran("systemctl restart kubelet"); ok {
+			t.Errorf("expected node %s to be left unchanged", n)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package stop implements stopping clusters without deleting them
+package stop
+
+import (
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/log"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/context"
+)
+
+// Cluster stops the nodes of the cluster identified by ctx, keeping their
+// state so that the cluster can be started again
+func Cluster(logger log.Logger, ctx *context.Context) (err error) {
+	n, err := ctx.ListNodes()
+	if err != nil {
+		return errors.Wrap(err, "error listing nodes")
+	}
+	if len(n) == 0 {
+		return errors.Errorf("no nodes found for cluster %q", ctx.Name())
+	}
+
+	status := cli.StatusForLogger(logger)
+	status.Start("Stopping nodes 🛑")
+	defer func() { status.End(err == nil) }()
+
+	return ctx.Provider().StopNodes(ctx.Context(), n)
+}
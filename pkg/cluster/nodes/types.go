@@ -24,6 +24,8 @@ import (
 type Node interface {
 	// The node should implement exec.Cmder for running commands against the node
 	// see: sigs.k8s.io/kind/pkg/exec
+	// Nodes should also implement exec.ContextCmder, so that their commands
+	// are killed once cancelled, see exec.CommandContextFor
 	exec.Cmder
 	// String should return the node name
 	String() string // see also: fmt.Stringer
@@ -34,3 +36,11 @@ type Node interface {
 	// exec or from the provider
 	IP() (ipv4 string, ipv6 string, err error)
 }
+
+// PortLister is optionally implemented by nodes whose ports are published
+// through the node provider
+type PortLister interface {
+	// Ports should return the published ports of the node in the form
+	// address:port->containerPort/protocol
+	Ports() ([]string, error)
+}
@@ -18,6 +18,7 @@ package nodeutils
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -67,13 +68,18 @@ func KubeVersion(n nodes.Node) (version string, err error) {
 
 // WriteFile writes content to dest on the node
 func WriteFile(n nodes.Node, dest, content string) error {
+	return WriteFileContext(context.Background(), n, dest, content)
+}
+
+// WriteFileContext is like WriteFile, but stops once ctx is done
+func WriteFileContext(ctx context.Context, n nodes.Node, dest, content string) error {
 	// create destination directory
-	err := n.Command("mkdir", "-p", filepath.Dir(dest)).Run()
+	err := exec.CommandContextFor(ctx, n, "mkdir", "-p", filepath.Dir(dest)).Run()
 	if err != nil {
 		return errors.Wrapf(err, "failed to create directory %s", dest)
 	}
 
-	return n.Command("cp", "/dev/stdin", dest).SetStdin(strings.NewReader(content)).Run()
+	return exec.CommandContextFor(ctx, n, "cp", "/dev/stdin", dest).SetStdin(strings.NewReader(content)).Run()
 }
 
 // CopyNodeToNode copies file from a to b
@@ -17,10 +17,14 @@ limitations under the License.
 package cluster
 
 import (
+	"context"
 	"sort"
+	"sync"
+	"time"
 
 	"sigs.k8s.io/kind/pkg/cluster/constants"
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
 	"sigs.k8s.io/kind/pkg/log"
 
 	internalcontext "sigs.k8s.io/kind/pkg/cluster/internal/context"
@@ -28,11 +32,10 @@ import (
 	internaldelete "sigs.k8s.io/kind/pkg/cluster/internal/delete"
 	"sigs.k8s.io/kind/pkg/cluster/internal/kubeconfig"
 	internallogs "sigs.k8s.io/kind/pkg/cluster/internal/logs"
-
-	// "sigs.k8s.io/kind/pkg/cluster/internal/providers/docker"
-
-	"sigs.k8s.io/kind/pkg/cluster/internal/providers/kubernetes"
 	internalprovider "sigs.k8s.io/kind/pkg/cluster/internal/providers/provider"
+	"sigs.k8s.io/kind/pkg/cluster/internal/readiness"
+	internalstart "sigs.k8s.io/kind/pkg/cluster/internal/start"
+	internalstop "sigs.k8s.io/kind/pkg/cluster/internal/stop"
 )
 
 // DefaultName is the default cluster name
@@ -40,8 +43,20 @@ const DefaultName = constants.DefaultClusterName
 
 // Provider is used to perform cluster operations
 type Provider struct {
+	// name is the explicitly selected provider name, if any
+	name string
+	// provider is the selected provider, or the default provider
 	provider internalprovider.Provider
-	logger   log.Logger
+	// providers holds every registered provider by name, for detection
+	providers map[string]internalprovider.Provider
+	// detected caches the provider detected for each cluster name
+	detectedMu sync.Mutex
+	detected   map[string]internalprovider.Provider
+	logger     log.Logger
+	// ctx cancels cluster operations, see ProviderWithContext
+	ctx context.Context
+	// options for the kubernetes provider's host cluster
+	kubernetesHost KubernetesHostOptions
 }
 
 // NewProvider returns a new provider based on the supplied options
@@ -60,9 +75,20 @@ func NewProvider(options ...ProviderOption) *Provider {
 	for _, o := range options {
 		o.apply(p)
 	}
+	p.providers = map[string]internalprovider.Provider{}
+	for name, factory := range providerFactories {
+		p.providers[name] = factory(p)
+	}
 	if p.provider == nil {
-		// p.provider = docker.NewProvider(p.logger)
-		p.provider = kubernetes.NewProvider(p.logger)
+		selected := p.name
+		if selected == "" {
+			selected = DefaultProviderName
+		}
+		if provider, ok := p.providers[selected]; ok {
+			p.provider = provider
+		} else {
+			p.provider = unknownProvider(selected)
+		}
 	}
 	return p
 }
@@ -87,9 +113,83 @@ func ProviderWithLogger(logger log.Logger) ProviderOption {
 	})
 }
 
+// providerOptionAdapter is a trivial ProviderOption adapter
+type providerOptionAdapter func(p *Provider)
+
+func (a providerOptionAdapter) apply(p *Provider) {
+	a(p)
+}
+
+// ProviderWithContext configures the provider to stop cluster operations
+// once ctx is done, E.G. on Ctrl-C
+// A cluster whose creation is cancelled is deleted, unless it is retained
+func ProviderWithContext(ctx context.Context) ProviderOption {
+	return providerOptionAdapter(func(p *Provider) {
+		p.ctx = ctx
+	})
+}
+
+// KubernetesHostOptions selects the host cluster used to run "node" pods
+// with the kubernetes provider
+// Existing clusters are found in any namespace the host credentials can
+// list, and keep the options other than Kubeconfig and Context they were
+// created with
+type KubernetesHostOptions struct {
+	// Kubeconfig is the path to the host cluster kubeconfig
+	// If unset the default kubectl loading rules are used
+	Kubeconfig string
+	// Context is the host kubeconfig context to use
+	// If unset the current context is used
+	Context string
+	// Namespace is the host namespace to create nodes in
+	// If unset the namespace of the context is used, or "default"
+	Namespace string
+	// NamespacePerCluster creates each cluster in its own host namespace
+	// named kind-<cluster>, which is created and deleted along with the cluster
+	NamespacePerCluster bool
+	// ServiceType is the type of the Services exposing the API server and
+	// ExtraPortMappings of nodes, either NodePort or LoadBalancer
+	// Defaults to NodePort
+	ServiceType string
+	// PersistentStorage runs each node as a single replica StatefulSet with
+	// /var on a PersistentVolumeClaim, so that nodes survive pod restarts on
+	// the same IP, and can be stopped and started
+	// Node pods rescheduled with a new IP need a `kind start` to update the
+	// cluster, which fails for clusters with multiple control plane nodes
+	PersistentStorage bool
+	// StorageClass is the StorageClass of the node claims
+	// If unset the host cluster default StorageClass is used
+	StorageClass string
+	// StorageSize is the requested size of the node claims
+	// Defaults to 20Gi
+	StorageSize string
+}
+
+// ProviderWithKubernetesHost configures the host cluster used by the
+// kubernetes provider
+func ProviderWithKubernetesHost(opts KubernetesHostOptions) ProviderOption {
+	return providerOptionAdapter(func(p *Provider) {
+		p.kubernetesHost = opts
+	})
+}
+
 // TODO: remove this, rename internal context to something else
 func (p *Provider) ic(name string) *internalcontext.Context {
-	return internalcontext.NewProviderContext(p.provider, name)
+	provider, err := p.providerFor(name)
+	if err != nil {
+		provider = errorProvider{err: err}
+	}
+	return p.newContext(provider, name)
+}
+
+// newContext returns the internal context for the cluster name operated on
+// with provider, cancelled along with p.ctx if set
+func (p *Provider) newContext(provider internalprovider.Provider, name string) *internalcontext.Context {
+	c := internalcontext.NewProviderContext(provider, name)
+	if p.ctx != nil {
+		c = c.WithContext(p.ctx)
+	}
+	return c
 }
 
 // Create provisions and starts a kubernetes-in-docker cluster
@@ -101,7 +201,17 @@ func (p *Provider) Create(name string, options ...CreateOption) error {
 			return err
 		}
 	}
-	return internalcreate.Cluster(p.logger, p.ic(name), opts)
+	// new clusters are always created with the selected provider, resumed
+	// clusters with the provider that created them
+	provider := p.provider
+	if opts.Resume {
+		detected, err := p.providerFor(name)
+		if err != nil {
+			return err
+		}
+		provider = detected
+	}
+	return internalcreate.Cluster(p.logger, p.newContext(provider, name), opts)
 }
 
 // Delete tears down a kubernetes-in-docker cluster
@@ -109,9 +219,66 @@ func (p *Provider) Delete(name, explicitKubeconfigPath string) error {
 	return internaldelete.Cluster(p.logger, p.ic(name), explicitKubeconfigPath)
 }
 
+// CreateNode adds a node to the existing cluster with name, returning the
+// name of the new node
+func (p *Provider) CreateNode(name string, options ...CreateNodeOption) (string, error) {
+	opts := &internalcreate.NodeOptions{
+		Role: config.WorkerRole,
+	}
+	for _, o := range options {
+		if err := o.apply(opts); err != nil {
+			return "", err
+		}
+	}
+	return internalcreate.Node(p.logger, p.ic(name), opts)
+}
+
+// DeleteNode removes the node with nodeName from the cluster with name
+func (p *Provider) DeleteNode(name, nodeName string) error {
+	return internaldelete.Node(p.logger, p.ic(name), nodeName)
+}
+
+// Stop stops the nodes of the cluster with name without deleting them
+func (p *Provider) Stop(name string) error {
+	return internalstop.Cluster(p.logger, p.ic(name))
+}
+
+// Start starts the stopped cluster with name, updating anything depending
+// on node addresses that changed while the nodes were stopped
+// explicitKubeconfigPath is --kubeconfig, following the rules from
+// https://kubernetes.io/docs/reference/generated/kubectl/kubectl-commands
+func (p *Provider) Start(name, explicitKubeconfigPath string) error {
+	return internalstart.Cluster(p.logger, p.ic(name), explicitKubeconfigPath)
+}
+
+// Wait waits up to timeout for the cluster with name to pass the readiness
+// gates, see ReadinessGateNames. If none are given, the gates that can pass
+// for the config the cluster was created with are used
+// The returned error summarizes the gates that did not pass
+func (p *Provider) Wait(name string, timeout time.Duration, gates ...string) error {
+	parsed, err := readiness.ParseGates(gates)
+	if err != nil {
+		return err
+	}
+	if len(parsed) == 0 {
+		parsed = nil
+	}
+	return readiness.Cluster(p.logger, p.ic(name), parsed, timeout)
+}
+
+// ReadinessGateNames returns the names of the readiness gates, in the order
+// they are checked
+func ReadinessGateNames() []string {
+	names := []string{}
+	for _, gate := range readiness.AllGates {
+		names = append(names, string(gate))
+	}
+	return names
+}
+
 // List returns a list of clusters for which nodes exist
 func (p *Provider) List() ([]string, error) {
-	return p.provider.ListClusters()
+	return p.listClusters()
 }
 
 // KubeConfig returns the KUBECONFIG for the cluster
@@ -0,0 +1,246 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"sort"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/cli"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/docker"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/kubernetes"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/podman"
+	internalprovider "sigs.k8s.io/kind/pkg/cluster/internal/providers/provider"
+)
+
+// DefaultProviderName is the provider used to create clusters when none is
+// selected with ProviderWithName
+const DefaultProviderName = "kubernetes"
+
+// providerFactory creates a named internal provider configured from p
+type providerFactory func(p *Provider) internalprovider.Provider
+
+// providerFactories is the registry of named providers
+var providerFactories = map[string]providerFactory{
+	"docker": func(p *Provider) internalprovider.Provider {
+		return docker.NewProvider(p.logger)
+	},
+	"kubernetes": func(p *Provider) internalprovider.Provider {
+		return kubernetes.NewProvider(p.logger, kubernetes.Options{
+			Kubeconfig:          p.kubernetesHost.Kubeconfig,
+			Context:             p.kubernetesHost.Context,
+			Namespace:           p.kubernetesHost.Namespace,
+			NamespacePerCluster: p.kubernetesHost.NamespacePerCluster,
+			ServiceType:         p.kubernetesHost.ServiceType,
+			PersistentStorage:   p.kubernetesHost.PersistentStorage,
+			StorageClass:        p.kubernetesHost.StorageClass,
+			StorageSize:         p.kubernetesHost.StorageSize,
+		})
+	},
+	"podman": func(p *Provider) internalprovider.Provider {
+		return podman.NewProvider(p.logger)
+	},
+}
+
+// ProviderNames returns the sorted names of the registered providers
+func ProviderNames() []string {
+	names := make([]string, 0, len(providerFactories))
+	for name := range providerFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ProviderWithName configures the provider to use the registered provider
+// name, see ProviderNames
+// If unset, clusters are created with DefaultProviderName and existing
+// clusters are operated on with the provider that created them
+func ProviderWithName(name string) ProviderOption {
+	return providerOptionAdapter(func(p *Provider) {
+		p.name = name
+	})
+}
+
+// ProviderWithFake configures the provider to use f instead of a registered
+// provider, for testing
+// f is usually a *sigs.k8s.io/kind/pkg/cluster/fake.Provider, which is only
+// imported by tests
+func ProviderWithFake(f internalprovider.Provider) ProviderOption {
+	return providerOptionAdapter(func(p *Provider) {
+		p.name = "fake"
+		p.provider = f
+	})
+}
+
+// providerFor returns the internal provider for the cluster name
+// If no provider was explicitly selected, this is the first registered
+// provider listing the cluster, which is remembered for later operations
+// Clusters no provider lists use the default provider, unless listing the
+// clusters of any provider failed
+func (p *Provider) providerFor(name string) (internalprovider.Provider, error) {
+	if p.name != "" {
+		return p.provider, nil
+	}
+	p.detectedMu.Lock()
+	defer p.detectedMu.Unlock()
+	if provider, ok := p.detected[name]; ok {
+		return provider, nil
+	}
+	errs := []error{}
+	for _, providerName := range p.detectionOrder() {
+		clusters, err := p.providers[providerName].ListClusters()
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to list %s provider clusters", providerName))
+			continue
+		}
+		for _, cluster := range clusters {
+			if cluster == name {
+				p.logger.V(1).Infof("Detected %s provider for cluster %q", providerName, name)
+				if p.detected == nil {
+					p.detected = map[string]internalprovider.Provider{}
+				}
+				p.detected[name] = p.providers[providerName]
+				return p.providers[providerName], nil
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Wrapf(
+			errors.NewAggregate(errs),
+			"failed to detect the provider of cluster %q, select it explicitly to skip detection", name,
+		)
+	}
+	return p.provider, nil
+}
+
+// listClusters returns the clusters of the selected provider, or of all
+// registered providers if none was explicitly selected
+// Providers that fail to list clusters are ignored, unless all of them fail
+func (p *Provider) listClusters() ([]string, error) {
+	if p.name != "" {
+		return p.provider.ListClusters()
+	}
+	seen := map[string]bool{}
+	clusters := []string{}
+	var firstErr error
+	failed := 0
+	for _, providerName := range p.detectionOrder() {
+		list, err := p.providers[providerName].ListClusters()
+		if err != nil {
+			p.logger.V(1).Infof("Failed to list %s provider clusters: %v", providerName, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			failed++
+			continue
+		}
+		for _, cluster := range list {
+			if !seen[cluster] {
+				seen[cluster] = true
+				clusters = append(clusters, cluster)
+			}
+		}
+	}
+	if failed == len(p.providers) {
+		return nil, firstErr
+	}
+	sort.Strings(clusters)
+	return clusters, nil
+}
+
+// detectionOrder returns the names of p.providers, starting with the
+// default provider and followed by the others sorted by name
+func (p *Provider) detectionOrder() []string {
+	names := []string{}
+	if _, ok := p.providers[DefaultProviderName]; ok {
+		names = append(names, DefaultProviderName)
+	}
+	others := []string{}
+	for name := range p.providers {
+		if name != DefaultProviderName {
+			others = append(others, name)
+		}
+	}
+	sort.Strings(others)
+	return append(names, others...)
+}
+
+// errorProvider fails every operation with err, it is used for names
+// missing from the registry and clusters whose provider cannot be detected,
+// so that the error surfaces on first use
+type errorProvider struct {
+	err error
+}
+
+var _ internalprovider.Provider = errorProvider{}
+
+// unknownProvider returns the provider for name missing from the registry
+func unknownProvider(name string) errorProvider {
+	return errorProvider{
+		err: errors.Errorf("unknown provider %q, expected one of %v", name, ProviderNames()),
+	}
+}
+
+// Provision is part of the providers.Provider interface
+func (e errorProvider) Provision(ctx context.Context, status *cli.Status, cluster string, cfg *config.Cluster) error {
+	return e.err
+}
+
+// ProvisionDryRun is part of the providers.Provider interface
+func (e errorProvider) ProvisionDryRun(cluster string, cfg *config.Cluster) (string, error) {
+	return "", e.err
+}
+
+// ProvisionNode is part of the providers.Provider interface
+func (e errorProvider) ProvisionNode(ctx context.Context, status *cli.Status, cluster, name string, cfg *config.Cluster, node *config.Node) error {
+	return e.err
+}
+
+// ListClusters is part of the providers.Provider interface
+func (e errorProvider) ListClusters() ([]string, error) {
+	return nil, e.err
+}
+
+// ListNodes is part of the providers.Provider interface
+func (e errorProvider) ListNodes(cluster string) ([]nodes.Node, error) {
+	return nil, e.err
+}
+
+// DeleteNodes is part of the providers.Provider interface
+func (e errorProvider) DeleteNodes(context.Context, []nodes.Node) error {
+	return e.err
+}
+
+// StopNodes is part of the providers.Provider interface
+func (e errorProvider) StopNodes(context.Context, []nodes.Node) error {
+	return e.err
+}
+
+// StartNodes is part of the providers.Provider interface
+func (e errorProvider) StartNodes(context.Context, []nodes.Node) error {
+	return e.err
+}
+
+// GetAPIServerEndpoint is part of the providers.Provider interface
+func (e errorProvider) GetAPIServerEndpoint(cluster string) (string, error) {
+	return "", e.err
+}
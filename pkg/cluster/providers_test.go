@@ -0,0 +1,291 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/cluster/fake"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+	"sigs.k8s.io/kind/pkg/internal/cli"
+	"sigs.k8s.io/kind/pkg/log"
+
+	internalprovider "sigs.k8s.io/kind/pkg/cluster/internal/providers/provider"
+)
+
+// listingProvider is an internal provider that only lists clusters
+type listingProvider struct {
+	clusters []string
+	err      error
+}
+
+func (l *listingProvider) Provision(ctx context.Context, status *cli.Status, cluster string, cfg *config.Cluster) error {
+	return nil
+}
+
+func (l *listingProvider) ProvisionDryRun(cluster string, cfg *config.Cluster) (string, error) {
+	return "", nil
+}
+
+func (l *listingProvider) ProvisionNode(ctx context.Context, status *cli.Status, cluster, name string, cfg *config.Cluster, node *config.Node) error {
+	return nil
+}
+
+func (l *listingProvider) ListClusters() ([]string, error) {
+	return l.clusters, l.err
+}
+
+func (l *listingProvider) ListNodes(cluster string) ([]nodes.Node, error) {
+	return nil, nil
+}
+
+func (l *listingProvider) DeleteNodes(context.Context, []nodes.Node) error {
+	return nil
+}
+
+func (l *listingProvider) StopNodes(context.Context, []nodes.Node) error {
+	return nil
+}
+
+func (l *listingProvider) StartNodes(context.Context, []nodes.Node) error {
+	return nil
+}
+
+func (l *listingProvider) GetAPIServerEndpoint(cluster string) (string, error) {
+	return "", nil
+}
+
+func newTestProvider(name string, providers map[string]internalprovider.Provider) *Provider {
+	p := &Provider{
+		name:      name,
+		providers: providers,
+		logger:    log.NoopLogger{},
+	}
+	selected := name
+	if selected == "" {
+		selected = DefaultProviderName
+	}
+	p.provider = providers[selected]
+	return p
+}
+
+func TestProviderNames(t *testing.T) {
+	t.Parallel()
+	assert.DeepEqual(t, []string{"docker", "kubernetes", "podman"}, ProviderNames())
+}
+
+func TestNewProviderWithName(t *testing.T) {
+	t.Parallel()
+	p := NewProvider(ProviderWithName("docker"))
+	assert.DeepEqual(t, p.providers["docker"], p.provider)
+
+	// unknown providers fail on use
+	p = NewProvider(ProviderWithName("unknown"))
+	_, err := p.List()
+	assert.ExpectError(t, true, err)
+}
+
+func TestProviderFor(t *testing.T) {
+	t.Parallel()
+	kubernetes := &listingProvider{clusters: []string{"kind"}}
+	docker := &listingProvider{clusters: []string{"kind", "other"}}
+	broken := &listingProvider{err: errors.New("unavailable")}
+	cases := []struct {
+		Name        string
+		Selected    string
+		Cluster     string
+		Broken      bool
+		Expected    internalprovider.Provider
+		ExpectError bool
+	}{
+		{
+			Name:     "default provider is preferred",
+			Cluster:  "kind",
+			Broken:   true,
+			Expected: kubernetes,
+		},
+		{
+			Name:     "cluster of another provider",
+			Cluster:  "other",
+			Broken:   true,
+			Expected: docker,
+		},
+		{
+			Name:     "missing cluster uses the default provider",
+			Cluster:  "missing",
+			Expected: kubernetes,
+		},
+		{
+			Name:        "missing cluster with a failing provider",
+			Cluster:     "missing",
+			Broken:      true,
+			ExpectError: true,
+		},
+		{
+			Name:     "explicitly selected provider",
+			Selected: "docker",
+			Cluster:  "missing",
+			Broken:   true,
+			Expected: docker,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			providers := map[string]internalprovider.Provider{
+				DefaultProviderName: kubernetes,
+				"docker":            docker,
+			}
+			if tc.Broken {
+				providers["broken"] = broken
+			}
+			p := newTestProvider(tc.Selected, providers)
+			provider, err := p.providerFor(tc.Cluster)
+			assert.ExpectError(t, tc.ExpectError, err)
+			if provider != tc.Expected {
+				t.Errorf("unexpected provider for cluster %q", tc.Cluster)
+			}
+		})
+	}
+}
+
+func TestProviderForCachesDetection(t *testing.T) {
+	t.Parallel()
+	docker := &countingProvider{listingProvider: listingProvider{clusters: []string{"kind"}}}
+	p := newTestProvider("", map[string]internalprovider.Provider{
+		DefaultProviderName: &listingProvider{},
+		"docker":            docker,
+	})
+	for i := 0; i < 3; i++ {
+		provider, err := p.providerFor("kind")
+		assert.ExpectError(t, false, err)
+		if provider != docker {
+			t.Errorf("unexpected provider for cluster %q", "kind")
+		}
+	}
+	assert.DeepEqual(t, 1, docker.lists)
+}
+
+// countingProvider is a listingProvider counting the calls to ListClusters
+type countingProvider struct {
+	listingProvider
+	lists int
+}
+
+func (c *countingProvider) ListClusters() ([]string, error) {
+	c.lists++
+	return c.listingProvider.ListClusters()
+}
+
+func TestListClusters(t *testing.T) {
+	t.Parallel()
+	broken := &listingProvider{err: errors.New("unavailable")}
+	p := newTestProvider("", map[string]internalprovider.Provider{
+		DefaultProviderName: &listingProvider{clusters: []string{"kind", "b"}},
+		"docker":            &listingProvider{clusters: []string{"kind", "a"}},
+		"broken":            broken,
+	})
+	clusters, err := p.List()
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, []string{"a", "b", "kind"}, clusters)
+
+	// an explicitly selected provider is not ignored when it fails
+	p = newTestProvider("broken", p.providers)
+	_, err = p.List()
+	assert.ExpectError(t, true, err)
+
+	// the error is returned when every provider fails
+	p = newTestProvider("", map[string]internalprovider.Provider{
+		DefaultProviderName: broken,
+	})
+	_, err = p.List()
+	assert.ExpectError(t, true, err)
+}
+
+func TestProviderWithFake(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kind-provider-test")
+	assert.ExpectError(t, false, err)
+	defer os.RemoveAll(dir)
+	kubeconfig := filepath.Join(dir, "kubeconfig")
+
+	f := fake.NewProvider()
+	f.Setup = func(n *fake.Node) {
+		n.SetFile("/kind/version", "v1.17.0")
+	}
+	p := NewProvider(ProviderWithFake(f))
+	assert.ExpectError(t, false, p.Create("test",
+		CreateWithRawConfig([]byte("kind: Cluster\napiVersion: kind.x-k8s.io/v1alpha4\nnodes:\n- role: control-plane\n- role: worker\n")),
+		CreateWithStopBeforeSettingUpKubernetes(true),
+		CreateWithKubeconfigPath(kubeconfig),
+	))
+	clusters, err := p.List()
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, []string{"test"}, clusters)
+	n, err := p.ListNodes("test")
+	assert.ExpectError(t, false, err)
+	names := []string{}
+	for _, node := range n {
+		names = append(names, node.String())
+	}
+	assert.DeepEqual(t, []string{"test-control-plane", "test-worker"}, names)
+
+	assert.ExpectError(t, false, p.Delete("test", kubeconfig))
+	clusters, err = p.List()
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, []string{}, clusters)
+}
+
+func TestCreateResumeDetectsProvider(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kind-provider-test")
+	assert.ExpectError(t, false, err)
+	defer os.RemoveAll(dir)
+	kubeconfig := filepath.Join(dir, "kubeconfig")
+	rawConfig := []byte("kind: Cluster\napiVersion: kind.x-k8s.io/v1alpha4\n")
+
+	// the cluster is partially created by a provider other than the default
+	created := fake.NewProvider()
+	created.Setup = func(n *fake.Node) {
+		n.SetFile("/kind/version", "v1.17.0")
+	}
+	assert.ExpectError(t, false, NewProvider(ProviderWithFake(created)).Create("test",
+		CreateWithRawConfig(rawConfig),
+		CreateWithStopBeforeSettingUpKubernetes(true),
+		CreateWithRetain(true),
+		CreateWithKubeconfigPath(kubeconfig),
+	))
+
+	p := newTestProvider("", map[string]internalprovider.Provider{
+		DefaultProviderName: fake.NewProvider(),
+		"docker":            created,
+	})
+	assert.ExpectError(t, false, p.Create("test",
+		CreateWithRawConfig(rawConfig),
+		CreateWithStopBeforeSettingUpKubernetes(true),
+		CreateWithResume(true),
+		CreateWithKubeconfigPath(kubeconfig),
+	))
+}
@@ -18,16 +18,21 @@ limitations under the License.
 package cluster
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 
 	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cluster/events"
 	"sigs.k8s.io/kind/pkg/cmd"
 	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
 	"sigs.k8s.io/kind/pkg/log"
 )
 
@@ -36,12 +41,16 @@ type flagpole struct {
 	Config     string
 	ImageName  string
 	Retain     bool
+	Resume     bool
 	Wait       time.Duration
+	Gates      []string
 	Kubeconfig string
+	DryRun     bool
+	EventsFile string
 }
 
 // NewCommand returns a new cobra.Command for cluster creation
-func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+func NewCommand(rt *runtime.Runtime, logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 	flags := &flagpole{}
 	cmd := &cobra.Command{
 		Args:  cobra.NoArgs,
@@ -49,50 +58,86 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 		Short: "Creates a local Kubernetes cluster",
 		Long:  "Creates a local Kubernetes cluster using Docker container 'nodes'",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runE(logger, streams, flags)
+			return runE(rt, logger, streams, flags)
 		},
 	}
 	cmd.Flags().StringVar(&flags.Name, "name", cluster.DefaultName, "cluster context name")
 	cmd.Flags().StringVar(&flags.Config, "config", "", "path to a kind config file")
 	cmd.Flags().StringVar(&flags.ImageName, "image", "", "node docker image to use for booting the cluster")
 	cmd.Flags().BoolVar(&flags.Retain, "retain", false, "retain nodes for debugging when cluster creation fails")
-	cmd.Flags().DurationVar(&flags.Wait, "wait", time.Duration(0), "Wait for control plane node to be ready (default 0s)")
+	cmd.Flags().BoolVar(&flags.Resume, "resume", false, "resume creating the retained nodes of a cluster whose creation failed")
+	cmd.Flags().DurationVar(&flags.Wait, "wait", time.Duration(0), "Wait for the cluster to be ready (default 0s)")
+	cmd.Flags().StringSliceVar(&flags.Gates, "readiness-gates", nil, fmt.Sprintf("readiness gates to wait for, any of %v (default all that apply to the cluster)", cluster.ReadinessGateNames()))
 	cmd.Flags().StringVar(&flags.Kubeconfig, "kubeconfig", "", "sets kubeconfig path instead of $KUBECONFIG or $HOME/.kube/config")
+	cmd.Flags().BoolVar(&flags.DryRun, "dry-run", false, "print the cluster config, node config files and provider objects without creating anything")
+	cmd.Flags().StringVar(&flags.EventsFile, "events-file", "", "write an event for each phase of creation to this file as JSON lines")
+	rt.AddHostCreateFlags(cmd)
 	return cmd
 }
 
-func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
-	provider := cluster.NewProvider(
-		cluster.ProviderWithLogger(logger),
-	)
+func runE(rt *runtime.Runtime, logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
+	provider := cluster.NewProvider(rt.ProviderOptions(logger)...)
 
-	// Check if the cluster name already exists
-	n, err := provider.ListNodes(flags.Name)
+	// handle config flag, we might need to read from stdin
+	withConfig, err := configOption(flags.Config, streams.In)
 	if err != nil {
 		return err
 	}
-	if len(n) != 0 {
-		return fmt.Errorf("node(s) already exist for a cluster with the name %q", flags.Name)
+
+	// only print what would be created
+	if flags.DryRun {
+		return provider.Create(
+			flags.Name,
+			withConfig,
+			cluster.CreateWithNodeImage(flags.ImageName),
+			cluster.CreateWithDryRun(streams.Out),
+		)
 	}
 
-	// handle config flag, we might need to read from stdin
-	withConfig, err := configOption(flags.Config, streams.In)
-	if err != nil {
-		return err
+	// Check if the cluster name already exists, unless resuming its creation
+	if !flags.Resume {
+		n, err := provider.ListNodes(flags.Name)
+		if err != nil {
+			return err
+		}
+		if len(n) != 0 {
+			return fmt.Errorf("node(s) already exist for a cluster with the name %q", flags.Name)
+		}
 	}
 
 	// create the cluster
-	logger.V(0).Infof("Creating cluster %q ...\n", flags.Name)
-	if err = provider.Create(
-		flags.Name,
+	if flags.Resume {
+		logger.V(0).Infof("Resuming the creation of cluster %q ...\n", flags.Name)
+	} else {
+		logger.V(0).Infof("Creating cluster %q ...\n", flags.Name)
+	}
+	// time the phases of creation for the summary and the events file
+	summary := &events.Summary{}
+	options := []cluster.CreateOption{
 		withConfig,
 		cluster.CreateWithNodeImage(flags.ImageName),
 		cluster.CreateWithRetain(flags.Retain),
+		cluster.CreateWithResume(flags.Resume),
 		cluster.CreateWithWaitForReady(flags.Wait),
 		cluster.CreateWithKubeconfigPath(flags.Kubeconfig),
 		cluster.CreateWithDisplayUsage(true),
 		cluster.CreateWithDisplaySalutation(true),
-	); err != nil {
+		cluster.CreateWithEventHandler(summary.Handle),
+	}
+	if len(flags.Gates) > 0 {
+		options = append(options, cluster.CreateWithReadinessGates(flags.Gates...))
+	}
+	if flags.EventsFile != "" {
+		f, err := os.Create(flags.EventsFile)
+		if err != nil {
+			return errors.Wrap(err, "failed to create events file")
+		}
+		defer f.Close()
+		options = append(options, cluster.CreateWithEventHandler(events.JSONLines(f)))
+	}
+	err = provider.Create(flags.Name, options...)
+	logSummary(logger, summary)
+	if err != nil {
 		if errs := errors.Errors(err); errs != nil {
 			for _, problem := range errs {
 				logger.Errorf("%v", problem)
@@ -105,6 +150,19 @@ func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
 	return nil
 }
 
+// logSummary logs a table of the phases of creation and their durations
+func logSummary(logger log.Logger, summary *events.Summary) {
+	if len(summary.Events()) == 0 {
+		return
+	}
+	var table bytes.Buffer
+	if err := summary.WriteTable(&table); err != nil {
+		return
+	}
+	logger.V(0).Info("")
+	logger.V(0).Info(strings.TrimSuffix(table.String(), "\n"))
+}
+
 // configOption converts the raw --config flag value to a cluster creation
 // option matching it. it will read from stdin if the flag value is `-`
 func configOption(rawConfigFlag string, stdin io.Reader) (cluster.CreateOption, error) {
@@ -22,17 +22,21 @@ import (
 
 	"sigs.k8s.io/kind/pkg/cmd"
 	createcluster "sigs.k8s.io/kind/pkg/cmd/kind/create/cluster"
+	createnode "sigs.k8s.io/kind/pkg/cmd/kind/create/node"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
 	"sigs.k8s.io/kind/pkg/log"
 )
 
 // NewCommand returns a new cobra.Command for cluster creation
-func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+func NewCommand(rt *runtime.Runtime, logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 	cmd := &cobra.Command{
 		Args:  cobra.NoArgs,
 		Use:   "create",
-		Short: "Creates one of [cluster]",
-		Long:  "Creates one of local Kubernetes cluster (cluster)",
+		Short: "Creates one of [cluster, node]",
+		Long:  "Creates one of local Kubernetes cluster (cluster) or node of a cluster (node)",
 	}
-	cmd.AddCommand(createcluster.NewCommand(logger, streams))
+	rt.AddHostFlags(cmd)
+	cmd.AddCommand(createcluster.NewCommand(rt, logger, streams))
+	cmd.AddCommand(createnode.NewCommand(rt, logger, streams))
 	return cmd
 }
@@ -0,0 +1,70 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package node implements the `create node` command
+package node
+
+import (
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+type flagpole struct {
+	Name      string
+	Role      string
+	ImageName string
+	Retain    bool
+}
+
+// NewCommand returns a new cobra.Command for adding a node to a cluster
+func NewCommand(rt *runtime.Runtime, logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "node",
+		Short: "Adds a node to an existing cluster",
+		Long:  "Provisions a new node and joins it to an existing local Kubernetes cluster",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(rt, logger, streams, flags)
+		},
+	}
+	cmd.Flags().StringVar(&flags.Name, "name", cluster.DefaultName, "the cluster name")
+	cmd.Flags().StringVar(&flags.Role, "role", "worker", "the node role, worker or control-plane, control-plane nodes can only be added to clusters with multiple control-plane nodes")
+	cmd.Flags().StringVar(&flags.ImageName, "image", "", "node docker image to use for booting the node, defaults to the image of the existing nodes")
+	cmd.Flags().BoolVar(&flags.Retain, "retain", false, "retain the node for debugging if it fails to join the cluster")
+	return cmd
+}
+
+func runE(rt *runtime.Runtime, logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
+	logger.V(0).Infof("Adding a %s node to cluster %q ...\n", flags.Role, flags.Name)
+	provider := cluster.NewProvider(rt.ProviderOptions(logger)...)
+	name, err := provider.CreateNode(
+		flags.Name,
+		cluster.CreateNodeWithRole(flags.Role),
+		cluster.CreateNodeWithNodeImage(flags.ImageName),
+		cluster.CreateNodeWithRetain(flags.Retain),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create node")
+	}
+	logger.V(0).Infof("Added node %q", name)
+	return nil
+}
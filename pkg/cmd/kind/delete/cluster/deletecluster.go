@@ -23,6 +23,7 @@ import (
 
 	"sigs.k8s.io/kind/pkg/cluster"
 	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
 	"sigs.k8s.io/kind/pkg/log"
 )
 
@@ -32,7 +33,7 @@ type flagpole struct {
 }
 
 // NewCommand returns a new cobra.Command for cluster creation
-func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+func NewCommand(rt *runtime.Runtime, logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 	flags := &flagpole{}
 	cmd := &cobra.Command{
 		Args: cobra.NoArgs,
@@ -41,7 +42,7 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 		Short: "Deletes a cluster",
 		Long:  "Deletes a resource",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runE(logger, flags)
+			return runE(rt, logger, flags)
 		},
 	}
 	cmd.Flags().StringVar(&flags.Name, "name", cluster.DefaultName, "the cluster name")
@@ -49,12 +50,10 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 	return cmd
 }
 
-func runE(logger log.Logger, flags *flagpole) error {
+func runE(rt *runtime.Runtime, logger log.Logger, flags *flagpole) error {
 	// Delete the cluster
 	logger.V(0).Infof("Deleting cluster %q ...\n", flags.Name)
-	provider := cluster.NewProvider(
-		cluster.ProviderWithLogger(logger),
-	)
+	provider := cluster.NewProvider(rt.ProviderOptions(logger)...)
 	if err := provider.Delete(flags.Name, flags.Kubeconfig); err != nil {
 		return errors.Wrap(err, "failed to delete cluster")
 	}
@@ -22,18 +22,22 @@ import (
 
 	"sigs.k8s.io/kind/pkg/cmd"
 	deletecluster "sigs.k8s.io/kind/pkg/cmd/kind/delete/cluster"
+	deletenode "sigs.k8s.io/kind/pkg/cmd/kind/delete/node"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
 	"sigs.k8s.io/kind/pkg/log"
 )
 
 // NewCommand returns a new cobra.Command for cluster creation
-func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+func NewCommand(rt *runtime.Runtime, logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 	cmd := &cobra.Command{
 		Args: cobra.NoArgs,
 		// TODO(bentheelder): more detailed usage
 		Use:   "delete",
-		Short: "Deletes one of [cluster]",
-		Long:  "Deletes one of [cluster]",
+		Short: "Deletes one of [cluster, node]",
+		Long:  "Deletes one of [cluster, node]",
 	}
-	cmd.AddCommand(deletecluster.NewCommand(logger, streams))
+	rt.AddHostFlags(cmd)
+	cmd.AddCommand(deletecluster.NewCommand(rt, logger, streams))
+	cmd.AddCommand(deletenode.NewCommand(rt, logger, streams))
 	return cmd
 }
@@ -0,0 +1,57 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package node implements the `delete node` command
+package node
+
+import (
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+type flagpole struct {
+	Name string
+}
+
+// NewCommand returns a new cobra.Command for removing a node from a cluster
+func NewCommand(rt *runtime.Runtime, logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.ExactArgs(1),
+		Use:   "node <node>",
+		Short: "Removes a node from a cluster",
+		Long:  "Drains a node and removes it from Kubernetes, then deletes the node",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(rt, logger, flags, args[0])
+		},
+	}
+	cmd.Flags().StringVar(&flags.Name, "name", cluster.DefaultName, "the cluster name")
+	return cmd
+}
+
+func runE(rt *runtime.Runtime, logger log.Logger, flags *flagpole, node string) error {
+	logger.V(0).Infof("Deleting node %q from cluster %q ...\n", node, flags.Name)
+	provider := cluster.NewProvider(rt.ProviderOptions(logger)...)
+	if err := provider.DeleteNode(flags.Name, node); err != nil {
+		return errors.Wrap(err, "failed to delete node")
+	}
+	return nil
+}
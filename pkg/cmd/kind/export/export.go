@@ -23,11 +23,12 @@ import (
 	"sigs.k8s.io/kind/pkg/cmd"
 	"sigs.k8s.io/kind/pkg/cmd/kind/export/kubeconfig"
 	"sigs.k8s.io/kind/pkg/cmd/kind/export/logs"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
 	"sigs.k8s.io/kind/pkg/log"
 )
 
 // NewCommand returns a new cobra.Command for export
-func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+func NewCommand(rt *runtime.Runtime, logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 	cmd := &cobra.Command{
 		Args: cobra.NoArgs,
 		// TODO(bentheelder): more detailed usage
@@ -36,7 +37,8 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 		Long:  "exports one of [kubeconfig, logs]",
 	}
 	// add subcommands
-	cmd.AddCommand(logs.NewCommand(logger, streams))
-	cmd.AddCommand(kubeconfig.NewCommand(logger, streams))
+	rt.AddHostFlags(cmd)
+	cmd.AddCommand(logs.NewCommand(rt, logger, streams))
+	cmd.AddCommand(kubeconfig.NewCommand(rt, logger, streams))
 	return cmd
 }
@@ -22,6 +22,7 @@ import (
 
 	"sigs.k8s.io/kind/pkg/cluster"
 	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
 	"sigs.k8s.io/kind/pkg/log"
 )
 
@@ -31,7 +32,7 @@ type flagpole struct {
 }
 
 // NewCommand returns a new cobra.Command for exporting the kubeconfig
-func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+func NewCommand(rt *runtime.Runtime, logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 	flags := &flagpole{}
 	cmd := &cobra.Command{
 		Args:  cobra.NoArgs,
@@ -39,7 +40,7 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 		Short: "exports cluster kubeconfig",
 		Long:  "exports cluster kubeconfig",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runE(logger, flags)
+			return runE(rt, logger, flags)
 		},
 	}
 	cmd.Flags().StringVar(
@@ -57,10 +58,8 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 	return cmd
 }
 
-func runE(logger log.Logger, flags *flagpole) error {
-	provider := cluster.NewProvider(
-		cluster.ProviderWithLogger(logger),
-	)
+func runE(rt *runtime.Runtime, logger log.Logger, flags *flagpole) error {
+	provider := cluster.NewProvider(rt.ProviderOptions(logger)...)
 	if err := provider.ExportKubeConfig(flags.Name, flags.Kubeconfig); err != nil {
 		return err
 	}
@@ -25,6 +25,7 @@ import (
 	"sigs.k8s.io/kind/pkg/cluster"
 	"sigs.k8s.io/kind/pkg/cmd"
 	"sigs.k8s.io/kind/pkg/fs"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
 	"sigs.k8s.io/kind/pkg/log"
 )
 
@@ -33,7 +34,7 @@ type flagpole struct {
 }
 
 // NewCommand returns a new cobra.Command for getting the cluster logs
-func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+func NewCommand(rt *runtime.Runtime, logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 	flags := &flagpole{}
 	cmd := &cobra.Command{
 		Args: cobra.MaximumNArgs(1),
@@ -42,17 +43,15 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 		Short: "exports logs to a tempdir or [output-dir] if specified",
 		Long:  "exports logs to a tempdir or [output-dir] if specified",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runE(logger, streams, flags, args)
+			return runE(rt, logger, streams, flags, args)
 		},
 	}
 	cmd.Flags().StringVar(&flags.Name, "name", cluster.DefaultName, "the cluster context name")
 	return cmd
 }
 
-func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole, args []string) error {
-	provider := cluster.NewProvider(
-		cluster.ProviderWithLogger(logger),
-	)
+func runE(rt *runtime.Runtime, logger log.Logger, streams cmd.IOStreams, flags *flagpole, args []string) error {
+	provider := cluster.NewProvider(rt.ProviderOptions(logger)...)
 
 	// Check if the cluster has any running nodes
 	nodes, err := provider.ListNodes(flags.Name)
@@ -24,11 +24,12 @@ import (
 
 	"sigs.k8s.io/kind/pkg/cluster"
 	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
 	"sigs.k8s.io/kind/pkg/log"
 )
 
 // NewCommand returns a new cobra.Command for getting the list of clusters
-func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+func NewCommand(rt *runtime.Runtime, logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 	cmd := &cobra.Command{
 		Args: cobra.NoArgs,
 		// TODO(bentheelder): more detailed usage
@@ -36,16 +37,14 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 		Short: "lists existing kind clusters by their name",
 		Long:  "lists existing kind clusters by their name",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runE(logger, streams)
+			return runE(rt, logger, streams)
 		},
 	}
 	return cmd
 }
 
-func runE(logger log.Logger, streams cmd.IOStreams) error {
-	provider := cluster.NewProvider(
-		cluster.ProviderWithLogger(logger),
-	)
+func runE(rt *runtime.Runtime, logger log.Logger, streams cmd.IOStreams) error {
+	provider := cluster.NewProvider(rt.ProviderOptions(logger)...)
 	clusters, err := provider.List()
 	if err != nil {
 		return err
@@ -24,11 +24,12 @@ import (
 	"sigs.k8s.io/kind/pkg/cmd/kind/get/clusters"
 	"sigs.k8s.io/kind/pkg/cmd/kind/get/kubeconfig"
 	"sigs.k8s.io/kind/pkg/cmd/kind/get/nodes"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
 	"sigs.k8s.io/kind/pkg/log"
 )
 
 // NewCommand returns a new cobra.Command for get
-func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+func NewCommand(rt *runtime.Runtime, logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 	cmd := &cobra.Command{
 		Args: cobra.NoArgs,
 		// TODO(bentheelder): more detailed usage
@@ -37,8 +38,9 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 		Long:  "Gets one of [clusters, nodes, kubeconfig]",
 	}
 	// add subcommands
-	cmd.AddCommand(clusters.NewCommand(logger, streams))
-	cmd.AddCommand(nodes.NewCommand(logger, streams))
-	cmd.AddCommand(kubeconfig.NewCommand(logger, streams))
+	rt.AddHostFlags(cmd)
+	cmd.AddCommand(clusters.NewCommand(rt, logger, streams))
+	cmd.AddCommand(nodes.NewCommand(rt, logger, streams))
+	cmd.AddCommand(kubeconfig.NewCommand(rt, logger, streams))
 	return cmd
 }
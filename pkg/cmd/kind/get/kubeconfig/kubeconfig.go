@@ -24,6 +24,7 @@ import (
 
 	"sigs.k8s.io/kind/pkg/cluster"
 	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
 	"sigs.k8s.io/kind/pkg/log"
 )
 
@@ -33,7 +34,7 @@ type flagpole struct {
 }
 
 // NewCommand returns a new cobra.Command for getting the kubeconfig
-func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+func NewCommand(rt *runtime.Runtime, logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 	flags := &flagpole{}
 	cmd := &cobra.Command{
 		Args:  cobra.NoArgs,
@@ -41,7 +42,7 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 		Short: "prints cluster kubeconfig",
 		Long:  "prints cluster kubeconfig",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runE(logger, streams, flags)
+			return runE(rt, logger, streams, flags)
 		},
 	}
 	cmd.Flags().StringVar(
@@ -59,10 +60,8 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 	return cmd
 }
 
-func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
-	provider := cluster.NewProvider(
-		cluster.ProviderWithLogger(logger),
-	)
+func runE(rt *runtime.Runtime, logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
+	provider := cluster.NewProvider(rt.ProviderOptions(logger)...)
 	cfg, err := provider.KubeConfig(flags.Name, flags.Internal)
 	if err != nil {
 		return err
@@ -19,11 +19,14 @@ package nodes
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"sigs.k8s.io/kind/pkg/cluster"
+	clusternodes "sigs.k8s.io/kind/pkg/cluster/nodes"
 	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
 	"sigs.k8s.io/kind/pkg/log"
 )
 
@@ -32,15 +35,15 @@ type flagpole struct {
 }
 
 // NewCommand returns a new cobra.Command for getting the list of nodes for a given cluster
-func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+func NewCommand(rt *runtime.Runtime, logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 	flags := &flagpole{}
 	cmd := &cobra.Command{
 		Args:  cobra.NoArgs,
 		Use:   "nodes",
 		Short: "lists existing kind nodes by their name",
-		Long:  "lists existing kind nodes by their name",
+		Long:  "lists existing kind nodes by their name, followed by their published ports if any",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runE(logger, streams, flags)
+			return runE(rt, logger, streams, flags)
 		},
 	}
 	cmd.Flags().StringVar(
@@ -52,16 +55,25 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 	return cmd
 }
 
-func runE(logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
+func runE(rt *runtime.Runtime, logger log.Logger, streams cmd.IOStreams, flags *flagpole) error {
 	// List nodes by cluster context name
-	provider := cluster.NewProvider(
-		cluster.ProviderWithLogger(logger),
-	)
+	provider := cluster.NewProvider(rt.ProviderOptions(logger)...)
 	n, err := provider.ListNodes(flags.Name)
 	if err != nil {
 		return err
 	}
 	for _, node := range n {
+		// show the published ports of nodes that have them
+		if lister, ok := node.(clusternodes.PortLister); ok {
+			ports, err := lister.Ports()
+			if err != nil {
+				return err
+			}
+			if len(ports) > 0 {
+				fmt.Fprintf(streams.Out, "%s\t%s\n", node.String(), strings.Join(ports, ","))
+				continue
+			}
+		}
 		fmt.Fprintln(streams.Out, node.String())
 	}
 	return nil
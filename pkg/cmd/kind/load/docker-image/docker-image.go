@@ -31,6 +31,7 @@ import (
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/exec"
 	"sigs.k8s.io/kind/pkg/fs"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
 	"sigs.k8s.io/kind/pkg/log"
 )
 
@@ -40,7 +41,7 @@ type flagpole struct {
 }
 
 // NewCommand returns a new cobra.Command for loading an image into a cluster
-func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+func NewCommand(rt *runtime.Runtime, logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 	flags := &flagpole{}
 	cmd := &cobra.Command{
 		Args: func(cmd *cobra.Command, args []string) error {
@@ -53,7 +54,7 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 		Short: "loads docker image from host into nodes",
 		Long:  "loads docker image from host into all or specified nodes by name",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runE(logger, flags, args)
+			return runE(rt, logger, flags, args)
 		},
 	}
 	cmd.Flags().StringVar(
@@ -71,10 +72,8 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 	return cmd
 }
 
-func runE(logger log.Logger, flags *flagpole, args []string) error {
-	provider := cluster.NewProvider(
-		cluster.ProviderWithLogger(logger),
-	)
+func runE(rt *runtime.Runtime, logger log.Logger, flags *flagpole, args []string) error {
+	provider := cluster.NewProvider(rt.ProviderOptions(logger)...)
 
 	// Check that the image exists locally and gets its ID, if not return error
 	imageName := args[0]
@@ -28,6 +28,7 @@ import (
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
 	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
 	"sigs.k8s.io/kind/pkg/log"
 )
 
@@ -37,7 +38,7 @@ type flagpole struct {
 }
 
 // NewCommand returns a new cobra.Command for loading an image into a cluster
-func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+func NewCommand(rt *runtime.Runtime, logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 	flags := &flagpole{}
 	cmd := &cobra.Command{
 		Args: func(cmd *cobra.Command, args []string) error {
@@ -50,7 +51,7 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 		Short: "loads docker image from archive into nodes",
 		Long:  "loads docker image from archive into all or specified nodes by name",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runE(logger, flags, args)
+			return runE(rt, logger, flags, args)
 		},
 	}
 	cmd.Flags().StringVar(
@@ -68,10 +69,8 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 	return cmd
 }
 
-func runE(logger log.Logger, flags *flagpole, args []string) error {
-	provider := cluster.NewProvider(
-		cluster.ProviderWithLogger(logger),
-	)
+func runE(rt *runtime.Runtime, logger log.Logger, flags *flagpole, args []string) error {
+	provider := cluster.NewProvider(rt.ProviderOptions(logger)...)
 
 	// Check if file exists
 	imageTarPath := args[0]
@@ -23,11 +23,12 @@ import (
 	"sigs.k8s.io/kind/pkg/cmd"
 	dockerimage "sigs.k8s.io/kind/pkg/cmd/kind/load/docker-image"
 	imagearchive "sigs.k8s.io/kind/pkg/cmd/kind/load/image-archive"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
 	"sigs.k8s.io/kind/pkg/log"
 )
 
 // NewCommand returns a new cobra.Command for get
-func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+func NewCommand(rt *runtime.Runtime, logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 	cmd := &cobra.Command{
 		Args:  cobra.NoArgs,
 		Use:   "load",
@@ -35,7 +36,8 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 		Long:  "Loads images into node from an archive or image on host",
 	}
 	// add subcommands
-	cmd.AddCommand(dockerimage.NewCommand(logger, streams))
-	cmd.AddCommand(imagearchive.NewCommand(logger, streams))
+	rt.AddHostFlags(cmd)
+	cmd.AddCommand(dockerimage.NewCommand(rt, logger, streams))
+	cmd.AddCommand(imagearchive.NewCommand(rt, logger, streams))
 	return cmd
 }
@@ -18,11 +18,15 @@ limitations under the License.
 package kind
 
 import (
+	"context"
+	"fmt"
 	"io"
 	"io/ioutil"
+	"strings"
 
 	"github.com/spf13/cobra"
 
+	"sigs.k8s.io/kind/pkg/cluster"
 	"sigs.k8s.io/kind/pkg/cmd"
 	"sigs.k8s.io/kind/pkg/cmd/kind/build"
 	"sigs.k8s.io/kind/pkg/cmd/kind/completion"
@@ -31,7 +35,12 @@ import (
 	"sigs.k8s.io/kind/pkg/cmd/kind/export"
 	"sigs.k8s.io/kind/pkg/cmd/kind/get"
 	"sigs.k8s.io/kind/pkg/cmd/kind/load"
+	"sigs.k8s.io/kind/pkg/cmd/kind/start"
+	"sigs.k8s.io/kind/pkg/cmd/kind/stop"
 	"sigs.k8s.io/kind/pkg/cmd/kind/version"
+	"sigs.k8s.io/kind/pkg/cmd/kind/wait"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
 	"sigs.k8s.io/kind/pkg/log"
 )
 
@@ -39,18 +48,20 @@ type flagpole struct {
 	LogLevel  string
 	Verbosity int32
 	Quiet     bool
+	Provider  string
 }
 
 // NewCommand returns a new cobra.Command implementing the root command for kind
-func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+func NewCommand(ctx context.Context, logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 	flags := &flagpole{}
+	rt := runtime.New(ctx)
 	cmd := &cobra.Command{
 		Args:  cobra.NoArgs,
 		Use:   "kind",
 		Short: "kind is a tool for managing local Kubernetes clusters",
 		Long:  "kind creates and manages local Kubernetes clusters using Docker container 'nodes'",
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-			return runE(logger, flags, cmd)
+			return runE(logger, flags, rt, cmd)
 		},
 		SilenceUsage:  true,
 		SilenceErrors: true,
@@ -77,19 +88,31 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 		false,
 		"silence all stderr output",
 	)
+	cmd.PersistentFlags().StringVar(
+		&flags.Provider,
+		"provider",
+		"",
+		fmt.Sprintf(
+			"cluster provider, one of %s (default %s for new clusters, otherwise the provider of the cluster)",
+			strings.Join(cluster.ProviderNames(), ", "), cluster.DefaultProviderName,
+		),
+	)
 	// add all top level subcommands
 	cmd.AddCommand(build.NewCommand(logger, streams))
 	cmd.AddCommand(completion.NewCommand(logger, streams))
-	cmd.AddCommand(create.NewCommand(logger, streams))
-	cmd.AddCommand(delete.NewCommand(logger, streams))
-	cmd.AddCommand(export.NewCommand(logger, streams))
-	cmd.AddCommand(get.NewCommand(logger, streams))
+	cmd.AddCommand(create.NewCommand(rt, logger, streams))
+	cmd.AddCommand(delete.NewCommand(rt, logger, streams))
+	cmd.AddCommand(export.NewCommand(rt, logger, streams))
+	cmd.AddCommand(get.NewCommand(rt, logger, streams))
 	cmd.AddCommand(version.NewCommand(logger, streams))
-	cmd.AddCommand(load.NewCommand(logger, streams))
+	cmd.AddCommand(load.NewCommand(rt, logger, streams))
+	cmd.AddCommand(start.NewCommand(rt, logger, streams))
+	cmd.AddCommand(stop.NewCommand(rt, logger, streams))
+	cmd.AddCommand(wait.NewCommand(rt, logger, streams))
 	return cmd
 }
 
-func runE(logger log.Logger, flags *flagpole, command *cobra.Command) error {
+func runE(logger log.Logger, flags *flagpole, rt *runtime.Runtime, command *cobra.Command) error {
 	// handle limited migration for --loglevel
 	setLogLevel := command.Flag("loglevel").Changed
 	setVerbosity := command.Flag("verbosity").Changed
@@ -108,6 +131,19 @@ func runE(logger log.Logger, flags *flagpole, command *cobra.Command) error {
 		maybeSetWriter(logger, ioutil.Discard)
 	}
 	maybeSetVerbosity(logger, log.Level(flags.Verbosity))
+	// explicitly set provider selection flags override the environment
+	selection := runtime.FromEnv()
+	changed := func(name string) bool {
+		return command.Flag(name) != nil && command.Flag(name).Changed
+	}
+	if changed("provider") {
+		selection.Provider = flags.Provider
+	}
+	rt.OverrideHost(command, &selection.Host)
+	if err := validateProvider(selection.Provider); err != nil {
+		return err
+	}
+	rt.Select(selection)
 	// warn about deprecated flag if used
 	if setLogLevel {
 		if cmd.ColorEnabled(logger) {
@@ -119,6 +155,23 @@ func runE(logger log.Logger, flags *flagpole, command *cobra.Command) error {
 	return nil
 }
 
+// validateProvider returns an error if name is set and not a registered
+// provider name
+func validateProvider(name string) error {
+	if name == "" {
+		return nil
+	}
+	for _, known := range cluster.ProviderNames() {
+		if name == known {
+			return nil
+		}
+	}
+	return errors.Errorf(
+		"unknown provider %q set by --provider or %s, expected one of %s",
+		name, runtime.ProviderEnv, strings.Join(cluster.ProviderNames(), ", "),
+	)
+}
+
 // maybeSetWriter will call logger.SetWriter(w) if logger has a SetWriter method
 func maybeSetWriter(logger log.Logger, w io.Writer) {
 	type writerSetter interface {
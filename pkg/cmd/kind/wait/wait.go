@@ -0,0 +1,64 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package wait implements the `wait` command
+package wait
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/cmd"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/runtime"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+type flagpole struct {
+	Name    string
+	Timeout time.Duration
+	Gates   []string
+}
+
+// NewCommand returns a new cobra.Command for waiting for a cluster to be ready
+func NewCommand(rt *runtime.Runtime, logger log.Logger, streams cmd.IOStreams) *cobra.Command {
+	flags := &flagpole{}
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "wait",
+		Short: "Waits for a cluster to be ready",
+		Long:  "Waits for an existing cluster to pass the readiness gates, failing with a summary of the gates that did not pass in time",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(rt, logger, flags)
+		},
+	}
+	cmd.Flags().StringVar(&flags.Name, "name", cluster.DefaultName, "the cluster name")
+	cmd.Flags().DurationVar(&flags.Timeout, "timeout", 5*time.Minute, "maximum time to wait for the cluster to be ready")
+	cmd.Flags().StringSliceVar(&flags.Gates, "readiness-gates", nil, fmt.Sprintf("readiness gates to wait for, any of %v (default all that apply to the cluster)", cluster.ReadinessGateNames()))
+	rt.AddHostFlags(cmd)
+	return cmd
+}
+
+func runE(rt *runtime.Runtime, logger log.Logger, flags *flagpole) error {
+	provider := cluster.NewProvider(rt.ProviderOptions(logger)...)
+	if err := provider.Wait(flags.Name, flags.Timeout, flags.Gates...); err != nil {
+		return errors.Wrap(err, "cluster is not ready")
+	}
+	return nil
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package errors
 
 import (
+	"context"
 	"sync"
 )
 
@@ -38,6 +39,30 @@ func UntilErrorConcurrent(funcs []func() error) error {
 	return nil
 }
 
+// UntilErrorConcurrentContext runs all funcs in separate goroutines with a
+// context derived from ctx, which is cancelled once any of them returns an
+// error or ctx is done. It returns once all funcs returned, with the first
+// non-nil error returned from funcs, or nil if all funcs return nil
+func UntilErrorConcurrentContext(ctx context.Context, funcs []func(context.Context) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errCh := make(chan error, len(funcs))
+	for _, f := range funcs {
+		f := f // capture f
+		go func() {
+			errCh <- f(ctx)
+		}()
+	}
+	var firstErr error
+	for i := 0; i < len(funcs); i++ {
+		if err := <-errCh; err != nil && firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+	return firstErr
+}
+
 // AggregateConcurrent runs fns concurrently, returning a NewAggregate if there are > 1 errors
 func AggregateConcurrent(funcs []func() error) error {
 	// run all fns concurrently
@@ -17,6 +17,7 @@ limitations under the License.
 package errors
 
 import (
+	"context"
 	"sort"
 	"testing"
 
@@ -54,6 +55,46 @@ func TestUntilErrorConcurrent(t *testing.T) {
 	})
 }
 
+func TestUntilErrorConcurrentContext(t *testing.T) {
+	t.Parallel()
+	t.Run("error cancels the others", func(t *testing.T) {
+		t.Parallel()
+		expected := New("first")
+		result := UntilErrorConcurrentContext(context.Background(), []func(context.Context) error{
+			func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			func(ctx context.Context) error {
+				return expected
+			},
+		})
+		assert.DeepEqual(t, expected, result)
+	})
+	t.Run("cancelled parent", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		result := UntilErrorConcurrentContext(ctx, []func(context.Context) error{
+			func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		})
+		assert.DeepEqual(t, context.Canceled, result)
+	})
+	t.Run("nil", func(t *testing.T) {
+		t.Parallel()
+		result := UntilErrorConcurrentContext(context.Background(), []func(context.Context) error{
+			func(ctx context.Context) error {
+				return nil
+			},
+		})
+		var expected error
+		assert.DeepEqual(t, expected, result)
+	})
+}
+
 func TestAggregateConcurrent(t *testing.T) {
 	t.Parallel()
 	t.Run("all errors returned", func(t *testing.T) {
@@ -16,6 +16,8 @@ limitations under the License.
 
 package exec
 
+import "context"
+
 // DefaultCmder is a LocalCmder instance used for convenience, packages
 // originally using os/exec.Command can instead use pkg/kind/exec.Command
 // which forwards to this instance
@@ -27,3 +29,8 @@ var DefaultCmder = &LocalCmder{}
 func Command(command string, args ...string) Cmd {
 	return DefaultCmder.Command(command, args...)
 }
+
+// CommandContext is a convenience wrapper over DefaultCmder.CommandContext
+func CommandContext(ctx context.Context, command string, args ...string) Cmd {
+	return DefaultCmder.CommandContext(ctx, command, args...)
+}
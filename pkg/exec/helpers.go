@@ -19,6 +19,7 @@ package exec
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"io"
 	"os"
 	"strings"
@@ -57,6 +58,52 @@ func RunErrorForError(err error) *RunError {
 	return runError
 }
 
+// CommandContextFor returns a command from cmder that is killed once ctx is
+// done if cmder implements ContextCmder, otherwise the command is only not
+// started once ctx is done
+func CommandContextFor(ctx context.Context, cmder Cmder, command string, args ...string) Cmd {
+	if c, ok := cmder.(ContextCmder); ok {
+		return c.CommandContext(ctx, command, args...)
+	}
+	return &contextCmd{cmd: cmder.Command(command, args...), ctx: ctx}
+}
+
+// contextCmd wraps the Cmd of a Cmder not implementing ContextCmder, to not
+// run it once ctx is done
+type contextCmd struct {
+	cmd Cmd
+	ctx context.Context
+}
+
+var _ Cmd = &contextCmd{}
+
+func (c *contextCmd) Run() error {
+	if err := c.ctx.Err(); err != nil {
+		return err
+	}
+	return c.cmd.Run()
+}
+
+func (c *contextCmd) SetEnv(env ...string) Cmd {
+	c.cmd.SetEnv(env...)
+	return c
+}
+
+func (c *contextCmd) SetStdin(r io.Reader) Cmd {
+	c.cmd.SetStdin(r)
+	return c
+}
+
+func (c *contextCmd) SetStdout(w io.Writer) Cmd {
+	c.cmd.SetStdout(w)
+	return c
+}
+
+func (c *contextCmd) SetStderr(w io.Writer) Cmd {
+	c.cmd.SetStderr(w)
+	return c
+}
+
 // CombinedOutputLines is like os/exec's cmd.CombinedOutput(),
 // but over our Cmd interface, and instead of returning the byte buffer of
 // stderr + stdout, it scans these for lines and returns a slice of output lines
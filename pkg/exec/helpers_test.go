@@ -0,0 +1,84 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exec
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+// countingCmder is a Cmder without CommandContext, counting the commands run
+type countingCmder struct {
+	ran int
+}
+
+func (c *countingCmder) Command(string, ...string) Cmd {
+	return &countingCmd{cmder: c}
+}
+
+type countingCmd struct {
+	cmder *countingCmder
+}
+
+func (c *countingCmd) Run() error {
+	c.cmder.ran++
+	return nil
+}
+
+func (c *countingCmd) SetEnv(...string) Cmd    { return c }
+func (c *countingCmd) SetStdin(io.Reader) Cmd  { return c }
+func (c *countingCmd) SetStdout(io.Writer) Cmd { return c }
+func (c *countingCmd) SetStderr(io.Writer) Cmd { return c }
+
+func TestCommandContextFor(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name        string
+		Cancel      bool
+		ExpectRan   int
+		ExpectError bool
+	}{
+		{
+			Name:      "running",
+			ExpectRan: 1,
+		},
+		{
+			Name:        "cancelled",
+			Cancel:      true,
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture range variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tc.Cancel {
+				cancel()
+			}
+			cmder := &countingCmder{}
+			err := CommandContextFor(ctx, cmder, "true").SetStdout(ioutil.Discard).Run()
+			assert.ExpectError(t, tc.ExpectError, err)
+			assert.DeepEqual(t, tc.ExpectRan, cmder.ran)
+		})
+	}
+}
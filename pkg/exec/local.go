@@ -18,6 +18,7 @@ package exec
 
 import (
 	"bytes"
+	"context"
 	"io"
 	osexec "os/exec"
 	"sync"
@@ -32,10 +33,10 @@ type LocalCmd struct {
 
 var _ Cmd = &LocalCmd{}
 
-// LocalCmder is a factory for LocalCmd, implementing Cmder
+// LocalCmder is a factory for LocalCmd, implementing ContextCmder
 type LocalCmder struct{}
 
-var _ Cmder = &LocalCmder{}
+var _ ContextCmder = &LocalCmder{}
 
 // Command returns a new exec.Cmd backed by Cmd
 func (c *LocalCmder) Command(name string, arg ...string) Cmd {
@@ -44,6 +45,14 @@ func (c *LocalCmder) Command(name string, arg ...string) Cmd {
 	}
 }
 
+// CommandContext returns a new exec.Cmd backed by Cmd, killed once ctx is
+// done
+func (c *LocalCmder) CommandContext(ctx context.Context, name string, arg ...string) Cmd {
+	return &LocalCmd{
+		Cmd: osexec.CommandContext(ctx, name, arg...),
+	}
+}
+
 // SetEnv sets env
 func (cmd *LocalCmd) SetEnv(env ...string) Cmd {
 	cmd.Env = env
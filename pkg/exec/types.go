@@ -17,6 +17,7 @@ limitations under the License.
 package exec
 
 import (
+	"context"
 	"fmt"
 	"io"
 )
@@ -39,6 +40,15 @@ type Cmder interface {
 	Command(string, ...string) Cmd
 }
 
+// ContextCmder is optionally implemented by Cmders that can stop commands,
+// see CommandContextFor
+type ContextCmder interface {
+	Cmder
+	// CommandContext is like Command, but the command is killed once the
+	// context is done, just like os/exec.CommandContext
+	CommandContext(context.Context, string, ...string) Cmd
+}
+
 // RunError represents an error running a Cmd
 type RunError struct {
 	Command []string // [Name Args...]
@@ -29,6 +29,9 @@ func Convertv1alpha4(in *v1alpha4.Cluster) *Cluster {
 		KubeadmConfigPatchesJSON6902:    make([]PatchJSON6902, len(in.KubeadmConfigPatchesJSON6902)),
 		ContainerdConfigPatches:         in.ContainerdConfigPatches,
 		ContainerdConfigPatchesJSON6902: in.ContainerdConfigPatchesJSON6902,
+		ProviderPatches:                 in.ProviderPatches,
+		Hooks:                           make([]Hook, len(in.Hooks)),
+		Addons:                          make([]Addon, len(in.Addons)),
 	}
 
 	for i := range in.Nodes {
@@ -36,11 +39,20 @@ func Convertv1alpha4(in *v1alpha4.Cluster) *Cluster {
 	}
 
 	convertv1alpha4Networking(&in.Networking, &out.Networking)
+	convertv1alpha4Storage(&in.Storage, &out.Storage)
 
 	for i := range in.KubeadmConfigPatchesJSON6902 {
 		convertv1alpha4PatchJSON6902(&in.KubeadmConfigPatchesJSON6902[i], &out.KubeadmConfigPatchesJSON6902[i])
 	}
 
+	for i := range in.Hooks {
+		convertv1alpha4Hook(&in.Hooks[i], &out.Hooks[i])
+	}
+
+	for i := range in.Addons {
+		convertv1alpha4Addon(&in.Addons[i], &out.Addons[i])
+	}
+
 	return out
 }
 
@@ -49,6 +61,7 @@ func convertv1alpha4Node(in *v1alpha4.Node, out *Node) {
 	out.Image = in.Image
 
 	out.KubeadmConfigPatches = in.KubeadmConfigPatches
+	out.ProviderPatches = in.ProviderPatches
 	out.ExtraMounts = make([]Mount, len(in.ExtraMounts))
 	out.ExtraPortMappings = make([]PortMapping, len(in.ExtraPortMappings))
 	out.KubeadmConfigPatchesJSON6902 = make([]PatchJSON6902, len(in.KubeadmConfigPatchesJSON6902))
@@ -64,6 +77,15 @@ func convertv1alpha4Node(in *v1alpha4.Node, out *Node) {
 	for i := range in.KubeadmConfigPatchesJSON6902 {
 		convertv1alpha4PatchJSON6902(&in.KubeadmConfigPatchesJSON6902[i], &out.KubeadmConfigPatchesJSON6902[i])
 	}
+
+	convertv1alpha4Resources(&in.Resources, &out.Resources)
+}
+
+func convertv1alpha4Resources(in *v1alpha4.Resources, out *Resources) {
+	out.Requests.CPU = in.Requests.CPU
+	out.Requests.Memory = in.Requests.Memory
+	out.Limits.CPU = in.Limits.CPU
+	out.Limits.Memory = in.Limits.Memory
 }
 
 func convertv1alpha4PatchJSON6902(in *v1alpha4.PatchJSON6902, out *PatchJSON6902) {
@@ -82,6 +104,12 @@ func convertv1alpha4Networking(in *v1alpha4.Networking, out *Networking) {
 	out.DisableDefaultCNI = in.DisableDefaultCNI
 }
 
+func convertv1alpha4Storage(in *v1alpha4.Storage, out *Storage) {
+	out.DisableDefaultStorageClass = in.DisableDefaultStorageClass
+	out.VolumeBindingMode = VolumeBindingMode(in.VolumeBindingMode)
+	out.ReclaimPolicy = ReclaimPolicy(in.ReclaimPolicy)
+}
+
 func convertv1alpha4Mount(in *v1alpha4.Mount, out *Mount) {
 	out.ContainerPath = in.ContainerPath
 	out.HostPath = in.HostPath
@@ -96,3 +124,26 @@ func convertv1alpha4PortMapping(in *v1alpha4.PortMapping, out *PortMapping) {
 	out.ListenAddress = in.ListenAddress
 	out.Protocol = PortMappingProtocol(in.Protocol)
 }
+
+func convertv1alpha4Hook(in *v1alpha4.Hook, out *Hook) {
+	out.Name = in.Name
+	out.When = HookPoint(in.When)
+	out.Roles = make([]NodeRole, len(in.Roles))
+	for i := range in.Roles {
+		out.Roles[i] = NodeRole(in.Roles[i])
+	}
+	out.Nodes = in.Nodes
+	out.OnHost = in.OnHost
+	out.Command = in.Command
+}
+
+func convertv1alpha4Addon(in *v1alpha4.Addon, out *Addon) {
+	out.Name = in.Name
+	out.Manifests = in.Manifests
+	out.WaitFor = make([]AddonWorkload, len(in.WaitFor))
+	for i := range in.WaitFor {
+		out.WaitFor[i].Kind = in.WaitFor[i].Kind
+		out.WaitFor[i].Namespace = in.WaitFor[i].Namespace
+		out.WaitFor[i].Name = in.WaitFor[i].Name
+	}
+}
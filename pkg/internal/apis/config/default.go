@@ -67,6 +67,22 @@ func SetDefaultsCluster(obj *Cluster) {
 			obj.Networking.ServiceSubnet = "fd00:10:96::/112"
 		}
 	}
+	// default the StorageClass to provision volumes on the node of their
+	// first consumer, and to clean them up once released
+	if obj.Storage.VolumeBindingMode == "" {
+		obj.Storage.VolumeBindingMode = VolumeBindingWaitForFirstConsumer
+	}
+	if obj.Storage.ReclaimPolicy == "" {
+		obj.Storage.ReclaimPolicy = ReclaimDelete
+	}
+	// default addon workloads to the default namespace
+	for i := range obj.Addons {
+		for j := range obj.Addons[i].WaitFor {
+			if obj.Addons[i].WaitFor[j].Namespace == "" {
+				obj.Addons[i].WaitFor[j].Namespace = "default"
+			}
+		}
+	}
 }
 
 // SetDefaultsNode sets uninitialized fields to their default value.
@@ -78,4 +94,12 @@ func SetDefaultsNode(obj *Node) {
 	if obj.Role == "" {
 		obj.Role = ControlPlaneRole
 	}
+
+	// default requests to limits, like Kubernetes
+	if obj.Resources.Requests.CPU == "" {
+		obj.Resources.Requests.CPU = obj.Resources.Limits.CPU
+	}
+	if obj.Resources.Requests.Memory == "" {
+		obj.Resources.Requests.Memory = obj.Resources.Limits.Memory
+	}
 }
@@ -19,6 +19,8 @@ package config
 import (
 	"net"
 
+	"k8s.io/apimachinery/pkg/api/resource"
+
 	"sigs.k8s.io/kind/pkg/errors"
 )
 
@@ -45,6 +47,20 @@ func (c *Cluster) Validate() error {
 		errs = append(errs, errors.Wrapf(err, "invalid serviceSubnet"))
 	}
 
+	// the default StorageClass settings should be one of the expected values
+	switch c.Storage.VolumeBindingMode {
+	case VolumeBindingWaitForFirstConsumer,
+		VolumeBindingImmediate:
+	default:
+		errs = append(errs, errors.Errorf("%q is not a valid volumeBindingMode", c.Storage.VolumeBindingMode))
+	}
+	switch c.Storage.ReclaimPolicy {
+	case ReclaimDelete,
+		ReclaimRetain:
+	default:
+		errs = append(errs, errors.Errorf("%q is not a valid reclaimPolicy", c.Storage.ReclaimPolicy))
+	}
+
 	// validate nodes
 	numByRole := make(map[NodeRole]int32)
 	// All nodes in the config should be valid
@@ -67,6 +83,25 @@ func (c *Cluster) Validate() error {
 		errs = append(errs, errors.Errorf("must have at least one %s node", string(ControlPlaneRole)))
 	}
 
+	// All hooks in the config should be valid
+	for i, h := range c.Hooks {
+		if err := h.Validate(); err != nil {
+			errs = append(errs, errors.Errorf("invalid configuration for hook %d: %v", i, err))
+		}
+	}
+
+	// All addons in the config should be valid, with unique names
+	addonNames := map[string]bool{}
+	for i, a := range c.Addons {
+		if err := a.Validate(); err != nil {
+			errs = append(errs, errors.Errorf("invalid configuration for addon %d: %v", i, err))
+		}
+		if addonNames[a.Name] {
+			errs = append(errs, errors.Errorf("duplicate addon name %q", a.Name))
+		}
+		addonNames[a.Name] = true
+	}
+
 	if len(errs) > 0 {
 		return errors.NewAggregate(errs)
 	}
@@ -101,10 +136,130 @@ func (n *Node) Validate() error {
 		}
 	}
 
+	// validate resources
+	if err := n.Resources.Validate(); err != nil {
+		errs = append(errs, errors.Wrapf(err, "invalid resources"))
+	}
+
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+
+	return nil
+}
+
+// Validate returns a ConfigErrors with an entry for each problem
+// with the Addon, or nil if there are none
+func (a *Addon) Validate() error {
+	errs := []error{}
+
+	// name and manifests should be defined
+	if a.Name == "" {
+		errs = append(errs, errors.New("name is a required field"))
+	}
+	if len(a.Manifests) == 0 {
+		errs = append(errs, errors.New("manifests is a required field"))
+	}
+
+	// validate the awaited workloads
+	for _, w := range a.WaitFor {
+		switch w.Kind {
+		case "Deployment",
+			"DaemonSet":
+		default:
+			errs = append(errs, errors.Errorf("%q is not a valid workload kind, expected Deployment or DaemonSet", w.Kind))
+		}
+		if w.Name == "" {
+			errs = append(errs, errors.New("waitFor name is a required field"))
+		}
+	}
+
 	if len(errs) > 0 {
 		return errors.NewAggregate(errs)
 	}
+	return nil
+}
+
+// Validate returns a ConfigErrors with an entry for each problem
+// with the Hook, or nil if there are none
+func (h *Hook) Validate() error {
+	errs := []error{}
+
+	// validate the hook point should be one of the expected values
+	switch h.When {
+	case AfterProvision,
+		AfterKubeadmInit,
+		AfterJoin,
+		AfterReady:
+	default:
+		errs = append(errs, errors.Errorf("%q is not a valid hook point", h.When))
+	}
+
+	// command should be defined
+	if len(h.Command) == 0 {
+		errs = append(errs, errors.New("command is a required field"))
+	}
+
+	// validate the selected roles
+	for _, role := range h.Roles {
+		switch role {
+		case ControlPlaneRole,
+			WorkerRole:
+		default:
+			errs = append(errs, errors.Errorf("%q is not a valid node role", role))
+		}
+	}
+
+	// host hooks do not run on nodes
+	if h.OnHost && (len(h.Roles) > 0 || len(h.Nodes) > 0) {
+		errs = append(errs, errors.New("onHost hooks cannot select roles or nodes"))
+	}
+
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+	return nil
+}
 
+// Validate returns a ConfigErrors with an entry for each problem
+// with the Resources, or nil if there are none
+func (r *Resources) Validate() error {
+	errs := []error{}
+	quantities := []struct {
+		name  string
+		value string
+	}{
+		{"requests.cpu", r.Requests.CPU},
+		{"requests.memory", r.Requests.Memory},
+		{"limits.cpu", r.Limits.CPU},
+		{"limits.memory", r.Limits.Memory},
+	}
+	parsed := map[string]resource.Quantity{}
+	for _, q := range quantities {
+		if q.value == "" {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(q.value)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "invalid %s %q", q.name, q.value))
+			continue
+		}
+		parsed[q.name] = quantity
+	}
+	// the host cluster rejects node pods requesting more than their limits
+	for _, name := range []string{"cpu", "memory"} {
+		request, hasRequest := parsed["requests."+name]
+		limit, hasLimit := parsed["limits."+name]
+		if hasRequest && hasLimit && request.Cmp(limit) > 0 {
+			errs = append(errs, errors.Errorf(
+				"requests.%s %q must be less than or equal to limits.%s %q",
+				name, request.String(), name, limit.String(),
+			))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
 	return nil
 }
 
@@ -85,6 +85,27 @@ func TestClusterValidate(t *testing.T) {
 			}(),
 			ExpectErrors: 1,
 		},
+		{
+			Name: "retained immediate storage",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				c.Storage.VolumeBindingMode = VolumeBindingImmediate
+				c.Storage.ReclaimPolicy = ReclaimRetain
+				SetDefaultsCluster(&c)
+				return c
+			}(),
+		},
+		{
+			Name: "bogus storage",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				c.Storage.VolumeBindingMode = "Later"
+				c.Storage.ReclaimPolicy = "Recycle"
+				SetDefaultsCluster(&c)
+				return c
+			}(),
+			ExpectErrors: 2,
+		},
 		{
 			Name: "missing control-plane",
 			Cluster: func() Cluster {
@@ -107,6 +128,27 @@ func TestClusterValidate(t *testing.T) {
 			}(),
 			ExpectErrors: 1,
 		},
+		{
+			Name: "bogus hook",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.Hooks = []Hook{{When: AfterReady}}
+				return c
+			}(),
+			ExpectErrors: 1,
+		},
+		{
+			Name: "duplicate addons",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				addon := Addon{Name: "rbac", Manifests: []string{"rbac.yaml"}}
+				c.Addons = []Addon{addon, addon}
+				return c
+			}(),
+			ExpectErrors: 1,
+		},
 	}
 
 	for _, tc := range cases {
@@ -216,6 +258,38 @@ func TestNodeValidate(t *testing.T) {
 			}(),
 			ExpectErrors: 1,
 		},
+		{
+			TestName: "Valid resources",
+			Node: func() Node {
+				cfg := newDefaultedNode(ControlPlaneRole)
+				cfg.Resources.Requests.CPU = "500m"
+				cfg.Resources.Limits.Memory = "4Gi"
+				return cfg
+			}(),
+			ExpectErrors: 0,
+		},
+		{
+			TestName: "Invalid resources",
+			Node: func() Node {
+				cfg := newDefaultedNode(ControlPlaneRole)
+				cfg.Resources.Limits.CPU = "two"
+				cfg.Resources.Limits.Memory = "4GiB"
+				return cfg
+			}(),
+			ExpectErrors: 2,
+		},
+		{
+			TestName: "Requests above limits",
+			Node: func() Node {
+				cfg := newDefaultedNode(ControlPlaneRole)
+				cfg.Resources.Requests.CPU = "2"
+				cfg.Resources.Limits.CPU = "1500m"
+				cfg.Resources.Requests.Memory = "4Gi"
+				cfg.Resources.Limits.Memory = "2Gi"
+				return cfg
+			}(),
+			ExpectErrors: 2,
+		},
 	}
 
 	for _, tc := range cases {
@@ -243,3 +317,138 @@ func TestNodeValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestAddonValidate(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		TestName     string
+		Addon        Addon
+		ExpectErrors int
+	}{
+		{
+			TestName: "Valid addon",
+			Addon: Addon{
+				Name:      "operator",
+				Manifests: []string{"crds", "operator.yaml"},
+				WaitFor: []AddonWorkload{
+					{Kind: "Deployment", Namespace: "operators", Name: "operator"},
+					{Kind: "DaemonSet", Namespace: "operators", Name: "agent"},
+				},
+			},
+			ExpectErrors: 0,
+		},
+		{
+			TestName:     "Missing name and manifests",
+			Addon:        Addon{},
+			ExpectErrors: 2,
+		},
+		{
+			TestName: "Invalid workload",
+			Addon: Addon{
+				Name:      "operator",
+				Manifests: []string{"operator.yaml"},
+				WaitFor: []AddonWorkload{
+					{Kind: "StatefulSet"},
+				},
+			},
+			ExpectErrors: 2,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc //capture loop variable
+		t.Run(tc.TestName, func(t *testing.T) {
+			t.Parallel()
+			err := tc.Addon.Validate()
+			if err == nil {
+				if tc.ExpectErrors != 0 {
+					t.Error("received no errors but expected errors for case")
+				}
+				return
+			}
+			errs := errors.Errors(err)
+			if errs == nil {
+				errs = []error{err}
+			}
+			if len(errs) != tc.ExpectErrors {
+				t.Errorf("expected %d errors but got len(%v) = %d", tc.ExpectErrors, errs, len(errs))
+			}
+		})
+	}
+}
+
+func TestHookValidate(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		TestName     string
+		Hook         Hook
+		ExpectErrors int
+	}{
+		{
+			TestName: "Node hook",
+			Hook: Hook{
+				When:    AfterJoin,
+				Roles:   []NodeRole{WorkerRole},
+				Nodes:   []string{"kind-control-plane"},
+				Command: []string{"true"},
+			},
+			ExpectErrors: 0,
+		},
+		{
+			TestName: "Host hook",
+			Hook: Hook{
+				When:    AfterReady,
+				OnHost:  true,
+				Command: []string{"true"},
+			},
+			ExpectErrors: 0,
+		},
+		{
+			TestName: "Invalid hook point and missing command",
+			Hook: Hook{
+				When: "Later",
+			},
+			ExpectErrors: 2,
+		},
+		{
+			TestName: "Invalid role",
+			Hook: Hook{
+				When:    AfterProvision,
+				Roles:   []NodeRole{"bogus"},
+				Command: []string{"true"},
+			},
+			ExpectErrors: 1,
+		},
+		{
+			TestName: "Host hook selecting nodes",
+			Hook: Hook{
+				When:    AfterKubeadmInit,
+				OnHost:  true,
+				Nodes:   []string{"kind-worker"},
+				Command: []string{"true"},
+			},
+			ExpectErrors: 1,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc //capture loop variable
+		t.Run(tc.TestName, func(t *testing.T) {
+			t.Parallel()
+			err := tc.Hook.Validate()
+			if err == nil {
+				if tc.ExpectErrors != 0 {
+					t.Error("received no errors but expected errors for case")
+				}
+				return
+			}
+			errs := errors.Errors(err)
+			if errs == nil {
+				errs = []error{err}
+			}
+			if len(errs) != tc.ExpectErrors {
+				t.Errorf("expected %d errors but got len(%v) = %d", tc.ExpectErrors, errs, len(errs))
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package runtime selects the cluster provider options used by the kind CLI
+// based on its flags and the environment
+package runtime
+
+import (
+	"context"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kind/pkg/cluster"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+// ProviderEnv selects the provider by name, see cluster.ProviderNames
+// The kind CLI's --provider flag takes precedence over this
+const ProviderEnv = "KIND_PROVIDER"
+
+// Environment variables selecting the kubernetes provider's host cluster,
+// the matching --host-* flags of the kind CLI take precedence over these
+const (
+	// HostKubeconfigEnv is the path to the host cluster kubeconfig
+	HostKubeconfigEnv = "KIND_HOST_KUBECONFIG"
+	// HostContextEnv is the host kubeconfig context
+	HostContextEnv = "KIND_HOST_CONTEXT"
+	// HostNamespaceEnv is the host namespace to create nodes in
+	HostNamespaceEnv = "KIND_HOST_NAMESPACE"
+	// HostNamespacePerClusterEnv enables a host namespace per cluster if "true"
+	HostNamespacePerClusterEnv = "KIND_HOST_NAMESPACE_PER_CLUSTER"
+	// HostServiceTypeEnv is the type of the node Services
+	HostServiceTypeEnv = "KIND_HOST_SERVICE_TYPE"
+	// HostPersistentStorageEnv enables persistent node storage if "true"
+	HostPersistentStorageEnv = "KIND_HOST_PERSISTENT_STORAGE"
+	// HostStorageClassEnv is the StorageClass of persistent node storage
+	HostStorageClassEnv = "KIND_HOST_STORAGE_CLASS"
+	// HostStorageSizeEnv is the size of persistent node storage
+	HostStorageSizeEnv = "KIND_HOST_STORAGE_SIZE"
+)
+
+// Selection is a cluster provider selection
+type Selection struct {
+	// Provider is the provider name, empty for the default
+	Provider string
+	// Host selects the kubernetes provider's host cluster
+	Host cluster.KubernetesHostOptions
+}
+
+// FromEnv returns the selection made by the environment
+func FromEnv() Selection {
+	return Selection{
+		Provider: os.Getenv(ProviderEnv),
+		Host: cluster.KubernetesHostOptions{
+			Kubeconfig:          os.Getenv(HostKubeconfigEnv),
+			Context:             os.Getenv(HostContextEnv),
+			Namespace:           os.Getenv(HostNamespaceEnv),
+			NamespacePerCluster: os.Getenv(HostNamespacePerClusterEnv) == "true",
+			ServiceType:         os.Getenv(HostServiceTypeEnv),
+			PersistentStorage:   os.Getenv(HostPersistentStorageEnv) == "true",
+			StorageClass:        os.Getenv(HostStorageClassEnv),
+			StorageSize:         os.Getenv(HostStorageSizeEnv),
+		},
+	}
+}
+
+// Runtime is shared by the kind CLI commands operating on clusters
+// The root command sets its selection once its flags are parsed
+type Runtime struct {
+	ctx       context.Context
+	selection Selection
+	// host is set by the --host-* flags of the executed command
+	host cluster.KubernetesHostOptions
+}
+
+// New returns a Runtime stopping once ctx is done, selecting the provider
+// from the environment until Select is called
+func New(ctx context.Context) *Runtime {
+	return &Runtime{
+		ctx:       ctx,
+		selection: FromEnv(),
+	}
+}
+
+// Select sets the provider selection
+func (r *Runtime) Select(selection Selection) {
+	r.selection = selection
+}
+
+// ProviderOptions returns the provider options for the selection, including
+// logging to logger and stopping once the runtime's context is done
+func (r *Runtime) ProviderOptions(logger log.Logger) []cluster.ProviderOption {
+	options := []cluster.ProviderOption{
+		cluster.ProviderWithLogger(logger),
+		cluster.ProviderWithContext(r.ctx),
+	}
+	if name := r.selection.Provider; name != "" {
+		logger.V(1).Infof("Using provider %q", name)
+		options = append(options, cluster.ProviderWithName(name))
+	}
+	logger.V(1).Infof("Using host cluster options: %+v", r.selection.Host)
+	return append(options, cluster.ProviderWithKubernetesHost(r.selection.Host))
+}
+
+// AddHostFlags adds the --host-* flags finding the kubernetes provider's
+// host cluster to cmd and its subcommands
+func (r *Runtime) AddHostFlags(cmd *cobra.Command) {
+	flags := cmd.PersistentFlags()
+	flags.StringVar(
+		&r.host.Kubeconfig,
+		"host-kubeconfig",
+		"",
+		"kubeconfig for the cluster hosting node pods, instead of $KUBECONFIG or $HOME/.kube/config",
+	)
+	flags.StringVar(
+		&r.host.Context,
+		"host-context",
+		"",
+		"kubeconfig context for the cluster hosting node pods (default current context)",
+	)
+	flags.StringVar(
+		&r.host.Namespace,
+		"host-namespace",
+		"",
+		"namespace to create node pods in, existing clusters are found in any namespace (default namespace of the host context)",
+	)
+	flags.BoolVar(
+		&r.host.NamespacePerCluster,
+		"host-namespace-per-cluster",
+		false,
+		"create and delete a kind-<cluster> namespace for each cluster's node pods",
+	)
+}
+
+// AddHostCreateFlags adds the --host-* flags only used to create clusters
+// to cmd, existing clusters keep the options they were created with
+func (r *Runtime) AddHostCreateFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.StringVar(
+		&r.host.ServiceType,
+		"host-service-type",
+		"",
+		"type of the Services exposing the API server and port mappings of node pods, NodePort or LoadBalancer (default NodePort)",
+	)
+	flags.BoolVar(
+		&r.host.PersistentStorage,
+		"host-persistent-storage",
+		false,
+		"run nodes as StatefulSets with /var on a PersistentVolumeClaim so they survive pod restarts on the same IP",
+	)
+	flags.StringVar(
+		&r.host.StorageClass,
+		"host-storage-class",
+		"",
+		"StorageClass of persistent node storage (default the host cluster default StorageClass)",
+	)
+	flags.StringVar(
+		&r.host.StorageSize,
+		"host-storage-size",
+		"",
+		"size of persistent node storage (default 20Gi)",
+	)
+}
+
+// OverrideHost sets the fields of host for the --host-* flags explicitly set
+// on command
+func (r *Runtime) OverrideHost(command *cobra.Command, host *cluster.KubernetesHostOptions) {
+	changed := func(name string) bool {
+		return command.Flag(name) != nil && command.Flag(name).Changed
+	}
+	if changed("host-kubeconfig") {
+		host.Kubeconfig = r.host.Kubeconfig
+	}
+	if changed("host-context") {
+		host.Context = r.host.Context
+	}
+	if changed("host-namespace") {
+		host.Namespace = r.host.Namespace
+	}
+	if changed("host-namespace-per-cluster") {
+		host.NamespacePerCluster = r.host.NamespacePerCluster
+	}
+	if changed("host-service-type") {
+		host.ServiceType = r.host.ServiceType
+	}
+	if changed("host-persistent-storage") {
+		host.PersistentStorage = r.host.PersistentStorage
+	}
+	if changed("host-storage-class") {
+		host.StorageClass = r.host.StorageClass
+	}
+	if changed("host-storage-size") {
+		host.StorageSize = r.host.StorageSize
+	}
+}